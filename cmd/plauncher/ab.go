@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/benchmark"
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const AB_DEFAULT_DURATION = 60 * time.Second
+const AB_DEFAULT_WARMUP = 10 * time.Second
+const AB_OVERLAY_FILENAME = "overlay.yaml"
+
+type abRun struct {
+	Name     string            `json:"name"`
+	Settings []string          `json:"settings"`
+	Result   *benchmark.Result `json:"result,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	folder   string
+}
+
+// Launches the game twice, with the base settings then the variant ones, benchmarking both with MangoHud
+func abCommand(args []string) {
+	base, variant, duration, warmup, launchArgs, err := parseAbArgs(args)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		fmt.Fprintf(os.Stderr, "Usage: %s ab [--base[=key=value]]... --variant key=value... [--duration=60s] [--warmup=10s] [launch flags] <game command>\n", config.APP_NAME)
+		os.Exit(1)
+	}
+
+	self, err := os.Executable()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find the plauncher executable: %s\n", err)
+		os.Exit(1)
+	}
+
+	runs := []*abRun{{Name: "base", Settings: base}, {Name: "variant", Settings: variant}}
+
+	// Settings are checked before the first launch, a typo must not cost a whole run
+	for _, run := range runs {
+		if run.folder, err = prepareBenchmarkOverlay(run.Settings); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid %s settings: %s\n", run.Name, err)
+			os.Exit(1)
+		}
+	}
+
+	for _, run := range runs {
+		result, err := benchmarkRun(self, run, duration, warmup, launchArgs)
+
+		if err != nil {
+			run.Error = err.Error()
+			fmt.Fprintf(os.Stderr, "The %s run gave no benchmark: %s\n", run.Name, err)
+			continue
+		}
+
+		run.Result = &result
+	}
+
+	if jsonOutput {
+		printJSON(map[string]any{"runs": runs})
+		return
+	}
+
+	printAbComparison(runs[0], runs[1])
+}
+
+func parseAbArgs(args []string) ([]string, []string, time.Duration, time.Duration, []string, error) {
+	base := make([]string, 0)
+	variant := make([]string, 0)
+	duration, warmup := AB_DEFAULT_DURATION, AB_DEFAULT_WARMUP
+	var err error
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--base":
+		case strings.HasPrefix(arg, "--base="):
+			base = append(base, strings.TrimPrefix(arg, "--base="))
+		case arg == "--variant" && i+1 < len(args):
+			i++
+			variant = append(variant, args[i])
+		case strings.HasPrefix(arg, "--variant="):
+			variant = append(variant, strings.TrimPrefix(arg, "--variant="))
+		case strings.HasPrefix(arg, "--duration="):
+			if duration, err = time.ParseDuration(strings.TrimPrefix(arg, "--duration=")); err != nil {
+				return nil, nil, 0, 0, nil, fmt.Errorf("Invalid --duration: %s", err)
+			}
+		case strings.HasPrefix(arg, "--warmup="):
+			if warmup, err = time.ParseDuration(strings.TrimPrefix(arg, "--warmup=")); err != nil {
+				return nil, nil, 0, 0, nil, fmt.Errorf("Invalid --warmup: %s", err)
+			}
+		default:
+			if len(variant) == 0 {
+				return nil, nil, 0, 0, nil, fmt.Errorf("No --variant setting to compare")
+			}
+
+			return base, variant, duration, warmup, args[i:], nil
+		}
+	}
+
+	return nil, nil, 0, 0, nil, fmt.Errorf("No game command")
+}
+
+// Writes the overlay of a run in a new folder, MangoHud enabled along with settings, where MangoHud logs its frames too
+func prepareBenchmarkOverlay(settings []string) (string, error) {
+	folder, err := os.MkdirTemp("", "plauncher-ab-")
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, setting := range append([]string{"mangohud.enabled=true"}, settings...) {
+		key, value, found := strings.Cut(setting, "=")
+
+		if !found || key == "" {
+			return "", fmt.Errorf("Expected key=value, got: %s", setting)
+		}
+
+		if err := config.SetOverrideValue(filepath.Join(folder, AB_OVERLAY_FILENAME), key, value); err != nil {
+			return "", err
+		}
+	}
+
+	return folder, nil
+}
+
+// Runs plauncher with the overlay of run merged on top of the game overrides, MangoHud logging frames
+func benchmarkRun(self string, run *abRun, duration time.Duration, warmup time.Duration, launchArgs []string) (benchmark.Result, error) {
+	overlayFile := filepath.Join(run.folder, AB_OVERLAY_FILENAME)
+
+	if !jsonOutput {
+		fmt.Printf("Launching the %s run (%s): frames are logged for %s after %s, quit the game afterwards\n", run.Name, describeSettings(run.Settings), duration, warmup)
+	}
+
+	cmdHandle := exec.Command(self, append([]string{"run", "--" + config.OVERLAY_PROP + "=" + overlayFile}, launchArgs...)...)
+	cmdHandle.Env = append(os.Environ(), "MANGOHUD_CONFIG="+benchmark.MangohudConfig(run.folder, warmup, duration))
+	cmdHandle.Stdin = os.Stdin
+	cmdHandle.Stderr = os.Stderr
+
+	if !jsonOutput {
+		cmdHandle.Stdout = os.Stdout
+	}
+
+	// A game quitting with an error status may still have logged its frames
+	if err := system.Exec.Run(cmdHandle); err != nil {
+		fmt.Fprintf(os.Stderr, "The %s run ended with: %s\n", run.Name, err)
+	}
+
+	logFile, err := benchmark.LatestLog(run.folder)
+
+	if err != nil {
+		return benchmark.Result{}, fmt.Errorf("%s, is MangoHud installed?", err)
+	}
+
+	return benchmark.ParseMangohudLog(logFile)
+}
+
+func describeSettings(settings []string) string {
+	if len(settings) == 0 {
+		return "current settings"
+	}
+
+	return strings.Join(settings, ", ")
+}
+
+func printAbComparison(base *abRun, variant *abRun) {
+	fmt.Printf("%-8s %10s %10s %14s  %s\n", "run", "avg fps", "1% low", "frametime ms", "settings")
+
+	for _, run := range []*abRun{base, variant} {
+		if run.Result == nil {
+			fmt.Printf("%-8s %10s %10s %14s  %s\n", run.Name, "-", "-", "-", describeSettings(run.Settings))
+			continue
+		}
+
+		fmt.Printf("%-8s %10.1f %10.1f %14.2f  %s\n", run.Name, run.Result.AverageFps, run.Result.OnePercentLowFps, run.Result.AverageFrametimeMs, describeSettings(run.Settings))
+	}
+
+	if base.Result == nil || variant.Result == nil {
+		return
+	}
+
+	fmt.Printf("variant: %+.1f%% average fps, %+.1f%% 1%% low\n",
+		percentChange(base.Result.AverageFps, variant.Result.AverageFps),
+		percentChange(base.Result.OnePercentLowFps, variant.Result.OnePercentLowFps))
+}
+
+func percentChange(from float64, to float64) float64 {
+	return (to - from) / from * 100
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/benchmark"
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/launcher"
+	"github.com/fpetros1/linux-game-launcher/pkg/session"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// BENCH_STOP_GRACE leaves MangoHud time to write its log before the game is asked to quit, BENCH_KILL_DELAY is how
+// long the game then has before it is killed
+const BENCH_STOP_GRACE = 5 * time.Second
+const BENCH_KILL_DELAY = 15 * time.Second
+
+// File descriptor the launches of a suite emit their events on, the first one after stdin, stdout and stderr
+const BENCH_EVENTS_FD = 3
+
+// benchRun is a run of a suite ready to launch, its arguments checked and its overlay written
+type benchRun struct {
+	run      benchmark.SuiteRun
+	args     []string
+	folder   string
+	warmup   time.Duration
+	duration time.Duration
+}
+
+func benchCommand(args []string) {
+	if len(args) < 2 || args[0] != "suite" {
+		fmt.Fprintf(os.Stderr, "Usage: %s bench suite <suite file> [--output=FILE.csv|FILE.json]\n", config.APP_NAME)
+		os.Exit(1)
+	}
+
+	output := ""
+
+	for _, arg := range args[2:] {
+		if !strings.HasPrefix(arg, "--output=") {
+			fmt.Fprintf(os.Stderr, "Unknown bench suite option: %s\n", arg)
+			os.Exit(1)
+		}
+
+		output = strings.TrimPrefix(arg, "--output=")
+	}
+
+	runs, err := prepareSuite(args[1])
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(system.EXIT_CONFIG_ERROR)
+	}
+
+	self, err := os.Executable()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find the plauncher executable: %s\n", err)
+		os.Exit(1)
+	}
+
+	results := make([]benchmark.SuiteResult, 0, len(runs))
+
+	for index, run := range runs {
+		if !jsonOutput {
+			fmt.Printf("Benchmarking %d/%d: %s, frames are logged for %s after %s\n", index+1, len(runs), run.run.Name, run.duration, run.warmup)
+		}
+
+		results = append(results, runSuiteGame(self, run))
+	}
+
+	if output != "" {
+		if err := writeSuiteReport(output, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write %s: %s\n", output, err)
+			os.Exit(1)
+		}
+	}
+
+	if jsonOutput {
+		printJSON(map[string]any{"version": versionString(), "results": results})
+		return
+	}
+
+	printSuiteResults(results)
+}
+
+// The whole suite is checked before the first launch, from the launch flags to the profiles, a mistake must not
+// surface hours into it
+func prepareSuite(suiteFile string) ([]benchRun, error) {
+	suite, err := benchmark.LoadSuite(suiteFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]benchRun, 0, len(suite.Runs))
+
+	for _, run := range suite.Runs {
+		words, err := wrappers.SplitShellWords(run.Command)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", run.Name, err)
+		}
+
+		if run.Profile != "" {
+			if !slices.Contains(config.ListProfiles(paths), run.Profile) {
+				return nil, fmt.Errorf("%s: no profile named %s", run.Name, run.Profile)
+			}
+
+			words = append([]string{"--" + config.PROFILE_FLAG + "=" + run.Profile}, words...)
+		}
+
+		if err := config.CheckArgvFlags(append([]string{config.APP_NAME}, words...)); err != nil {
+			return nil, fmt.Errorf("%s: %s", run.Name, err)
+		}
+
+		folder, err := prepareBenchmarkOverlay(run.Set)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", run.Name, err)
+		}
+
+		warmup, duration, _ := suite.Timing(run)
+		runs = append(runs, benchRun{run, words, folder, warmup, duration})
+	}
+
+	return runs, nil
+}
+
+// Launches run with MangoHud logging its frames, then asks the game to quit once they are logged
+func runSuiteGame(self string, run benchRun) benchmark.SuiteResult {
+	result := benchmark.SuiteResult{Name: run.run.Name, Profile: run.run.Profile, ExitCode: -1}
+	events, eventsWriter, err := os.Pipe()
+
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	launchArgs := []string{"run", "--" + config.OVERLAY_PROP + "=" + filepath.Join(run.folder, AB_OVERLAY_FILENAME), fmt.Sprintf("--events-fd=%d", BENCH_EVENTS_FD)}
+	cmdHandle := exec.Command(self, append(launchArgs, run.args...)...)
+	cmdHandle.Env = append(os.Environ(), "MANGOHUD_CONFIG="+benchmark.MangohudConfig(run.folder, run.warmup, run.duration))
+	cmdHandle.ExtraFiles = []*os.File{eventsWriter}
+	cmdHandle.Stdin = os.Stdin
+	cmdHandle.Stderr = os.Stderr
+
+	if !jsonOutput {
+		cmdHandle.Stdout = os.Stdout
+	}
+
+	startedAt := time.Now()
+	_, err = system.Exec.Start(cmdHandle)
+	eventsWriter.Close()
+
+	if err != nil {
+		events.Close()
+		result.Error = err.Error()
+		return result
+	}
+
+	stopper := &gameStopper{after: run.warmup + run.duration + BENCH_STOP_GRACE}
+	go stopper.watch(events)
+
+	result.ExitCode, _ = system.Exec.Wait(cmdHandle)
+	result.Duration = time.Since(startedAt).Round(time.Second).Seconds()
+	stopper.cancel()
+	events.Close()
+
+	logFile, err := benchmark.LatestLog(run.folder)
+
+	if err != nil {
+		result.Error = fmt.Sprintf("%s, is MangoHud installed?", err)
+		return result
+	}
+
+	if frames, err := benchmark.ParseMangohudLog(logFile); err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Result = &frames
+	}
+
+	return result
+}
+
+// gameStopper ends the game of a launch once its frames are logged, the game pid coming from its game-started event
+type gameStopper struct {
+	after     time.Duration
+	timers    []*time.Timer
+	cancelled bool
+	lock      sync.Mutex
+}
+
+func (stopper *gameStopper) watch(events *os.File) {
+	scanner := bufio.NewScanner(events)
+
+	for scanner.Scan() {
+		event := launcher.LaunchEvent{}
+
+		if json.Unmarshal(scanner.Bytes(), &event) != nil || event.Event != launcher.EVENT_GAME_STARTED {
+			continue
+		}
+
+		// Numbers decode as float64 in a map[string]any
+		pid, _ := event.Fields["pid"].(float64)
+
+		stopper.lock.Lock()
+
+		if !stopper.cancelled && pid > 0 {
+			stopper.timers = append(stopper.timers,
+				time.AfterFunc(stopper.after, func() { signalProcessTree(int(pid), syscall.SIGTERM) }),
+				time.AfterFunc(stopper.after+BENCH_KILL_DELAY, func() { signalProcessTree(int(pid), syscall.SIGKILL) }))
+		}
+
+		stopper.lock.Unlock()
+	}
+}
+
+func (stopper *gameStopper) cancel() {
+	stopper.lock.Lock()
+	defer stopper.lock.Unlock()
+
+	stopper.cancelled = true
+
+	for _, timer := range stopper.timers {
+		timer.Stop()
+	}
+}
+
+func signalProcessTree(pid int, signal syscall.Signal) {
+	for _, process := range session.ProcessTree(pid) {
+		syscall.Kill(process, signal)
+	}
+}
+
+// Writes the results as CSV when file ends with .csv, as JSON otherwise
+func writeSuiteReport(file string, results []benchmark.SuiteResult) error {
+	handle, err := os.Create(file)
+
+	if err != nil {
+		return err
+	}
+
+	defer handle.Close()
+
+	if strings.EqualFold(filepath.Ext(file), ".csv") {
+		return benchmark.WriteSuiteCSV(handle, results)
+	}
+
+	encoder := json.NewEncoder(handle)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(map[string]any{"version": versionString(), "results": results})
+}
+
+func printSuiteResults(results []benchmark.SuiteResult) {
+	fmt.Printf("%10s %10s %14s  %s\n", "avg fps", "1% low", "frametime ms", "run")
+
+	for _, result := range results {
+		if result.Result == nil {
+			fmt.Printf("%10s %10s %14s  %s: %s\n", "-", "-", "-", result.Name, result.Error)
+			continue
+		}
+
+		fmt.Printf("%10.1f %10.1f %14.2f  %s\n", result.Result.AverageFps, result.Result.OnePercentLowFps, result.Result.AverageFrametimeMs, result.Name)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/steam"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+func cacheCommand(args []string) {
+	if len(args) < 1 || args[0] != "warm" {
+		fmt.Fprintf(os.Stderr, "Usage: %s cache warm\n", config.APP_NAME)
+		os.Exit(1)
+	}
+
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	configuration, _ := config.LoadConfiguration(paths)
+	config.MakeSureFoldersExist(paths.AppNamesCacheFolder, paths.UmuIdsCacheFolder)
+
+	type warmedApp struct {
+		steam.InstalledApp
+		CachedName string   `json:"cached-name"`
+		UmuId      string   `json:"umu-id"`
+		Errors     []string `json:"errors"`
+	}
+
+	warmed := make([]warmedApp, 0)
+	failed := false
+
+	for _, app := range steam.InstalledApps(paths.HomeDir) {
+		entry := warmedApp{app, "", "", make([]string, 0)}
+
+		if name, _, err := steam.CacheInstalledAppName(app, paths.AppNamesCacheFolder, configuration.Metadata); err != nil {
+			entry.Errors = append(entry.Errors, err.Error())
+		} else {
+			entry.CachedName = name
+		}
+
+		umuId, cached := wrappers.CachedUmuId(paths.UmuIdsCacheFolder, wrappers.UMU_STORE_STEAM, app.Id)
+
+		if !cached {
+			if umuId, err = wrappers.FetchUmuId(paths.UmuIdsCacheFolder, wrappers.UMU_STORE_STEAM, app.Id); err != nil {
+				entry.Errors = append(entry.Errors, err.Error())
+			}
+		}
+
+		entry.UmuId = umuId
+		failed = failed || len(entry.Errors) > 0
+		warmed = append(warmed, entry)
+
+		if !jsonOutput {
+			fmt.Printf("%s\t%s\tumu id: %s\n", app.Id, entry.CachedName, entry.UmuId)
+
+			for _, warmErr := range entry.Errors {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", app.Id, warmErr)
+			}
+		}
+	}
+
+	if jsonOutput {
+		printJSON(warmed)
+	} else if len(warmed) == 0 {
+		fmt.Println("No installed Steam app found")
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
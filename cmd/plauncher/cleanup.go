@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+)
+
+func cleanup() {
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	removed := config.RemoveHomeShortcuts(paths)
+
+	if _, err := os.Stat(paths.ConfigurationFile); err == nil {
+		configuration, _ := config.LoadConfiguration(paths)
+
+		if configuration.HomeShortcuts.PrefixLinks != "" {
+			linksFolder := config.ExpandUserPath(configuration.HomeShortcuts.PrefixLinks, paths.HomeDir)
+			removed = append(removed, prefix.RemovePrefixLinks(linksFolder, paths.AppDataFolder)...)
+		}
+	}
+
+	if jsonOutput {
+		printJSON(map[string][]string{"removed": removed})
+		return
+	}
+
+	if len(removed) == 0 {
+		fmt.Println("Nothing to clean up")
+		return
+	}
+
+	for _, path := range removed {
+		fmt.Printf("Removed %s\n", path)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+func configCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s config path\n       %s config show\n       %s config edit\n       %s config profiles\n", config.APP_NAME, config.APP_NAME, config.APP_NAME, config.APP_NAME)
+		os.Exit(1)
+	}
+
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "path":
+		configPaths(paths)
+	case "show":
+		showConfiguration(paths)
+	case "edit":
+		editConfiguration(paths)
+	case "profiles":
+		listProfiles(paths)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown config command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// Prints where the configuration, the system-wide one and the overrides are read from
+func configPaths(paths config.Paths) {
+	configPaths := map[string]string{
+		"configuration":        paths.ConfigurationFile,
+		"system-configuration": paths.SystemConfigurationFile,
+		"overrides":            paths.OverridesFolder,
+		"profiles":             paths.ProfilesFolder,
+		"debug-log":            paths.DebugFile,
+	}
+
+	if jsonOutput {
+		printJSON(configPaths)
+		return
+	}
+
+	for _, key := range []string{"configuration", "system-configuration", "overrides", "profiles", "debug-log"} {
+		fmt.Printf("%s: %s\n", key, configPaths[key])
+	}
+}
+
+// Prints the global configuration, the user one layered over the system-wide one, before any game override
+func showConfiguration(paths config.Paths) {
+	configuration, _ := config.LoadConfiguration(paths)
+	printConfiguration(configuration, jsonOutput)
+}
+
+func printConfiguration(configuration config.Configuration, asJson bool) {
+	content, err := yaml.Marshal(configuration)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if asJson {
+		configurationContent := make(map[string]any)
+		yaml.Unmarshal(content, &configurationContent)
+		printJSON(configurationContent)
+		return
+	}
+
+	fmt.Print(string(content))
+}
+
+// Prints the profiles --profile can select
+func listProfiles(paths config.Paths) {
+	profiles := config.ListProfiles(paths)
+
+	if jsonOutput {
+		printJSON(profiles)
+		return
+	}
+
+	for _, profile := range profiles {
+		fmt.Println(profile)
+	}
+}
+
+// Opens the user configuration in $VISUAL or $EDITOR, creating it first when it does not exist yet
+func editConfiguration(paths config.Paths) {
+	editor := os.Getenv("VISUAL")
+
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	if editor == "" {
+		editor = "vi"
+	}
+
+	config.MakeSureFoldersExist(paths.AppConfigFolder)
+	config.LoadConfiguration(paths)
+
+	cmdHandle := exec.Command("sh", "-c", editor+` "$1"`, editor, paths.ConfigurationFile)
+	cmdHandle.Stdin = os.Stdin
+	cmdHandle.Stdout = os.Stdout
+	cmdHandle.Stderr = os.Stderr
+
+	if err := cmdHandle.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %s\n", editor, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/session"
+)
+
+// controlLaunch is a running launch listening for control commands
+type controlLaunch struct {
+	Socket string `json:"socket"`
+	Status string `json:"status"`
+}
+
+// Sends a command to the most recent running launch, or to the one whose game --game names, and prints its reply.
+// Without a command, lists the running launches.
+func controlCommand(args []string) {
+	game := ""
+	words := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		if value, found := strings.CutPrefix(arg, "--game="); found {
+			game = value
+			continue
+		}
+
+		words = append(words, arg)
+	}
+
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	launches := runningControlLaunches(paths, game)
+
+	if len(words) == 0 {
+		if jsonOutput {
+			printJSON(map[string]any{"launches": launches})
+			return
+		}
+
+		for _, launch := range launches {
+			fmt.Printf("%s\t%s\n", launch.Status, launch.Socket)
+		}
+
+		return
+	}
+
+	if len(launches) == 0 {
+		fmt.Fprintf(os.Stderr, "No running launch to control, control.enabled must be set when the game is launched\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s control [--game=NAME] <%s> [arguments]\n", config.APP_NAME, strings.Join(session.ControlCommandNames(), "|"))
+		os.Exit(1)
+	}
+
+	reply, err := session.SendControl(launches[0].Socket, strings.Join(words, " "))
+
+	if jsonOutput {
+		output := map[string]any{"launch": launches[0], "reply": reply}
+
+		if err != nil {
+			output["error"] = err.Error()
+		}
+
+		printJSON(output)
+	} else if err == nil {
+		fmt.Printf("%s: %s\n", launches[0].Status, reply)
+	}
+
+	if err != nil {
+		if !jsonOutput {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", launches[0].Status, err)
+		}
+
+		os.Exit(1)
+	}
+}
+
+// Sends a MangoHud command to the running launch, plauncher hud toggle being plauncher control mangohud toggle
+func hudCommand(args []string) {
+	if !slices.ContainsFunc(args, func(arg string) bool { return !strings.HasPrefix(arg, "--") }) {
+		fmt.Fprintf(os.Stderr, "Usage: %s hud [--game=NAME] toggle|logging start|logging stop\n", config.APP_NAME)
+		os.Exit(1)
+	}
+
+	controlCommand(append([]string{"mangohud"}, args...))
+}
+
+// Changes a setting of the gamescope of the running launch, plauncher gs set filter fsr being plauncher control
+// gamescope set filter fsr
+func gamescopeCommand(args []string) {
+	if !slices.ContainsFunc(args, func(arg string) bool { return !strings.HasPrefix(arg, "--") }) {
+		fmt.Fprintf(os.Stderr, "Usage: %s gs [--game=NAME] set <%s> <value>\n", config.APP_NAME, strings.Join(session.GamescopePropertyNames(), "|"))
+		os.Exit(1)
+	}
+
+	controlCommand(append([]string{"gamescope"}, args...))
+}
+
+// The running launches, the most recent first, left to the ones of game when it is set
+func runningControlLaunches(paths config.Paths, game string) []controlLaunch {
+	launches := make([]controlLaunch, 0)
+
+	for _, socket := range session.ControlSockets(paths) {
+		status, err := session.SendControl(socket, "status")
+
+		if err != nil {
+			continue
+		}
+
+		if game != "" && !strings.Contains(strings.ToLower(status), strings.ToLower(game)) {
+			continue
+		}
+
+		launches = append(launches, controlLaunch{socket, status})
+	}
+
+	return launches
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/doctor"
+)
+
+func doctorCommand() {
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Checking older files migrates them in memory, the report says all there is to know
+	log.SetOutput(io.Discard)
+	checks := doctor.Run(paths)
+
+	if jsonOutput {
+		printJSON(checks)
+	} else {
+		for _, check := range checks {
+			fmt.Printf("%-4s  %s: %s\n", check.Status, check.Name, check.Detail)
+
+			if check.Fix != "" {
+				fmt.Printf("      fix: %s\n", check.Fix)
+			}
+		}
+	}
+
+	if doctor.Failed(checks) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/launcher"
+)
+
+func exportLaunchScript(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s export-script <game> [output-file]\n", config.APP_NAME)
+		os.Exit(1)
+	}
+
+	game := args[0]
+	outputFile := config.GameSlug(game) + ".sh"
+
+	if len(args) > 1 {
+		outputFile = args[1]
+	}
+
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	record, err := launcher.ReadLaunchRecord(game, paths.LaunchesFolder)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// The script carries the environment of the launch, tokens included, only its owner may read it
+	if err := os.WriteFile(outputFile, []byte(launcher.BuildLaunchScript(record)), config.PRIVATE_PERMISSION); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write script %s: %s\n", outputFile, err)
+		os.Exit(1)
+	}
+
+	if err := os.Chmod(outputFile, config.PRIVATE_PERMISSION); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to restrict script %s to its owner: %s\n", outputFile, err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		printJSON(map[string]string{"game": game, "script": outputFile})
+		return
+	}
+
+	fmt.Printf("Launch script for %s written to %s\n", game, outputFile)
+}
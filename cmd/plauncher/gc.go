@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/gc"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+func gcCommand(args []string) {
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	dryRun := slices.Contains(args, "--dry-run")
+	configuration, _ := config.LoadConfiguration(paths)
+	removed := make([]gc.Removal, 0)
+	freed := int64(0)
+
+	removals, kept := gc.Find(paths, configuration, time.Now())
+
+	for _, removal := range removals {
+		if !dryRun {
+			if err := system.FS.RemoveAll(removal.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to remove %s: %s\n", removal.Path, err)
+				continue
+			}
+		}
+
+		removed = append(removed, removal)
+		freed += removal.Bytes
+
+		if !jsonOutput {
+			fmt.Printf("%s\t%s\t%s\n", removal.Kind, formatBytes(removal.Bytes), removal.Path)
+		}
+	}
+
+	if jsonOutput {
+		printJSON(map[string]any{"removed": removed, "kept": kept, "freed": freed, "dry-run": dryRun})
+		return
+	}
+
+	for _, keptData := range kept {
+		fmt.Printf("%s\tkept\t%s: %s\n", keptData.Kind, keptData.Path, keptData.Reason)
+	}
+
+	if dryRun {
+		fmt.Printf("%s would be freed, run again without --dry-run to remove\n", formatBytes(freed))
+		return
+	}
+
+	fmt.Printf("%s freed\n", formatBytes(freed))
+}
+
+func formatBytes(bytes int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB"}
+	value := float64(bytes)
+	unit := 0
+
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/launcher"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// headlessOptions are the flags of plauncher headless, given before the launch flags
+type headlessOptions struct {
+	session string
+	vt      int
+	wait    bool
+	list    bool
+}
+
+// Starts a game in gamescope on the screen of the machine from a terminal without one, e.g. over SSH: in a
+// graphical session of the user, the active one unless --session picks another, or on the virtual terminal --vt
+// names. The launch is detached from the terminal unless --wait is given.
+func headlessCommand(args []string) {
+	options, launchArgs, err := parseHeadlessArgs(args)
+
+	if err == nil && !options.list && len(launchArgs) == 0 {
+		err = errors.New("Nothing to launch")
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		fmt.Fprintf(os.Stderr, "Usage: %s headless [--session=ID|--vt=N] [--wait] [launch flags] <game command>\n", config.APP_NAME)
+		fmt.Fprintf(os.Stderr, "       %s headless --list\n", config.APP_NAME)
+		os.Exit(1)
+	}
+
+	if options.list {
+		printGraphicalSessions(launcher.FindGraphicalSessions())
+		return
+	}
+
+	if err := config.CheckArgvFlags(append([]string{config.APP_NAME}, launchArgs...)); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(system.EXIT_CONFIG_ERROR)
+	}
+
+	self, err := os.Executable()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find the plauncher executable: %s\n", err)
+		os.Exit(1)
+	}
+
+	// Flags given after it, -!G included, still win
+	command := append([]string{self, "run", "--" + config.SET_FLAG + "=gamescope.enabled=true"}, launchArgs...)
+	environment := withoutVariables(os.Environ(), launcher.SESSION_VARIABLES...)
+	target := ""
+
+	if options.vt > 0 {
+		command, err = launcher.HeadlessCommand(options.vt, command)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(system.EXIT_MISSING_DEPENDENCY)
+		}
+
+		// Only the runtime folder is kept, gamescope finds no display to nest in and takes the screen
+		environment = append(environment, "XDG_RUNTIME_DIR="+os.Getenv("XDG_RUNTIME_DIR"))
+		target = fmt.Sprintf("virtual terminal %d", options.vt)
+	} else {
+		session, err := pickGraphicalSession(launcher.FindGraphicalSessions(), options.session)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+
+		environment = append(environment, session.Environment...)
+		target = describeGraphicalSession(session)
+	}
+
+	cmdHandle := exec.Command(command[0], command[1:]...)
+	cmdHandle.Env = environment
+
+	if options.wait {
+		cmdHandle.Stdin = os.Stdin
+		cmdHandle.Stdout = os.Stdout
+		cmdHandle.Stderr = os.Stderr
+	} else {
+		// The launch outlives the SSH connection, its output is in the debug log
+		cmdHandle.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	}
+
+	pid, err := system.Exec.Start(cmdHandle)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to launch: %s\n", err)
+		os.Exit(system.EXIT_GAME_START_FAILED)
+	}
+
+	fmt.Fprintf(os.Stderr, "Launched in %s, pid %d\n", target, pid)
+
+	if !options.wait {
+		fmt.Fprintf(os.Stderr, "%s control lists it while it runs\n", config.APP_NAME)
+		return
+	}
+
+	exitCode, _ := system.Exec.Wait(cmdHandle)
+	os.Exit(exitCode)
+}
+
+// The headless flags come first, everything from the first other argument on is the launch
+func parseHeadlessArgs(args []string) (headlessOptions, []string, error) {
+	options := headlessOptions{}
+
+	for index, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+
+		switch name {
+		case "--session":
+			options.session = value
+		case "--vt":
+			vt, err := strconv.Atoi(value)
+
+			if err != nil || vt < 1 {
+				return options, nil, fmt.Errorf("Invalid --vt %s, expected a virtual terminal number from 1", value)
+			}
+
+			options.vt = vt
+		case "--wait":
+			options.wait = true
+		case "--list":
+			options.list = true
+		default:
+			if options.session != "" && options.vt > 0 {
+				return options, nil, errors.New("--session and --vt can not be used together")
+			}
+
+			return options, args[index:], nil
+		}
+	}
+
+	return options, nil, nil
+}
+
+// The session with id, or the first one, active ones coming first
+func pickGraphicalSession(sessions []launcher.GraphicalSession, id string) (launcher.GraphicalSession, error) {
+	if len(sessions) == 0 {
+		return launcher.GraphicalSession{}, errors.New("No graphical session of this user is running, log in on the machine or use --vt=N")
+	}
+
+	if id == "" {
+		return sessions[0], nil
+	}
+
+	index := slices.IndexFunc(sessions, func(session launcher.GraphicalSession) bool { return session.Id == id })
+
+	if index < 0 {
+		return launcher.GraphicalSession{}, fmt.Errorf("No graphical session %s, %s headless --list shows them", id, config.APP_NAME)
+	}
+
+	return sessions[index], nil
+}
+
+func describeGraphicalSession(session launcher.GraphicalSession) string {
+	details := make([]string, 0, 3)
+
+	for _, detail := range []string{session.Type, session.Seat} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+
+	if session.VT != "" {
+		details = append(details, "vt "+session.VT)
+	}
+
+	if len(details) == 0 {
+		return "session " + session.Id
+	}
+
+	return fmt.Sprintf("session %s (%s)", session.Id, strings.Join(details, ", "))
+}
+
+func printGraphicalSessions(sessions []launcher.GraphicalSession) {
+	if jsonOutput {
+		printJSON(map[string]any{"sessions": sessions})
+		return
+	}
+
+	for _, session := range sessions {
+		active := ""
+
+		if session.Active {
+			active = "\tactive"
+		}
+
+		fmt.Printf("%s%s\n", describeGraphicalSession(session), active)
+	}
+}
+
+// environ without the variables named
+func withoutVariables(environ []string, names ...string) []string {
+	return slices.DeleteFunc(slices.Clone(environ), func(variable string) bool {
+		name, _, _ := strings.Cut(variable, "=")
+		return slices.Contains(names, name)
+	})
+}
@@ -0,0 +1,94 @@
+// /usr/bin/true; exec go run "$0" "$@"
+package main
+
+import (
+	"os"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// Management subcommands listed in the usage. A first argument matching none of them is a game command, run as
+// with plauncher run
+var subcommandNames = []string{"run", "config", "prefix", "cache", "overrides", "steam", "recipes", "gc", "ab", "bench", "queue", "wake", "control", "hud", "gs", "headless", "doctor", "cleanup", "export-script", "version"}
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "run":
+			// run --sequential takes a queue of launches rather than one game command, wherever the flag is given
+			if queueArgs, sequential := takeSequentialFlag(os.Args[2:]); sequential {
+				queueCommand(parseOutputFlag(queueArgs))
+				return
+			}
+
+			runCommand(append([]string{os.Args[0]}, os.Args[2:]...))
+			return
+		case "version":
+			parseOutputFlag(os.Args[2:])
+			printVersion()
+			return
+		case "export-script":
+			exportLaunchScript(parseOutputFlag(os.Args[2:]))
+			return
+		case "cleanup":
+			parseOutputFlag(os.Args[2:])
+			cleanup()
+			return
+		case "config":
+			configCommand(parseOutputFlag(os.Args[2:]))
+			return
+		// override is the spelling older scripts use
+		case "overrides", "override":
+			override(parseOutputFlag(os.Args[2:]))
+			return
+		case "prefix":
+			prefixCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "steam":
+			steamCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "recipes":
+			recipesCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "cache":
+			cacheCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "gc":
+			gcCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "ab":
+			abCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "bench":
+			benchCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "queue":
+			queueCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "control":
+			controlCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "hud":
+			hudCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "gs":
+			gamescopeCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "headless":
+			headlessCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "wake":
+			wakeCommand(parseOutputFlag(os.Args[2:]))
+			return
+		case "doctor":
+			parseOutputFlag(os.Args[2:])
+			doctorCommand()
+			return
+		case wrappers.NETWORK_SHAPE_COMMAND:
+			networkShape(os.Args[2:])
+			return
+		}
+	}
+
+	runCommand(os.Args)
+}
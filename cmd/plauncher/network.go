@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// Runs the command after -- in its own network namespace, the network wrapper puts it in front of the game
+func networkShape(args []string) {
+	separator := slices.Index(args, "--")
+	limit := ""
+	outboundInterface := ""
+
+	for _, arg := range args[:max(separator, 0)] {
+		if value, found := strings.CutPrefix(arg, "--limit="); found {
+			limit = value
+		} else if value, found := strings.CutPrefix(arg, "--interface="); found {
+			outboundInterface = value
+		}
+	}
+
+	if separator < 0 || separator == len(args)-1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s [--limit=<rate>] [--interface=<name>] -- <command>\n", config.APP_NAME, wrappers.NETWORK_SHAPE_COMMAND)
+		os.Exit(1)
+	}
+
+	exitCode, err := wrappers.RunInNetworkNamespace(limit, outboundInterface, args[separator+1:])
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	if exitCode < 0 {
+		exitCode = 1
+	}
+
+	os.Exit(exitCode)
+}
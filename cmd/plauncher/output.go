@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Set by the global --json flag: subcommands print one JSON document on stdout instead of text
+var jsonOutput = false
+
+// Removes --json from args, enabling JSON output when it was there
+func parseOutputFlag(args []string) []string {
+	if !slices.Contains(args, "--json") {
+		return args
+	}
+
+	jsonOutput = true
+
+	return slices.DeleteFunc(slices.Clone(args), func(arg string) bool { return arg == "--json" })
+}
+
+// Prints value as one line of JSON, for the subcommands that keep running and report as they go
+func printJSONLine(value any) {
+	if err := json.NewEncoder(os.Stdout).Encode(value); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode JSON output: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func printJSON(value any) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(value); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode JSON output: %s\n", err)
+		os.Exit(1)
+	}
+}
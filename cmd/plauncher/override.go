@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/launcher"
+	"github.com/fpetros1/linux-game-launcher/pkg/steam"
+
+	"gopkg.in/yaml.v3"
+)
+
+func override(args []string) {
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if len(args) > 0 && slices.Contains([]string{"fetch", "update", "pin", "unpin", "sources"}, args[0]) {
+		overrideSources(paths, args)
+		return
+	}
+
+	if len(args) > 0 && args[0] == "renames" {
+		overrideRenames(paths, args[1:])
+		return
+	}
+
+	if len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: %s overrides new <game> [--template=<name>]\n       %s overrides show <game>\n       %s overrides set <game> key=value...\n       %s overrides explain <game>\n", config.APP_NAME, config.APP_NAME, config.APP_NAME, config.APP_NAME)
+		fmt.Fprintf(os.Stderr, "       %s overrides fetch <git repository or file url>\n       %s overrides update\n       %s overrides pin <source> [ref]\n       %s overrides unpin <source>\n       %s overrides sources\n       %s overrides renames [--refresh] [--apply]\n", config.APP_NAME, config.APP_NAME, config.APP_NAME, config.APP_NAME, config.APP_NAME, config.APP_NAME)
+		os.Exit(1)
+	}
+
+	overrideFile := config.OverrideFile(paths, args[1])
+
+	switch args[0] {
+	case "new":
+		newOverride(paths, args[1:])
+	case "show":
+		content, err := os.ReadFile(overrideFile)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "No override for %s: %s\n", args[1], err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			overrideContent := make(map[string]any)
+
+			if err := yaml.Unmarshal(content, &overrideContent); err != nil {
+				fmt.Fprintf(os.Stderr, "%s is not valid YAML: %s\n", overrideFile, err)
+				os.Exit(1)
+			}
+
+			printJSON(overrideContent)
+			return
+		}
+
+		fmt.Print(string(content))
+	case "set":
+		if len(args) < 3 {
+			fmt.Fprintf(os.Stderr, "Usage: %s overrides set <game> key=value...\n", config.APP_NAME)
+			os.Exit(1)
+		}
+
+		// Keys the override leaves out keep following the global configuration, so only the assigned ones are written
+		config.MakeSureFoldersExist(paths.OverridesFolder)
+
+		assigned := make([]map[string]string, 0)
+
+		for _, assignment := range args[2:] {
+			key, value, found := strings.Cut(assignment, "=")
+
+			if !found || key == "" {
+				fmt.Fprintf(os.Stderr, "Expected key=value, got: %s\n", assignment)
+				os.Exit(1)
+			}
+
+			if err := config.SetOverrideValue(overrideFile, key, value); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+
+			assigned = append(assigned, map[string]string{"file": overrideFile, "key": key, "value": value})
+
+			if !jsonOutput {
+				fmt.Printf("%s: %s=%s\n", overrideFile, key, value)
+			}
+		}
+
+		if jsonOutput {
+			printJSON(assigned)
+		}
+	case "explain":
+		explainOverrides(paths, args[1])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown override command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// Creates the override of a game from a template, or as a copy of the global configuration without one
+func newOverride(paths config.Paths, args []string) {
+	template := ""
+
+	for _, arg := range args[1:] {
+		if value, isTemplate := strings.CutPrefix(arg, "--template="); isTemplate {
+			template = value
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "Unknown argument: %s, templates: %s\n", arg, strings.Join(config.OverrideTemplateNames(paths), ", "))
+		os.Exit(1)
+	}
+
+	overrideFile := config.OverrideFile(paths, args[0])
+
+	if template == "" {
+		if _, err := os.Stat(overrideFile); err == nil {
+			fmt.Fprintf(os.Stderr, "%s already exists\n", overrideFile)
+			os.Exit(1)
+		}
+
+		config.MakeSureFoldersExist(paths.OverridesFolder)
+		globalConfiguration, _ := config.LoadConfiguration(paths)
+		config.CreateOverrideFile(globalConfiguration, overrideFile)
+	} else if _, err := config.CreateOverrideFromTemplate(paths, args[0], template); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		printJSON(map[string]string{"game": args[0], "file": overrideFile, "template": template})
+		return
+	}
+
+	fmt.Println(overrideFile)
+}
+
+// Manages the community override sources, merged between the system-wide and the user overrides
+func overrideSources(paths config.Paths, args []string) {
+	var sources []config.OverrideSource
+	var err error
+
+	switch {
+	case args[0] == "sources":
+		sources, err = config.ReadOverrideSources(paths)
+	case args[0] == "update":
+		sources, err = config.UpdateOverrideSources(paths)
+	case len(args) < 2:
+		fmt.Fprintf(os.Stderr, "Usage: %s overrides %s <%s>\n", config.APP_NAME, args[0], map[string]string{"fetch": "url", "pin": "source", "unpin": "source"}[args[0]])
+		os.Exit(1)
+	case args[0] == "fetch":
+		source, fetchErr := config.FetchOverrideSource(paths, args[1])
+		sources, err = []config.OverrideSource{source}, fetchErr
+	case args[0] == "pin":
+		ref := ""
+
+		if len(args) > 2 {
+			ref = args[2]
+		}
+
+		source, pinErr := config.PinOverrideSource(paths, args[1], ref)
+		sources, err = []config.OverrideSource{source}, pinErr
+	case args[0] == "unpin":
+		source, unpinErr := config.UnpinOverrideSource(paths, args[1])
+		sources, err = []config.OverrideSource{source}, unpinErr
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		// Only updates report what went through along with the failures
+		if args[0] != "update" {
+			os.Exit(1)
+		}
+	}
+
+	if jsonOutput {
+		printJSON(sources)
+	} else {
+		for _, source := range sources {
+			fmt.Printf("%s\t%s\t%s\t%s\n", source.Name, source.Kind, source.Url, source.Pin)
+		}
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// Lists the installed games Steam renamed since their name was cached, moving their override and prefixes to the
+// new name with --apply
+func overrideRenames(paths config.Paths, args []string) {
+	apply := slices.Contains(args, "--apply")
+	configuration, _ := config.LoadConfiguration(paths)
+	renames := steam.FindRenames(paths.HomeDir, paths.AppNamesCacheFolder, configuration.Metadata, slices.Contains(args, "--refresh"))
+
+	type migratedRename struct {
+		steam.Rename
+		Moved []string `json:"moved"`
+		Error string   `json:"error,omitempty"`
+	}
+
+	migrated := make([]migratedRename, 0, len(renames))
+	failed := false
+
+	for _, rename := range renames {
+		entry := migratedRename{rename, make([]string, 0), ""}
+
+		if !jsonOutput {
+			fmt.Printf("%s\t%s -> %s\n", rename.Appid, rename.OldName, rename.NewName)
+		}
+
+		if apply {
+			moved, err := launcher.MigrateRename(paths, configuration, rename)
+			entry.Moved = moved
+
+			if err != nil {
+				entry.Error = err.Error()
+				failed = true
+				fmt.Fprintln(os.Stderr, err)
+			}
+
+			for _, move := range moved {
+				if !jsonOutput {
+					fmt.Printf("\tmoved %s\n", move)
+				}
+			}
+		}
+
+		migrated = append(migrated, entry)
+	}
+
+	if jsonOutput {
+		printJSON(migrated)
+	} else if len(renames) == 0 {
+		fmt.Println("No renamed game")
+	} else if !apply {
+		fmt.Println("Run again with --apply to move their overrides and prefixes to the new names")
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// Prints the effective value of every key for game, a name or an appid, and the file it comes from
+func explainOverrides(paths config.Paths, game string) {
+	configuration, _ := config.LoadConfiguration(paths)
+	configuration.Props["name"], configuration.Props["id"] = gameNameAndId(steam.NameCacheFolder(paths.AppNamesCacheFolder, configuration.Metadata), game)
+
+	config.ApplyGameOverrides(&configuration, config.OverrideFolders(paths)...)
+
+	explainedKeys := config.ExplainConfiguration(configuration, "global")
+
+	if jsonOutput {
+		printJSON(explainedKeys)
+		return
+	}
+
+	for _, explained := range explainedKeys {
+		fmt.Printf("%s = %s (%s)\n", explained.Key, explained.Value, explained.Source)
+	}
+}
+
+// Completes a game name or appid with the other half, looked up in the app names cache
+func gameNameAndId(namesCacheFolder string, game string) (string, string) {
+	if _, err := strconv.Atoi(game); err == nil {
+		name, _ := os.ReadFile(filepath.Join(namesCacheFolder, game))
+		return string(name), game
+	}
+
+	entries, _ := os.ReadDir(namesCacheFolder)
+
+	for _, entry := range entries {
+		if name, err := os.ReadFile(filepath.Join(namesCacheFolder, entry.Name())); err == nil && string(name) == game {
+			return game, entry.Name()
+		}
+	}
+
+	return game, ""
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+func prefixCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s prefix list\n       %s prefix path <game>\n       %s prefix prune [--yes]\n       %s prefix repair\n       %s prefix move <game> <new base folder>\n", config.APP_NAME, config.APP_NAME, config.APP_NAME, config.APP_NAME, config.APP_NAME)
+		os.Exit(1)
+	}
+
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if args[0] == "path" {
+		if len(args) < 2 {
+			fmt.Fprintf(os.Stderr, "Usage: %s prefix path <game>\n", config.APP_NAME)
+			os.Exit(1)
+		}
+
+		prefixRoot, found := findPrefix(paths, args[1])
+
+		if !found {
+			fmt.Fprintf(os.Stderr, "No prefix found for %s\n", args[1])
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(map[string]string{"game": args[1], "path": prefixRoot})
+			return
+		}
+
+		fmt.Println(prefixRoot)
+		return
+	}
+
+	if args[0] == "move" {
+		if len(args) < 3 {
+			fmt.Fprintf(os.Stderr, "Usage: %s prefix move <game> <new base folder>\n", config.APP_NAME)
+			os.Exit(1)
+		}
+
+		movePrefix(paths, args[1], args[2])
+		return
+	}
+
+	if args[0] == "repair" {
+		repaired := make([]string, 0)
+
+		for _, base := range config.CompatDataBases(paths) {
+			repairedLinks, err := prefix.RepairCompatDataLinks(base)
+
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to list prefixes in %s: %s\n", base, err)
+				os.Exit(1)
+			}
+
+			repaired = append(repaired, repairedLinks...)
+		}
+
+		if jsonOutput {
+			printJSON(map[string][]string{"repaired": repaired})
+			return
+		}
+
+		for _, link := range repaired {
+			fmt.Printf("Repaired: %s\n", link)
+		}
+
+		return
+	}
+
+	managedPrefixes := make([]prefix.ManagedPrefix, 0)
+
+	for _, base := range config.CompatDataBases(paths) {
+		basePrefixes, err := prefix.ListManagedPrefixes(base)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list prefixes in %s: %s\n", base, err)
+			os.Exit(1)
+		}
+
+		managedPrefixes = append(managedPrefixes, basePrefixes...)
+	}
+
+	switch args[0] {
+	case "list":
+		if jsonOutput {
+			type listedPrefix struct {
+				prefix.ManagedPrefix
+				Orphaned bool `json:"orphaned"`
+			}
+
+			listed := make([]listedPrefix, 0, len(managedPrefixes))
+
+			for _, managedPrefix := range managedPrefixes {
+				listed = append(listed, listedPrefix{managedPrefix, managedPrefix.IsOrphaned()})
+			}
+
+			printJSON(listed)
+			return
+		}
+
+		for _, managedPrefix := range managedPrefixes {
+			manifest := managedPrefix.Manifest
+			fmt.Printf("%s\tid: %s\tproton: %s\tdxvk: %s\tcreated: %s\n", manifest.Name, manifest.Id, manifest.ProtonVersion, manifest.DxvkVersion, manifest.CreatedAt.Format("2006-01-02"))
+		}
+	case "prune":
+		confirmed := slices.Contains(args[1:], "--yes")
+		orphaned := make([]string, 0)
+		removed := make([]string, 0)
+
+		for _, managedPrefix := range managedPrefixes {
+			if !managedPrefix.IsOrphaned() {
+				continue
+			}
+
+			orphaned = append(orphaned, managedPrefix.Folder)
+
+			if !confirmed {
+				if !jsonOutput {
+					fmt.Printf("Orphaned: %s (%s)\n", managedPrefix.Folder, managedPrefix.Manifest.SteamCompatData)
+				}
+				continue
+			}
+
+			if err := system.FS.RemoveAll(managedPrefix.Folder); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to remove %s: %s\n", managedPrefix.Folder, err)
+				continue
+			}
+
+			removed = append(removed, managedPrefix.Folder)
+			system.FS.RemoveAll(prefix.InstancesFolder(managedPrefix.Folder))
+
+			if !jsonOutput {
+				fmt.Printf("Removed: %s\n", managedPrefix.Folder)
+			}
+		}
+
+		if jsonOutput {
+			printJSON(map[string][]string{"orphaned": orphaned, "removed": removed})
+			return
+		}
+
+		if !confirmed {
+			fmt.Println("Run again with --yes to remove the orphaned prefixes")
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown prefix command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// Looks for the prefix of game in every prefix folder, the default one first
+func findPrefix(paths config.Paths, game string) (string, bool) {
+	for _, base := range config.CompatDataBases(paths) {
+		if prefixRoot, found := prefix.FindPrefix(base, game); found {
+			return prefixRoot, true
+		}
+	}
+
+	return "", false
+}
+
+// Moves the prefix of game under newBase and records newBase as its compatdata.base, the prefix goes back when the
+// override cannot be written
+func movePrefix(paths config.Paths, game string, newBase string) {
+	prefixRoot, found := findPrefix(paths, game)
+
+	if !found {
+		fmt.Fprintf(os.Stderr, "No prefix found for %s\n", game)
+		os.Exit(1)
+	}
+
+	newBase, err := filepath.Abs(config.ExpandUserPath(newBase, paths.HomeDir))
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if filepath.Dir(prefixRoot) == newBase {
+		fmt.Fprintf(os.Stderr, "The prefix of %s is already in %s\n", game, newBase)
+		os.Exit(1)
+	}
+
+	manifest, _ := prefix.ReadManifest(prefixRoot)
+	managedPrefix := prefix.ManagedPrefix{Folder: prefixRoot, Manifest: manifest}
+	newPrefixRoot, err := prefix.MovePrefix(managedPrefix, newBase)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		if newPrefixRoot == "" {
+			os.Exit(1)
+		}
+	}
+
+	overrideFile := prefixOverrideFile(paths, game, manifest)
+	config.MakeSureFoldersExist(paths.OverridesFolder)
+
+	// Only the base goes in the override, the game keeps following the global configuration otherwise
+	if err := config.SetOverrideValue(overrideFile, "compatdata.base", strconv.Quote(newBase)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		movedPrefix := prefix.ManagedPrefix{Folder: newPrefixRoot, Manifest: manifest}
+
+		if _, rollbackErr := prefix.MovePrefix(movedPrefix, filepath.Dir(prefixRoot)); rollbackErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to move the prefix back to %s: %s\n", prefixRoot, rollbackErr)
+		}
+
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		printJSON(map[string]string{"game": game, "from": prefixRoot, "to": newPrefixRoot, "override": overrideFile})
+		return
+	}
+
+	fmt.Printf("Moved %s to %s\n", prefixRoot, newPrefixRoot)
+	fmt.Printf("%s: compatdata.base=%s\n", overrideFile, newBase)
+}
+
+// The override launches read: by name, or by appid when only that one exists
+func prefixOverrideFile(paths config.Paths, game string, manifest prefix.Manifest) string {
+	name := manifest.Name
+
+	if name == "" {
+		name = game
+	}
+
+	nameOverrideFile := config.OverrideFile(paths, name)
+
+	if _, err := os.Stat(nameOverrideFile); os.IsNotExist(err) && manifest.Id != "" {
+		if idOverrideFile := config.OverrideFile(paths, manifest.Id); idOverrideFile != nameOverrideFile {
+			if _, err := os.Stat(idOverrideFile); err == nil {
+				return idOverrideFile
+			}
+		}
+	}
+
+	return nameOverrideFile
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const SEQUENTIAL_FLAG = "--sequential"
+
+// queueLaunch is one launch of a queue, an entry being the flags and game command given to plauncher run
+type queueLaunch struct {
+	Entry     string    `json:"entry"`
+	ExitCode  int       `json:"exit-code"`
+	StartedAt time.Time `json:"started-at"`
+	Seconds   float64   `json:"seconds"`
+	Error     string    `json:"error,omitempty"`
+	Skipped   bool      `json:"skipped,omitempty"`
+	args      []string
+}
+
+// Launches the entries of the command line and of --file one after another, then reports how each one went
+func queueCommand(args []string) {
+	entries, stopOnFailure, err := parseQueueArgs(args)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		fmt.Fprintf(os.Stderr, "Usage: %s queue [--file FILE]... [--stop-on-failure] ['[launch flags] <game command>']...\n", config.APP_NAME)
+		os.Exit(1)
+	}
+
+	launches, err := prepareQueue(entries)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(system.EXIT_CONFIG_ERROR)
+	}
+
+	self, err := os.Executable()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find the plauncher executable: %s\n", err)
+		os.Exit(1)
+	}
+
+	failed := false
+
+	for index, launch := range launches {
+		if failed && stopOnFailure {
+			launch.Skipped = true
+			continue
+		}
+
+		if !jsonOutput {
+			fmt.Printf("Launching %d/%d: %s\n", index+1, len(launches), launch.Entry)
+		}
+
+		runQueueLaunch(self, launch, nil)
+		failed = failed || launch.ExitCode != system.EXIT_OK
+	}
+
+	if jsonOutput {
+		printJSON(map[string]any{"launches": launches})
+	} else {
+		printQueueReport(launches)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// Removes --sequential from the arguments of plauncher run, reporting whether it was there. The entries of a
+// queue are quoted whole, so the flag can come before, between or after them
+func takeSequentialFlag(args []string) ([]string, bool) {
+	for index, arg := range args {
+		if arg == SEQUENTIAL_FLAG {
+			return append(append([]string{}, args[:index]...), args[index+1:]...), true
+		}
+	}
+
+	return args, false
+}
+
+func parseQueueArgs(args []string) ([]string, bool, error) {
+	entries := make([]string, 0)
+	stopOnFailure := false
+
+	for index := 0; index < len(args); index++ {
+		arg := args[index]
+
+		switch {
+		case arg == "--stop-on-failure":
+			stopOnFailure = true
+		case arg == "--file" || strings.HasPrefix(arg, "--file="):
+			file, found := strings.CutPrefix(arg, "--file=")
+
+			if !found {
+				if index+1 >= len(args) {
+					return nil, false, errors.New("Flag --file needs a value: FILE")
+				}
+
+				index++
+				file = args[index]
+			}
+
+			fileEntries, err := readQueueFile(file)
+
+			if err != nil {
+				return nil, false, err
+			}
+
+			entries = append(entries, fileEntries...)
+		default:
+			entries = append(entries, arg)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, false, errors.New("Nothing to launch")
+	}
+
+	return entries, stopOnFailure, nil
+}
+
+// A queue file holds one entry per line, blank lines and lines starting with # are skipped
+func readQueueFile(file string) ([]string, error) {
+	handle, err := os.Open(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer handle.Close()
+
+	entries := make([]string, 0)
+	scanner := bufio.NewScanner(handle)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line != "" && !strings.HasPrefix(line, "#") {
+			entries = append(entries, line)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// Entries are split like a shell would and their flags checked before the first launch, a typo must not
+// surface hours into the queue
+func prepareQueue(entries []string) ([]*queueLaunch, error) {
+	launches := make([]*queueLaunch, 0, len(entries))
+
+	for _, entry := range entries {
+		words, err := wrappers.SplitShellWords(entry)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", entry, err)
+		}
+
+		if err := config.CheckArgvFlags(append([]string{config.APP_NAME}, words...)); err != nil {
+			return nil, fmt.Errorf("%s: %s", entry, err)
+		}
+
+		launches = append(launches, &queueLaunch{Entry: entry, args: words})
+	}
+
+	return launches, nil
+}
+
+// Runs plauncher run with the arguments of launch, environment added to its own, recording how it ended
+func runQueueLaunch(self string, launch *queueLaunch, environment []string) {
+	cmdHandle := exec.Command(self, append([]string{"run"}, launch.args...)...)
+	cmdHandle.Env = append(os.Environ(), environment...)
+	cmdHandle.Stdin = os.Stdin
+	cmdHandle.Stderr = os.Stderr
+
+	if !jsonOutput {
+		cmdHandle.Stdout = os.Stdout
+	}
+
+	launch.StartedAt = time.Now()
+	err := system.Exec.Run(cmdHandle)
+	launch.Seconds = time.Since(launch.StartedAt).Round(time.Second).Seconds()
+
+	var exitErr *exec.ExitError
+
+	switch {
+	case errors.As(err, &exitErr):
+		launch.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		launch.ExitCode = system.EXIT_FAILURE
+		launch.Error = err.Error()
+	}
+}
+
+func printQueueReport(launches []*queueLaunch) {
+	fmt.Printf("%-6s %9s  %s\n", "exit", "time", "entry")
+
+	for _, launch := range launches {
+		if launch.Skipped {
+			fmt.Printf("%-6s %9s  %s\n", "-", "skipped", launch.Entry)
+			continue
+		}
+
+		fmt.Printf("%-6d %9s  %s\n", launch.ExitCode, time.Duration(launch.Seconds)*time.Second, launch.Entry)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/recipes"
+	"github.com/fpetros1/linux-game-launcher/pkg/steam"
+)
+
+func recipesCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s recipes update [url]\n       %s recipes show <game>\n", config.APP_NAME, config.APP_NAME)
+		os.Exit(1)
+	}
+
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	configuration, _ := config.LoadConfiguration(paths)
+
+	switch args[0] {
+	case "update":
+		url := configuration.Recipes.Url
+
+		if len(args) > 1 {
+			url = args[1]
+		}
+
+		if url == "" {
+			url = config.DEFAULT_RECIPES_URL
+		}
+
+		config.MakeSureFoldersExist(paths.AppDataFolder)
+		fetched, err := recipes.Fetch(url, paths.FetchedRecipesFile)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to update recipes: %s\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(map[string]any{"url": url, "file": paths.FetchedRecipesFile, "recipes": len(fetched)})
+			return
+		}
+
+		fmt.Printf("%d recipes from %s saved in %s\n", len(fetched), url, paths.FetchedRecipesFile)
+	case "show":
+		if len(args) < 2 {
+			fmt.Fprintf(os.Stderr, "Usage: %s recipes show <game>\n", config.APP_NAME)
+			os.Exit(1)
+		}
+
+		_, id := gameNameAndId(steam.NameCacheFolder(paths.AppNamesCacheFolder, configuration.Metadata), args[1])
+		recipe, exists := recipes.FindRecipe(id, recipes.RecipeFiles(paths)...)
+
+		if !exists {
+			fmt.Fprintf(os.Stderr, "No recipe for %s\n", args[1])
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(recipe)
+			return
+		}
+
+		fmt.Printf("%s (%s): %s\n", recipe.Name, recipe.Id, recipe.Notes)
+
+		for key, value := range recipe.Environment {
+			fmt.Printf("environment: %s=%s\n", key, value)
+		}
+
+		if len(recipe.Winetricks) > 0 {
+			fmt.Printf("winetricks: %s\n", strings.Join(recipe.Winetricks, " "))
+		}
+
+		if len(recipe.LaunchArgs) > 0 {
+			fmt.Printf("launch args: %s\n", strings.Join(recipe.LaunchArgs, " "))
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown recipes command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/launcher"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Launches the game command of args, the flags before it included, args[0] being the program name
+func runCommand(args []string) {
+	if config.HasArgvFlag(args, config.HELP_FLAG) {
+		config.PrintLaunchUsage(os.Stdout, subcommandNames)
+		return
+	}
+
+	if len(args) == 1 {
+		config.PrintLaunchUsage(os.Stderr, subcommandNames)
+		os.Exit(system.EXIT_CONFIG_ERROR)
+	}
+
+	// Steam hides the debug log, flag mistakes are shown right away
+	if flagsErr := config.CheckArgvFlags(args); flagsErr != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s, see %s --help\n", config.APP_NAME, flagsErr, config.APP_NAME)
+		os.Exit(system.EXIT_CONFIG_ERROR)
+	}
+
+	paths, pathsErr := config.ResolvePaths()
+
+	if pathsErr != nil {
+		system.Fatalf(system.EXIT_FAILURE, "%s\n", pathsErr)
+	}
+
+	simulate := config.HasArgvFlag(args, "simulate")
+	dryRun := config.HasArgvFlag(args, config.DRY_RUN_FLAG)
+	printConfig := config.HasArgvFlag(args, config.PRINT_CONFIG_FLAG)
+
+	if simulate {
+		system.Simulate(os.Stdout)
+		log.SetOutput(os.Stderr)
+	} else {
+		// Created for real before any simulation, dry runs log to the debug file too
+		config.MakeSureFoldersExist(paths.AppStateFolder)
+
+		// A dry run goes through the simulation with its descriptions dropped, only the outcome is printed
+		if dryRun || printConfig {
+			system.SimulateChanges(io.Discard)
+		}
+
+		config.MigrateLegacyStateFiles(paths)
+
+		if _, err := os.Stat(paths.DebugFile); os.IsNotExist(err) {
+			os.WriteFile(paths.DebugFile, []byte(""), config.DEFAULT_PERMISSION)
+		}
+
+		debugFileHandle, debugFileErr := os.OpenFile(paths.DebugFile, os.O_APPEND|os.O_RDWR|os.O_CREATE, config.DEFAULT_PERMISSION)
+
+		// Losing the debug log is no reason not to play, it goes to stderr instead
+		if debugFileErr != nil {
+			fmt.Fprintf(os.Stderr, "plauncher: warning: failed to open the debug file, logging to stderr: %s\n", debugFileErr)
+		} else {
+			defer debugFileHandle.Close()
+			log.SetOutput(debugFileHandle)
+		}
+	}
+
+	log.Printf("---------------------- START PID: %d ----------------------\n", os.Getpid())
+	log.Printf("Version: %s\n", versionString())
+
+	log.Printf("Using app names cache folder: %s\n", paths.AppNamesCacheFolder)
+	log.Printf("Using scripts folder: %s\n", paths.ScriptsFolder)
+	log.Printf("Using game overrides folder: %s\n", paths.OverridesFolder)
+
+	log.Printf("Writing to debug file: %s\n", paths.DebugFile)
+	log.Printf("Using configuration file: %s\n", paths.ConfigurationFile)
+
+	config.MakeSureFoldersExist(
+		paths.AppNamesCacheFolder,
+		paths.LaunchesFolder,
+		paths.ScriptsFolder,
+		paths.OverridesFolder,
+	)
+
+	wrappers.DetectBinaries(wrappers.KNOWN_BINARIES...)
+
+	timings := launcher.NewPhaseTimings()
+	userConfiguration, gameArgs, resolveErr := launcher.Resolve(paths, args, timings)
+
+	if resolveErr != nil {
+		if printConfig {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", config.APP_NAME, resolveErr)
+		}
+
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "%s\n", resolveErr)
+	}
+
+	if printConfig {
+		printConfiguration(userConfiguration, userConfiguration.Props[config.PRINT_CONFIG_FLAG] == "json")
+		log.Printf("---------------------- END PID: %d ----------------------\n", os.Getpid())
+		return
+	}
+
+	if userConfiguration.Tv.Enabled {
+		system.OnFatal = launcher.ShowTvError
+	}
+
+	if config.IsPassthrough(userConfiguration) && dryRun {
+		launcher.PrintDryRun(os.Stdout, gameArgs, nil)
+		log.Printf("---------------------- END PID: %d ----------------------\n", os.Getpid())
+		return
+	}
+
+	if config.IsPassthrough(userConfiguration) {
+		passthroughErr := launcher.Passthrough(gameArgs)
+
+		log.Printf("---------------------- END PID: %d ----------------------\n", os.Getpid())
+
+		if passthroughErr != nil {
+			os.Exit(system.GameExitCode(passthroughErr))
+		}
+
+		return
+	}
+
+	if userConfiguration.HomeShortcuts.Config {
+		config.CreateHomeShortcut(paths.HomeDir, config.CONFIG_SHORTCUT_NAME, paths.AppConfigFolder)
+	}
+
+	eventStream := launcher.OpenEventStream(userConfiguration)
+	defer eventStream.Close()
+
+	eventStream.Emit(launcher.EVENT_CONFIG_RESOLVED, map[string]any{
+		"name": userConfiguration.Props["name"],
+		"id":   userConfiguration.Props["id"],
+	})
+
+	launcher.PreparePrefix(userConfiguration, paths, gameArgs, timings)
+	launcher.ExpandPlaceholders(&userConfiguration, paths)
+
+	eventStream.Emit(launcher.EVENT_PREFIX_READY, map[string]any{
+		"compat-data": userConfiguration.Environment["STEAM_COMPAT_DATA_PATH"],
+	})
+
+	if prepareErr := launcher.RunPrepareSteps(userConfiguration, paths, timings); prepareErr != nil {
+		system.Fatalf(system.EXIT_FAILURE, "%s\n", prepareErr)
+	}
+
+	if problem := launcher.CheckHealth(userConfiguration, paths, gameArgs, timings); problem != nil {
+		launcher.ReportHealthProblem(problem)
+		system.Fatalf(problem.Code, "%s\n", problem)
+	}
+
+	doneWrappers := timings.Track(launcher.PHASE_WRAPPERS)
+	command := wrappers.BuildCommand(&userConfiguration, paths, gameArgs)
+	doneWrappers()
+
+	launcher.LinkPrefix(userConfiguration, paths)
+	command = launcher.ApplyInstance(&userConfiguration, command)
+
+	log.Printf("Launch phases: %s\n", timings.Summary())
+	protonLogFile := launcher.EnrichEnvironmentWithDebug(&userConfiguration, paths)
+	launcher.EnrichEnvironmentWithWineDebug(&userConfiguration)
+	launcher.EnrichEnvironmentWithAudio(&userConfiguration)
+	config.ApplyLaunchEnvironment(&userConfiguration)
+
+	finalConfigurationYaml, _ := yaml.Marshal(userConfiguration)
+
+	log.Printf("Final configuration: \n%s\n", finalConfigurationYaml)
+
+	newEnviron := launcher.BuildEnvironment(userConfiguration)
+
+	if dryRun {
+		launcher.PrintDryRun(os.Stdout, command, userConfiguration.Environment)
+		log.Printf("---------------------- END PID: %d ----------------------\n", os.Getpid())
+		return
+	}
+
+	if simulate {
+		for key, value := range userConfiguration.Environment {
+			fmt.Printf("%s env: %s=%s\n", system.SIMULATE_PREFIX, key, os.ExpandEnv(value))
+		}
+	}
+
+	launcher.SaveLaunchRecord(userConfiguration, command, newEnviron, version, paths.LaunchesFolder)
+
+	newEnviron, secretsErr := launcher.ResolveSecrets(userConfiguration, paths, newEnviron)
+
+	if secretsErr != nil {
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "%s\n", secretsErr)
+	}
+
+	startedAt := time.Now()
+	executeErr := launcher.Execute(userConfiguration, paths, command, newEnviron, eventStream)
+
+	launcher.UpdatePrefixManifest(userConfiguration, paths)
+	launcher.MirrorPrefix(userConfiguration, paths)
+
+	if protonLogFile != "" {
+		log.Printf("Proton log: %s\n", protonLogFile)
+	}
+
+	launcher.ReportSessionSummary(userConfiguration, launcher.BuildSessionSummary(userConfiguration, paths, startedAt, executeErr))
+
+	log.Printf("---------------------- END PID: %d ----------------------\n", os.Getpid())
+
+	if executeErr != nil {
+		exitCode := system.GameExitCode(executeErr)
+
+		if userConfiguration.Tv.Enabled && exitCode == system.EXIT_GAME_START_FAILED {
+			launcher.ShowTvError(fmt.Sprintf("%s could not start: %s", userConfiguration.Props["name"], executeErr))
+		}
+
+		os.Exit(exitCode)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/steam"
+)
+
+// auditedGame is an installed Steam game, or a managed prefix left by one no longer installed, with what
+// plauncher knows of it.
+type auditedGame struct {
+	Appid         string            `json:"appid"`
+	Name          string            `json:"name"`
+	Installed     bool              `json:"installed"`
+	Launcher      bool              `json:"launcher"`
+	LaunchOptions map[string]string `json:"launch-options"`
+	Overrides     []string          `json:"overrides"`
+	Prefix        string            `json:"prefix"`
+	Issues        []string          `json:"issues"`
+}
+
+func steamCommand(args []string) {
+	if len(args) < 1 || !slices.Contains([]string{"set-launch-options", "audit"}, args[0]) {
+		fmt.Fprintf(os.Stderr, "Usage: %s steam set-launch-options <appid>...|--all [--remove] [--dry-run]\n       %s steam audit\n", config.APP_NAME, config.APP_NAME)
+		os.Exit(1)
+	}
+
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if args[0] == "audit" {
+		steamAudit(paths)
+		return
+	}
+
+	appids := make([]string, 0)
+	all, remove, dryRun := false, false, false
+
+	for _, arg := range args[1:] {
+		switch {
+		case arg == "--all":
+			all = true
+		case arg == "--remove":
+			remove = true
+		case arg == "--dry-run":
+			dryRun = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "Unknown argument: %s\n", arg)
+			os.Exit(1)
+		default:
+			appids = append(appids, arg)
+		}
+	}
+
+	if !all && len(appids) == 0 {
+		fmt.Fprintf(os.Stderr, "Give the appids to change, or --all for every installed game\n")
+		os.Exit(1)
+	}
+
+	changes, err := steam.SetLaunchOptions(paths.HomeDir, appids, all, remove, dryRun)
+
+	if jsonOutput {
+		printJSON(changes)
+	} else {
+		for _, change := range changes {
+			fmt.Printf("%s\t%s\t%q -> %q\n", change.User, change.Appid, change.Before, change.After)
+		}
+
+		if len(changes) == 0 && err == nil {
+			fmt.Println("Launch options already up to date")
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Lists the installed games with their launch options, overrides and managed prefix, and what does not add up
+func steamAudit(paths config.Paths) {
+	launchOptions, err := steam.ReadLaunchOptions(paths.HomeDir)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	users := steam.Users(paths.HomeDir)
+	managedPrefixes := make([]prefix.ManagedPrefix, 0)
+
+	for _, base := range config.CompatDataBases(paths) {
+		basePrefixes, _ := prefix.ListManagedPrefixes(base)
+		managedPrefixes = append(managedPrefixes, basePrefixes...)
+	}
+
+	audited := make([]auditedGame, 0)
+
+	for _, app := range steam.InstalledApps(paths.HomeDir) {
+		game := auditedGame{app.Id, app.Name, true, false, make(map[string]string), config.FindOverrideFiles(paths, app.Id, app.Name), "", make([]string, 0)}
+		withLauncher := 0
+
+		for _, user := range users {
+			options := launchOptions[app.Id][user]
+			game.LaunchOptions[user] = options
+
+			if steam.HasLauncher(options) {
+				withLauncher++
+			}
+		}
+
+		game.Launcher = len(users) > 0 && withLauncher == len(users)
+
+		for i, managedPrefix := range managedPrefixes {
+			if managedPrefix.Manifest.Id == app.Id {
+				game.Prefix = managedPrefix.Folder
+				managedPrefixes = slices.Delete(managedPrefixes, i, i+1)
+				break
+			}
+		}
+
+		if withLauncher > 0 && !game.Launcher {
+			game.Issues = append(game.Issues, fmt.Sprintf("plauncher is in the launch options of %d of %d Steam users", withLauncher, len(users)))
+		}
+
+		if withLauncher == 0 && len(game.Overrides) > 0 {
+			game.Issues = append(game.Issues, "has overrides but is not launched through plauncher")
+		}
+
+		if withLauncher == 0 && game.Prefix != "" {
+			game.Issues = append(game.Issues, "has a managed prefix but is not launched through plauncher")
+		}
+
+		audited = append(audited, game)
+	}
+
+	// Prefixes left are of games no longer installed, or of games outside Steam
+	for _, managedPrefix := range managedPrefixes {
+		if managedPrefix.Manifest.SteamCompatData == "" {
+			continue
+		}
+
+		game := auditedGame{managedPrefix.Manifest.Id, managedPrefix.Manifest.Name, false, false, make(map[string]string), make([]string, 0), managedPrefix.Folder, make([]string, 0)}
+		game.Issues = append(game.Issues, "the game is no longer installed, see plauncher prefix prune")
+		audited = append(audited, game)
+	}
+
+	if jsonOutput {
+		printJSON(audited)
+		return
+	}
+
+	issues := 0
+
+	for _, game := range audited {
+		launcher := "no"
+
+		if game.Launcher {
+			launcher = "yes"
+		}
+
+		managedPrefix := game.Prefix
+
+		if managedPrefix == "" {
+			managedPrefix = "-"
+		}
+
+		fmt.Printf("%s\t%s\tplauncher: %s\toverrides: %d\tprefix: %s\n", game.Appid, game.Name, launcher, len(game.Overrides), managedPrefix)
+
+		for _, issue := range game.Issues {
+			fmt.Printf("\t! %s\n", issue)
+			issues++
+		}
+	}
+
+	fmt.Printf("%d games, %d issues\n", len(audited), issues)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+)
+
+// Populated at build time through -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=..."
+var version = "dev"
+var commit = "unknown"
+var buildDate = "unknown"
+
+func versionString() string {
+	return fmt.Sprintf("%s %s (commit: %s, built: %s, %s %s/%s)", config.APP_NAME, version, commit, buildDate, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+func printVersion() {
+	if jsonOutput {
+		printJSON(map[string]string{
+			"name":       config.APP_NAME,
+			"version":    version,
+			"commit":     commit,
+			"build-date": buildDate,
+			"go":         runtime.Version(),
+			"platform":   runtime.GOOS + "/" + runtime.GOARCH,
+		})
+		return
+	}
+
+	fmt.Println(versionString())
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/launcher"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// wakeEvent is one line of plauncher wake --json, which keeps printing them while it runs
+type wakeEvent struct {
+	Event      string       `json:"event"`
+	Combo      []string     `json:"combo,omitempty"`
+	Controller string       `json:"controller,omitempty"`
+	UdpPort    int          `json:"udp-port,omitempty"`
+	Entry      string       `json:"entry,omitempty"`
+	Source     string       `json:"source,omitempty"`
+	Launch     *queueLaunch `json:"launch,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// Waits for the controller combo or magic packet of the wake section and launches its game every time one comes,
+// meant to run as a user service on console-like machines
+func wakeCommand(args []string) {
+	for _, arg := range args {
+		fmt.Fprintf(os.Stderr, "Unknown argument: %s\n", arg)
+		os.Exit(system.EXIT_CONFIG_ERROR)
+	}
+
+	paths, err := config.ResolvePaths()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	configuration, _ := config.LoadConfiguration(paths)
+	launch, combo, err := prepareWake(paths, configuration.Wake)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(system.EXIT_CONFIG_ERROR)
+	}
+
+	self, err := os.Executable()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find the plauncher executable: %s\n", err)
+		os.Exit(1)
+	}
+
+	// Triggers arriving while the game runs are dropped, holding the combo in game must not queue another launch
+	triggers := make(chan string)
+
+	trigger := func(source string) {
+		select {
+		case triggers <- source:
+		default:
+		}
+	}
+
+	watchers := &wakeWatchers{}
+
+	if err := watchers.start(configuration.Wake, combo, launch.Entry, trigger); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	// Edits of the configuration, profiles and overrides apply without restarting the service, on SIGHUP or once
+	// they are saved. Launches read them again anyway, a reload picks up the wake section.
+	reloads := make(chan struct{})
+	go config.WatchConfiguration(paths, reloads, nil)
+
+	for {
+		select {
+		case source := <-triggers:
+			if jsonOutput {
+				printJSONLine(wakeEvent{Event: "woken", Entry: launch.Entry, Source: source})
+			} else {
+				fmt.Printf("Woken up by %s, launching %s\n", source, launch.Entry)
+			}
+
+			runQueueLaunch(self, launch, nil)
+
+			if jsonOutput {
+				printJSONLine(wakeEvent{Event: "exited", Entry: launch.Entry, Launch: launch})
+			} else if launch.Error != "" {
+				fmt.Fprintf(os.Stderr, "Failed to launch %s: %s\n", launch.Entry, launch.Error)
+			} else {
+				fmt.Printf("%s exited with %d\n", launch.Entry, launch.ExitCode)
+			}
+		case <-reloads:
+			reloaded, reloadedLaunch, reloadedCombo, err := reloadWake(paths)
+
+			if err == nil && !reflect.DeepEqual(reloaded.Wake, configuration.Wake) {
+				err = watchers.start(reloaded.Wake, reloadedCombo, reloadedLaunch.Entry, trigger)
+			}
+
+			if jsonOutput {
+				event := wakeEvent{Event: "reloaded"}
+
+				if err != nil {
+					event = wakeEvent{Event: "reload-failed", Error: err.Error()}
+				}
+
+				printJSONLine(event)
+			} else if err != nil {
+				fmt.Fprintf(os.Stderr, "Keeping the previous configuration: %s\n", err)
+			} else {
+				fmt.Println("Configuration reloaded")
+			}
+
+			if err == nil {
+				configuration, launch, combo = reloaded, reloadedLaunch, reloadedCombo
+			}
+		}
+	}
+}
+
+// wakeWatchers are the controller combo watcher and magic packet listener of plauncher wake
+type wakeWatchers struct {
+	udpPort        int
+	stopController chan struct{}
+	stopListener   chan struct{}
+	released       func()
+}
+
+// Watches for the controller combo and magic packets of wake in place of the current ones. A new UDP port is bound
+// before the previous one is released, when it can not be the current watchers are left running.
+func (watchers *wakeWatchers) start(wake config.WakeConfiguration, combo []uint16, entry string, trigger func(string)) error {
+	if wake.UdpPort != watchers.udpPort {
+		stopListener := make(chan struct{})
+		released := func() {}
+
+		if wake.UdpPort > 0 {
+			var err error
+
+			if released, err = launcher.ListenMagicPacket(wake.UdpPort, trigger, stopListener); err != nil {
+				return fmt.Errorf("Failed to listen for magic packets on UDP port %d: %s", wake.UdpPort, err)
+			}
+		}
+
+		if watchers.stopListener != nil {
+			close(watchers.stopListener)
+			watchers.released()
+		}
+
+		watchers.udpPort, watchers.stopListener, watchers.released = wake.UdpPort, stopListener, released
+	}
+
+	if watchers.stopController != nil {
+		close(watchers.stopController)
+	}
+
+	watchers.stopController = make(chan struct{})
+	go launcher.WatchControllerCombo(combo, wake.Controller, trigger, watchers.stopController)
+
+	if jsonOutput {
+		printJSONLine(wakeEvent{Event: "waiting", Combo: wake.Combo, Controller: wake.Controller, UdpPort: wake.UdpPort, Entry: entry})
+		return nil
+	}
+
+	fmt.Printf("Waiting for %v on %s", wake.Combo, wakeControllerName(wake.Controller))
+
+	if wake.UdpPort > 0 {
+		fmt.Printf(" or a magic packet on UDP port %d", wake.UdpPort)
+	}
+
+	fmt.Printf(" to launch %s\n", entry)
+
+	return nil
+}
+
+// Reads the configuration again, plauncher wake keeps running with the previous one when it became invalid
+func reloadWake(paths config.Paths) (config.Configuration, *queueLaunch, []uint16, error) {
+	configuration, err := config.ReloadConfiguration(paths)
+
+	if err != nil {
+		return configuration, nil, nil, err
+	}
+
+	launch, combo, err := prepareWake(paths, configuration.Wake)
+
+	return configuration, launch, combo, err
+}
+
+// The wake section is checked before waiting, a mistake must surface when the service starts, not when the
+// couch is reached
+func prepareWake(paths config.Paths, wake config.WakeConfiguration) (*queueLaunch, []uint16, error) {
+	if wake.Command == "" {
+		return nil, nil, fmt.Errorf("Nothing to launch, set wake.command in %s", paths.ConfigurationFile)
+	}
+
+	words, err := wrappers.SplitShellWords(wake.Command)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("wake.command: %s", err)
+	}
+
+	if wake.Profile != "" {
+		if !slices.Contains(config.ListProfiles(paths), wake.Profile) {
+			return nil, nil, fmt.Errorf("wake.profile: no profile named %s", wake.Profile)
+		}
+
+		words = append([]string{"--" + config.PROFILE_FLAG + "=" + wake.Profile}, words...)
+	}
+
+	if err := config.CheckArgvFlags(append([]string{config.APP_NAME}, words...)); err != nil {
+		return nil, nil, fmt.Errorf("wake.command: %s", err)
+	}
+
+	combo, err := launcher.ParseButtonCombo(wake.Combo)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("wake.combo: %s", err)
+	}
+
+	return &queueLaunch{Entry: wake.Command, args: words}, combo, nil
+}
+
+func wakeControllerName(selector string) string {
+	if selector == "" {
+		return "any controller"
+	}
+
+	return selector
+}
@@ -2,4 +2,7 @@ module github.com/fpetros1/linux-game-launcher
 
 go 1.23.0
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
+require (
+	golang.org/x/text v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
+)
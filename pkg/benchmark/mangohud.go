@@ -0,0 +1,121 @@
+// Package benchmark collects frame time logs with MangoHud and summarizes them.
+package benchmark
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result summarizes a MangoHud frame log.
+type Result struct {
+	File               string  `json:"file"`
+	Samples            int     `json:"samples"`
+	AverageFps         float64 `json:"average-fps"`
+	OnePercentLowFps   float64 `json:"one-percent-low-fps"`
+	AverageFrametimeMs float64 `json:"average-frametime-ms"`
+}
+
+// MangohudConfig returns the MANGOHUD_CONFIG logging duration seconds of frames to outputFolder, warmup seconds
+// after the game starts. The MangoHud configuration file is still read.
+func MangohudConfig(outputFolder string, warmup time.Duration, duration time.Duration) string {
+	return fmt.Sprintf("read_cfg,output_folder=%s,autostart_log=%d,log_duration=%d", outputFolder, int(warmup.Seconds()), int(duration.Seconds()))
+}
+
+// LatestLog returns the newest frame log MangoHud wrote in folder, leaving out its summaries.
+func LatestLog(folder string) (string, error) {
+	logs, _ := filepath.Glob(filepath.Join(folder, "*.csv"))
+	latest := ""
+	var latestTime time.Time
+
+	for _, candidate := range logs {
+		info, err := os.Stat(candidate)
+
+		if err != nil || strings.HasSuffix(candidate, "_summary.csv") {
+			continue
+		}
+
+		if latest == "" || info.ModTime().After(latestTime) {
+			latest, latestTime = candidate, info.ModTime()
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("MangoHud wrote no frame log in %s", folder)
+	}
+
+	return latest, nil
+}
+
+// ParseMangohudLog summarizes the frame log in file. The 1% low is the average frame rate of the slowest 1% of frames.
+func ParseMangohudLog(file string) (Result, error) {
+	logFile, err := os.Open(file)
+
+	if err != nil {
+		return Result{}, err
+	}
+
+	defer logFile.Close()
+
+	frametimes := make([]float64, 0)
+	frametimeColumn := -1
+	scanner := bufio.NewScanner(logFile)
+
+	// System information comes first, then the header of the frame columns, starting with fps
+	for scanner.Scan() {
+		columns := strings.Split(strings.TrimSpace(scanner.Text()), ",")
+
+		if frametimeColumn < 0 {
+			if columns[0] == "fps" {
+				frametimeColumn = slices.Index(columns, "frametime")
+			}
+
+			continue
+		}
+
+		if frametimeColumn >= len(columns) {
+			continue
+		}
+
+		if frametime, err := strconv.ParseFloat(columns[frametimeColumn], 64); err == nil && frametime > 0 {
+			frametimes = append(frametimes, frametime)
+		}
+	}
+
+	if len(frametimes) == 0 {
+		return Result{}, errors.New("No frames in " + file)
+	}
+
+	total := 0.0
+
+	for _, frametime := range frametimes {
+		total += frametime
+	}
+
+	// Slowest first
+	slices.Sort(frametimes)
+	slices.Reverse(frametimes)
+	slowest := frametimes[:int(math.Ceil(float64(len(frametimes))/100))]
+	slowestTotal := 0.0
+
+	for _, frametime := range slowest {
+		slowestTotal += frametime
+	}
+
+	averageFrametime := total / float64(len(frametimes))
+
+	return Result{
+		File:               file,
+		Samples:            len(frametimes),
+		AverageFps:         1000 / averageFrametime,
+		OnePercentLowFps:   1000 / (slowestTotal / float64(len(slowest))),
+		AverageFrametimeMs: averageFrametime,
+	}, nil
+}
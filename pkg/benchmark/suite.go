@@ -0,0 +1,142 @@
+package benchmark
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const SUITE_DEFAULT_DURATION = 60 * time.Second
+const SUITE_DEFAULT_WARMUP = 10 * time.Second
+
+// Suite is a benchmark suite file: games, each run through plauncher with an optional profile and settings, logged
+// with MangoHud for Duration after Warmup. The durations of a run default to the ones of the suite.
+type Suite struct {
+	Duration string     `yaml:"duration"`
+	Warmup   string     `yaml:"warmup"`
+	Runs     []SuiteRun `yaml:"runs"`
+}
+
+// SuiteRun is one game of a suite, Command being the launch flags and game command as given to plauncher run.
+type SuiteRun struct {
+	Name     string   `yaml:"name"`
+	Command  string   `yaml:"command"`
+	Profile  string   `yaml:"profile"`
+	Set      []string `yaml:"set"`
+	Duration string   `yaml:"duration"`
+	Warmup   string   `yaml:"warmup"`
+}
+
+// SuiteResult is the outcome of one run of a suite, Result being unset when MangoHud logged no frames.
+type SuiteResult struct {
+	Name     string  `json:"name"`
+	Profile  string  `json:"profile,omitempty"`
+	Duration float64 `json:"duration-seconds"`
+	ExitCode int     `json:"exit-code"`
+	Result   *Result `json:"result,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// LoadSuite reads the suite file, checking every run has a command and valid durations.
+func LoadSuite(file string) (Suite, error) {
+	suite := Suite{}
+	content, err := os.ReadFile(file)
+
+	if err != nil {
+		return suite, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&suite); err != nil && !errors.Is(err, io.EOF) {
+		return suite, fmt.Errorf("%s: %w", file, err)
+	}
+
+	if len(suite.Runs) == 0 {
+		return suite, fmt.Errorf("%s has no runs", file)
+	}
+
+	for index, run := range suite.Runs {
+		if run.Command == "" {
+			return suite, fmt.Errorf("Run %d of %s has no command", index+1, file)
+		}
+
+		if run.Name == "" {
+			suite.Runs[index].Name = run.Command
+		}
+
+		if _, _, err := suite.Timing(run); err != nil {
+			return suite, fmt.Errorf("%s: %w", suite.Runs[index].Name, err)
+		}
+	}
+
+	return suite, nil
+}
+
+// Timing returns the warmup and duration of run, the ones of the suite or the defaults when it sets none.
+func (suite Suite) Timing(run SuiteRun) (time.Duration, time.Duration, error) {
+	warmup, err := parseSuiteDuration("warmup", SUITE_DEFAULT_WARMUP, run.Warmup, suite.Warmup)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	duration, err := parseSuiteDuration("duration", SUITE_DEFAULT_DURATION, run.Duration, suite.Duration)
+
+	if err == nil && duration <= 0 {
+		err = fmt.Errorf("Invalid duration %s, a run needs frames to log", duration)
+	}
+
+	return warmup, duration, err
+}
+
+// WriteSuiteCSV writes one line per result, the runs without frames having empty measures.
+func WriteSuiteCSV(out io.Writer, results []SuiteResult) error {
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"name", "profile", "duration-seconds", "exit-code", "samples", "average-fps", "one-percent-low-fps", "average-frametime-ms", "log", "error"})
+
+	for _, result := range results {
+		line := []string{result.Name, result.Profile, strconv.FormatFloat(result.Duration, 'f', 0, 64), strconv.Itoa(result.ExitCode), "", "", "", "", "", result.Error}
+
+		if result.Result != nil {
+			line[4] = strconv.Itoa(result.Result.Samples)
+			line[5] = strconv.FormatFloat(result.Result.AverageFps, 'f', 1, 64)
+			line[6] = strconv.FormatFloat(result.Result.OnePercentLowFps, 'f', 1, 64)
+			line[7] = strconv.FormatFloat(result.Result.AverageFrametimeMs, 'f', 2, 64)
+			line[8] = result.Result.File
+		}
+
+		writer.Write(line)
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// The first of values that is set, parsed as a duration, fallback when none is
+func parseSuiteDuration(key string, fallback time.Duration, values ...string) (time.Duration, error) {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(value)
+
+		if err != nil || duration < 0 {
+			return 0, fmt.Errorf("Invalid %s %s, expected a duration like 90s", key, value)
+		}
+
+		return duration, nil
+	}
+
+	return fallback, nil
+}
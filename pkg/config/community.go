@@ -0,0 +1,279 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const GIT_BIN_NAME = "git"
+const OVERRIDE_SOURCE_GIT = "git"
+const OVERRIDE_SOURCE_FILE = "file"
+const OVERRIDE_SOURCES_FILENAME = "sources.yaml"
+
+// OverrideSource is a community override collection fetched into its own read-only layer, either a git
+// repository (holding override files at its root or in overrides/) or a single override file.
+// Pinned sources are left alone by updates, Pin is the commit or tag checked out for git.
+type OverrideSource struct {
+	Name string `yaml:"name" json:"name"`
+	Url  string `yaml:"url" json:"url"`
+	Kind string `yaml:"kind" json:"kind"`
+	Pin  string `yaml:"pin,omitempty" json:"pin,omitempty"`
+}
+
+// OverrideFolders returns every game overrides folder, lowest precedence first: system-wide, community then user.
+func OverrideFolders(paths Paths) []string {
+	folders := []string{paths.SystemOverridesFolder}
+	sources, _ := ReadOverrideSources(paths)
+
+	for _, source := range sources {
+		folder := overrideSourceFolder(paths, source)
+
+		if info, err := os.Stat(filepath.Join(folder, "overrides")); err == nil && info.IsDir() {
+			folder = filepath.Join(folder, "overrides")
+		}
+
+		folders = append(folders, folder)
+	}
+
+	return append(folders, paths.OverridesFolder)
+}
+
+// ReadOverrideSources returns the fetched community sources, in the order they were added.
+func ReadOverrideSources(paths Paths) ([]OverrideSource, error) {
+	sources := make([]OverrideSource, 0)
+	content, err := os.ReadFile(filepath.Join(paths.CommunityOverridesFolder, OVERRIDE_SOURCES_FILENAME))
+
+	if os.IsNotExist(err) {
+		return sources, nil
+	}
+
+	if err != nil {
+		return sources, err
+	}
+
+	return sources, yaml.Unmarshal(content, &sources)
+}
+
+// FetchOverrideSource adds the repository or override file at url as a community source and downloads it.
+func FetchOverrideSource(paths Paths, url string) (OverrideSource, error) {
+	sources, err := ReadOverrideSources(paths)
+
+	if err != nil {
+		return OverrideSource{}, err
+	}
+
+	for _, source := range sources {
+		if source.Url == url {
+			return source, syncOverrideSource(paths, source)
+		}
+	}
+
+	source := OverrideSource{overrideSourceName(url, sources), url, OVERRIDE_SOURCE_GIT, ""}
+
+	if strings.HasSuffix(url, ".yaml") || strings.HasSuffix(url, ".yml") {
+		source.Kind = OVERRIDE_SOURCE_FILE
+	}
+
+	if err := syncOverrideSource(paths, source); err != nil {
+		system.FS.RemoveAll(overrideSourceFolder(paths, source))
+		return source, err
+	}
+
+	return source, writeOverrideSources(paths, append(sources, source))
+}
+
+// UpdateOverrideSources downloads the latest version of every source that is not pinned, returning the updated ones.
+func UpdateOverrideSources(paths Paths) ([]OverrideSource, error) {
+	sources, err := ReadOverrideSources(paths)
+	updated := make([]OverrideSource, 0, len(sources))
+
+	if err != nil {
+		return updated, err
+	}
+
+	failures := make([]error, 0)
+
+	for _, source := range sources {
+		if source.Pin != "" {
+			log.Printf("Not updating pinned override source %s\n", source.Name)
+			continue
+		}
+
+		if err := syncOverrideSource(paths, source); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %s", source.Name, err))
+			continue
+		}
+
+		updated = append(updated, source)
+	}
+
+	return updated, errors.Join(failures...)
+}
+
+// PinOverrideSource stops updates of the source called name. Git sources check ref out first,
+// or stay on their current commit when ref is empty.
+func PinOverrideSource(paths Paths, name string, ref string) (OverrideSource, error) {
+	return changeOverrideSource(paths, name, func(source *OverrideSource) error {
+		source.Pin = ref
+
+		if source.Kind != OVERRIDE_SOURCE_GIT {
+			source.Pin = "pinned"
+			return nil
+		}
+
+		if ref != "" {
+			return checkoutOverrideSource(overrideSourceFolder(paths, *source), ref)
+		}
+
+		commit, err := system.Exec.Output(exec.Command(GIT_BIN_NAME, "-C", overrideSourceFolder(paths, *source), "rev-parse", "HEAD"))
+		source.Pin = strings.TrimSpace(string(commit))
+
+		return err
+	})
+}
+
+// UnpinOverrideSource lets updates of the source called name through again, updating it right away.
+func UnpinOverrideSource(paths Paths, name string) (OverrideSource, error) {
+	return changeOverrideSource(paths, name, func(source *OverrideSource) error {
+		source.Pin = ""
+		return syncOverrideSource(paths, *source)
+	})
+}
+
+func changeOverrideSource(paths Paths, name string, change func(source *OverrideSource) error) (OverrideSource, error) {
+	sources, err := ReadOverrideSources(paths)
+
+	if err != nil {
+		return OverrideSource{}, err
+	}
+
+	for i := range sources {
+		if sources[i].Name != name {
+			continue
+		}
+
+		if err := change(&sources[i]); err != nil {
+			return sources[i], err
+		}
+
+		return sources[i], writeOverrideSources(paths, sources)
+	}
+
+	return OverrideSource{}, fmt.Errorf("No override source called %s", name)
+}
+
+func writeOverrideSources(paths Paths, sources []OverrideSource) error {
+	content, err := yaml.Marshal(sources)
+
+	if err != nil {
+		return err
+	}
+
+	return system.FS.WriteFile(filepath.Join(paths.CommunityOverridesFolder, OVERRIDE_SOURCES_FILENAME), content, DEFAULT_PERMISSION)
+}
+
+func overrideSourceFolder(paths Paths, source OverrideSource) string {
+	return filepath.Join(paths.CommunityOverridesFolder, source.Name)
+}
+
+// Names the source after the last part of url, e.g. the repository name, made unique among sources
+func overrideSourceName(url string, sources []OverrideSource) string {
+	base := strings.TrimSuffix(path.Base(strings.TrimRight(url, "/")), ".git")
+	base = strings.TrimSuffix(strings.TrimSuffix(base, ".yaml"), ".yml")
+	name := GameSlug(base)
+
+	for suffix := 2; ; suffix++ {
+		taken := false
+
+		for _, source := range sources {
+			taken = taken || source.Name == name
+		}
+
+		if !taken {
+			return name
+		}
+
+		name = fmt.Sprintf("%s-%d", GameSlug(base), suffix)
+	}
+}
+
+// Downloads the latest version of source into its folder
+func syncOverrideSource(paths Paths, source OverrideSource) error {
+	folder := overrideSourceFolder(paths, source)
+
+	if source.Kind == OVERRIDE_SOURCE_FILE {
+		return downloadOverrideFile(source.Url, folder)
+	}
+
+	if _, err := os.Stat(filepath.Join(folder, ".git")); os.IsNotExist(err) {
+		system.FS.MkdirAll(paths.CommunityOverridesFolder, DEFAULT_PERMISSION)
+
+		// A failed clone leaves a partial folder behind, git refuses to clone into it again
+		return system.Retry(GIT_BIN_NAME+" clone", func() error {
+			system.FS.RemoveAll(folder)
+			return runGit("clone", "--depth", "1", source.Url, folder)
+		})
+	}
+
+	return checkoutOverrideSource(folder, "HEAD")
+}
+
+func checkoutOverrideSource(folder string, ref string) error {
+	err := system.Retry(GIT_BIN_NAME+" fetch", func() error {
+		return runGit("-C", folder, "fetch", "--depth", "1", "origin", ref)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return runGit("-C", folder, "checkout", "--quiet", "--detach", "FETCH_HEAD")
+}
+
+func runGit(args ...string) error {
+	git, exists := system.Exec.LookPath(GIT_BIN_NAME)
+
+	if !exists {
+		return system.Permanent(fmt.Errorf("%s is needed to fetch override repositories", GIT_BIN_NAME))
+	}
+
+	cmdHandle := exec.Command(git, args...)
+	log.Printf("Running: %s\n", cmdHandle)
+
+	if _, err := system.Exec.Output(cmdHandle); err != nil {
+		if exitErr, isExitErr := err.(*exec.ExitError); isExitErr {
+			return fmt.Errorf("%s failed: %s", cmdHandle, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+
+		return fmt.Errorf("%s failed: %s", cmdHandle, err)
+	}
+
+	return nil
+}
+
+// Override files carry the game name or appid as their name, the one of the url is kept
+func downloadOverrideFile(url string, folder string) error {
+	content, err := system.HttpGet(url)
+
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.Unmarshal(content, &map[string]any{}); err != nil {
+		return fmt.Errorf("%s is not a valid override: %s", url, err)
+	}
+
+	system.FS.MkdirAll(folder, DEFAULT_PERMISSION)
+
+	return system.FS.WriteFile(filepath.Join(folder, path.Base(url)), content, DEFAULT_PERMISSION)
+}
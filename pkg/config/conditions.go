@@ -0,0 +1,226 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const GPU_VENDOR_AMD = "amd"
+const GPU_VENDOR_NVIDIA = "nvidia"
+const GPU_VENDOR_INTEL = "intel"
+
+const SESSION_TYPE_WAYLAND = "wayland"
+const SESSION_TYPE_X11 = "x11"
+const SESSION_TYPE_TTY = "tty"
+
+// PCI vendor ids of the GPU makers, as /sys/class/drm lists them
+var gpuVendorIds = map[string]string{
+	"0x1002": GPU_VENDOR_AMD,
+	"0x10de": GPU_VENDOR_NVIDIA,
+	"0x8086": GPU_VENDOR_INTEL,
+}
+
+// ConditionalSection holds configuration keys merged, like an override, only on the machines matching When. The
+// configuration shared across machines through dotfiles keeps the settings of each one in a section.
+type ConditionalSection struct {
+	When          Condition `yaml:"when"`
+	Configuration `yaml:",inline"`
+}
+
+// Condition matches the machine plauncher runs on, every key set having to match. Hostname takes shell patterns
+// (e.g. desk*), GpuVendor matches any GPU of the machine.
+type Condition struct {
+	Hostname  string `yaml:"hostname"`
+	GpuVendor string `yaml:"gpu-vendor"`
+	Session   string `yaml:"session"`
+}
+
+// machineFacts is what conditions are matched against, detected once per run
+type machineFacts struct {
+	hostname   string
+	gpuVendors []string
+	session    string
+}
+
+var detectMachineFacts = sync.OnceValue(func() machineFacts {
+	hostname, _ := os.Hostname()
+	facts := machineFacts{hostname: hostname, gpuVendors: detectGpuVendors(), session: detectSessionType()}
+
+	log.Printf("Matching conditional sections against hostname %s, GPU %s, session %s\n", facts.hostname, strings.Join(facts.gpuVendors, "+"), facts.session)
+
+	return facts
+})
+
+func detectGpuVendors() []string {
+	vendorFiles, _ := filepath.Glob("/sys/class/drm/card[0-9]*/device/vendor")
+	vendors := make([]string, 0)
+
+	for _, vendorFile := range vendorFiles {
+		content, err := os.ReadFile(vendorFile)
+
+		if err != nil {
+			continue
+		}
+
+		if vendor, known := gpuVendorIds[strings.TrimSpace(string(content))]; known && !slices.Contains(vendors, vendor) {
+			vendors = append(vendors, vendor)
+		}
+	}
+
+	return vendors
+}
+
+// The session type of the login, guessed from the displays plauncher can reach when it is not set
+func detectSessionType() string {
+	switch sessionType := os.Getenv("XDG_SESSION_TYPE"); {
+	case sessionType == SESSION_TYPE_WAYLAND || sessionType == SESSION_TYPE_X11 || sessionType == SESSION_TYPE_TTY:
+		return sessionType
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		return SESSION_TYPE_WAYLAND
+	case os.Getenv("DISPLAY") != "":
+		return SESSION_TYPE_X11
+	default:
+		return SESSION_TYPE_TTY
+	}
+}
+
+// Reports whether facts satisfy every key of condition
+func (condition Condition) matches(facts machineFacts) bool {
+	if condition.Hostname != "" {
+		if matched, _ := path.Match(strings.ToLower(condition.Hostname), strings.ToLower(facts.hostname)); !matched {
+			return false
+		}
+	}
+
+	if condition.GpuVendor != "" && !slices.Contains(facts.gpuVendors, strings.ToLower(condition.GpuVendor)) {
+		return false
+	}
+
+	return condition.Session == "" || strings.EqualFold(condition.Session, facts.session)
+}
+
+func (condition Condition) String() string {
+	parts := make([]string, 0, 3)
+
+	for _, part := range [][2]string{{"hostname", condition.Hostname}, {"gpu-vendor", condition.GpuVendor}, {"session", condition.Session}} {
+		if part[1] != "" {
+			parts = append(parts, part[0]+": "+part[1])
+		}
+	}
+
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// Merges the conditional sections of content, read from source, matching this machine on top of configuration,
+// in the order they are written. A section may hold conditional sections of its own.
+func applyConditionalSections(configuration *Configuration, content []byte, source string) {
+	document := yaml.Node{}
+
+	if yaml.Unmarshal(content, &document) != nil || len(document.Content) == 0 {
+		return
+	}
+
+	sections := mappingValue(document.Content[0], "conditional")
+
+	if sections == nil || sections.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for _, section := range sections.Content {
+		condition := Condition{}
+
+		if when := mappingValue(section, "when"); when != nil {
+			when.Decode(&condition)
+		}
+
+		if !condition.matches(detectMachineFacts()) {
+			continue
+		}
+
+		log.Printf("Applying conditional section %s of %s\n", condition, source)
+
+		sectionContent, err := yaml.Marshal(withoutMappingKey(section, "when"))
+
+		if err != nil {
+			continue
+		}
+
+		overlay, keys := overlayConfiguration(*configuration, sectionContent, source)
+		applyOverlay(configuration, overlay, keys, source)
+		applyConditionalSections(configuration, sectionContent, source)
+	}
+}
+
+// A copy of mapping without key
+func withoutMappingKey(mapping *yaml.Node, key string) *yaml.Node {
+	copied := *mapping
+	copied.Content = make([]*yaml.Node, 0, len(mapping.Content))
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			copied.Content = append(copied.Content, mapping.Content[i], mapping.Content[i+1])
+		}
+	}
+
+	return &copied
+}
+
+// Lists the conditional sections of content, read from file, whose condition is missing or can never match
+func conditionProblems(file string, content []byte) []ConfigurationProblem {
+	document := yaml.Node{}
+
+	if yaml.Unmarshal(content, &document) != nil || len(document.Content) == 0 {
+		return nil
+	}
+
+	return sectionProblems(file, document.Content[0])
+}
+
+func sectionProblems(file string, mapping *yaml.Node) []ConfigurationProblem {
+	sections := mappingValue(mapping, "conditional")
+
+	if sections == nil || sections.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	problems := make([]ConfigurationProblem, 0)
+
+	for _, section := range sections.Content {
+		when := mappingValue(section, "when")
+
+		if when == nil {
+			problems = append(problems, ConfigurationProblem{file, section.Line, "conditional section without when, it would apply everywhere"})
+			continue
+		}
+
+		for _, check := range []struct {
+			key     string
+			allowed []string
+		}{
+			{"gpu-vendor", []string{GPU_VENDOR_AMD, GPU_VENDOR_NVIDIA, GPU_VENDOR_INTEL}},
+			{"session", []string{SESSION_TYPE_WAYLAND, SESSION_TYPE_X11, SESSION_TYPE_TTY}},
+		} {
+			if value := mappingValue(when, check.key); value != nil && !slices.Contains(check.allowed, strings.ToLower(value.Value)) {
+				problems = append(problems, ConfigurationProblem{file, value.Line, fmt.Sprintf("when.%s: %s is not one of %s", check.key, value.Value, strings.Join(check.allowed, ", "))})
+			}
+		}
+
+		if hostname := mappingValue(when, "hostname"); hostname != nil {
+			if _, err := path.Match(hostname.Value, ""); err != nil {
+				problems = append(problems, ConfigurationProblem{file, hostname.Line, fmt.Sprintf("when.hostname: %s is not a valid pattern", hostname.Value)})
+			}
+		}
+
+		problems = append(problems, sectionProblems(file, section)...)
+	}
+
+	return problems
+}
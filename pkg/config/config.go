@@ -0,0 +1,919 @@
+// Package config holds the plauncher configuration model, the global/override
+// YAML files it is read from and the argv flags layered on top of it.
+package config
+
+import (
+	"log"
+	"maps"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const DEFAULT_PERMISSION = 0755
+
+// PRIVATE_PERMISSION is DEFAULT_PERMISSION for what holds the environment of a launch, and so its tokens
+const PRIVATE_PERMISSION = 0700
+const PRIVATE_FILE_PERMISSION = 0600
+
+const ENV_HOME = "HOME"
+const ENV_XDG_CONFIG_HOME = "XDG_CONFIG_HOME"
+const ENV_XDG_DATA_HOME = "XDG_DATA_HOME"
+const ENV_XDG_CACHE_HOME = "XDG_CACHE_HOME"
+const ENV_XDG_STATE_HOME = "XDG_STATE_HOME"
+const ENV_XDG_RUNTIME_DIR = "XDG_RUNTIME_DIR"
+
+const APP_NAME = "plauncher"
+
+// Configuration is the effective launch configuration. SpecialFlags and Props
+// come from the command line (and game detection) and are never serialized.
+type Configuration struct {
+	ConfigVersion int                        `yaml:"config-version"`
+	Extends       string                     `yaml:"extends,omitempty"`
+	Include       []string                   `yaml:"include,omitempty"`
+	Conditional   []ConditionalSection       `yaml:"conditional,omitempty"`
+	Environment   map[string]string          `yaml:"environment"`
+	Wine          WineConfiguration          `yaml:"wine"`
+	Mangohud      MangohudConfiguration      `yaml:"mangohud"`
+	Gamemode      GamemodeConfiguration      `yaml:"gamemode"`
+	Gamescope     GamescopeConfiguration     `yaml:"gamescope"`
+	EosOverlay    EosConfiguration           `yaml:"eos-overlay"`
+	Umu           UmuConfiguration           `yaml:"umu"`
+	PreScripts    []string                   `yaml:"pre-scripts"`
+	PostScripts   []string                   `yaml:"post-scripts"`
+	Prepare       []PrepareStep              `yaml:"prepare"`
+	HomeShortcuts HomeShortcutsConfiguration `yaml:"home-shortcuts"`
+	Debug         DebugConfiguration         `yaml:"debug"`
+	CompatData    CompatDataConfiguration    `yaml:"compatdata"`
+	Overrides     OverridesConfiguration     `yaml:"overrides"`
+	OnlyFor       []string                   `yaml:"only-for"`
+	SkipFor       []string                   `yaml:"skip-for"`
+	Passthrough   bool                       `yaml:"passthrough"`
+	Strict        bool                       `yaml:"strict"`
+	Anticheat     AnticheatConfiguration     `yaml:"anticheat"`
+	Dependencies  DependenciesConfiguration  `yaml:"dependencies"`
+	Network       NetworkConfiguration       `yaml:"network"`
+	Display       DisplayConfiguration       `yaml:"display"`
+	Audio         AudioConfiguration         `yaml:"audio"`
+	Tv            TvConfiguration            `yaml:"tv"`
+	Handheld      HandheldConfiguration      `yaml:"handheld"`
+	Health        HealthConfiguration        `yaml:"health"`
+	Gc            GcConfiguration            `yaml:"gc"`
+	Summary       SummaryConfiguration       `yaml:"summary"`
+	Timeouts      TimeoutsConfiguration      `yaml:"timeouts"`
+	Recording     RecordingConfiguration     `yaml:"recording"`
+	Watchdog      WatchdogConfiguration      `yaml:"watchdog"`
+	Metadata      MetadataConfiguration      `yaml:"metadata"`
+	Recipes       RecipesConfiguration       `yaml:"recipes"`
+	Mods          ModsConfiguration          `yaml:"mods"`
+	DllSwaps      []DllSwap                  `yaml:"dll-swaps"`
+	Instances     InstancesConfiguration     `yaml:"instances"`
+	Wake          WakeConfiguration          `yaml:"wake"`
+	Control       ControlConfiguration       `yaml:"control"`
+	Secrets       SecretsConfiguration       `yaml:"secrets"`
+	SpecialFlags  map[string]bool            `yaml:"-"`
+	Props         map[string]string          `yaml:"-"`
+	Provenance    map[string]string          `yaml:"-"`
+	ListMerge     map[string]string          `yaml:"-"`
+	FlagValues    map[string][]string        `yaml:"-"`
+}
+
+// --overlay=FILE merges FILE on top of the game overrides for one launch
+const OVERLAY_PROP = "overlay"
+
+const OVERRIDE_BY_ID = "id"
+const OVERRIDE_BY_NAME = "name"
+
+// OverridesConfiguration orders the game override files, highest precedence first. Both always rank above
+// the global configuration, in each rank user overrides rank above system-wide ones.
+type OverridesConfiguration struct {
+	Precedence []string `yaml:"precedence"`
+}
+
+// HomeShortcutsConfiguration controls the ~/.plauncher symlink and the folder collecting one symlink per
+// game prefix (e.g. ~/Games/prefixes/<name>), both off unless enabled.
+type HomeShortcutsConfiguration struct {
+	Config      bool   `yaml:"config"`
+	PrefixLinks string `yaml:"prefix-links"`
+}
+
+const COMPATDATA_MODE_RELOCATE = "relocate"
+const COMPATDATA_MODE_MIRROR = "mirror"
+
+// CompatDataConfiguration selects what happens to Steam compat data: relocate moves it under the
+// plauncher data folder, mirror leaves it in place and syncs a copy there after every session.
+// Base replaces the plauncher data folder for the prefixes, e.g. on another drive.
+type CompatDataConfiguration struct {
+	Mode string `yaml:"mode"`
+	Base string `yaml:"base"`
+}
+
+const ANTICHEAT_ACTION_WARN = "warn"
+const ANTICHEAT_ACTION_PASSTHROUGH = "passthrough"
+const ANTICHEAT_ACTION_IGNORE = "ignore"
+
+// AnticheatConfiguration selects what happens when a game known to break with gamescope or
+// LD_PRELOAD overlays is launched with them: warn, run it in pass-through mode or ignore it.
+type AnticheatConfiguration struct {
+	Action string `yaml:"action"`
+}
+
+const DEPENDENCIES_ACTION_SUGGEST = "suggest"
+const DEPENDENCIES_ACTION_INSTALL = "install"
+const DEPENDENCIES_ACTION_IGNORE = "ignore"
+
+// DependenciesConfiguration scans the game folder for the runtimes it ships installers for (Visual C++, .NET, XNA)
+// on the first launch of a new prefix, and suggests or installs the matching winetricks verbs.
+type DependenciesConfiguration struct {
+	Action string `yaml:"action"`
+}
+
+// NetworkConfiguration limits the game bandwidth to a tc rate (e.g. 5mbit) in both directions and binds its
+// traffic to an interface (e.g. wg0), or runs it in an existing named network namespace instead.
+type NetworkConfiguration struct {
+	Limit     string `yaml:"limit"`
+	Interface string `yaml:"interface"`
+	Namespace string `yaml:"namespace"`
+}
+
+const DISPLAY_VRR_ON = "on"
+const DISPLAY_VRR_OFF = "off"
+const DISPLAY_VRR_GAME_ONLY = "game-only"
+
+// DisplayConfiguration changes the desktop display for the length of the session. Vrr sets adaptive sync
+// (on, off or game-only, for fullscreen windows only), left as is when empty, on Output or every output.
+type DisplayConfiguration struct {
+	SuspendNightLight bool   `yaml:"suspend-night-light"`
+	Vrr               string `yaml:"vrr"`
+	Output            string `yaml:"output"`
+}
+
+// AudioConfiguration routes the game audio on PipeWire or PulseAudio. Sink is the name or description of
+// the output device the game streams are moved to, e.g. "HDMI 1". The other fields fix crackling audio
+// through the game environment, zero leaves the defaults: latency-msec (PulseAudio clients), quantum in
+// frames at rate Hz (PipeWire), wine-period-usec and wine-duration-usec (the Wine audio buffer).
+// Profile switches a sound card to another profile for the session (e.g. output:analog-surround-51) and
+// filter-chain runs a PipeWire filter chain config (e.g. virtual surround) for it, relative to the
+// configuration folder unless absolute.
+type AudioConfiguration struct {
+	Sink             string `yaml:"sink"`
+	LatencyMsec      int    `yaml:"latency-msec"`
+	Quantum          int    `yaml:"quantum"`
+	Rate             int    `yaml:"rate"`
+	WinePeriodUsec   int    `yaml:"wine-period-usec"`
+	WineDurationUsec int    `yaml:"wine-duration-usec"`
+	Card             string `yaml:"card"`
+	Profile          string `yaml:"profile"`
+	FilterChain      string `yaml:"filter-chain"`
+}
+
+const TV_MODE_4K60 = "4k60"
+const TV_MODE_1080P120 = "1080p120"
+
+// TvConfiguration turns the session into a couch setup: Output (e.g. HDMI-A-1, the first HDMI output when empty)
+// becomes the only active output, in Mode (4k60, 1080p120 or WIDTHxHEIGHT@REFRESH), the idle cursor hides and
+// errors show as dialogs instead of only in the logs. The desktop layout is restored after exit.
+type TvConfiguration struct {
+	Enabled bool   `yaml:"enabled"`
+	Output  string `yaml:"output"`
+	Mode    string `yaml:"mode"`
+}
+
+// HandheldConfiguration applies the defaults of a handheld (auto, rog-ally, legion-go or steam-deck) to gamescope,
+// unless in tv mode. Changing the power limit (tdp-watts, the preset default when 0) runs ryzenadj, which needs
+// root, only with allow-tdp.
+type HandheldConfiguration struct {
+	Preset   string `yaml:"preset"`
+	AllowTdp bool   `yaml:"allow-tdp"`
+	TdpWatts int    `yaml:"tdp-watts"`
+}
+
+// TimeoutsConfiguration bounds the external tools plauncher waits on, as durations like 30s or 10m. A tool still
+// running past its timeout is killed with everything it started, 0 waits forever.
+// Probe covers the quick queries of detection, e.g. gamescope --help, pactl or kscreen-doctor.
+type TimeoutsConfiguration struct {
+	Winetricks string `yaml:"winetricks"`
+	Legendary  string `yaml:"legendary"`
+	Probe      string `yaml:"probe"`
+}
+
+// SummaryConfiguration sends the session summary written at the end of the debug log as a desktop notification.
+type SummaryConfiguration struct {
+	Notify bool `yaml:"notify"`
+}
+
+// GcConfiguration holds the retention limits of plauncher gc, a zero limit keeps everything it covers.
+// Name cache entries and backups older than their limit, in days, are removed once their game is gone,
+// session logs beyond the newest SessionLogs or older than SessionLogDays are removed.
+type GcConfiguration struct {
+	NameCacheDays  int  `yaml:"name-cache-days"`
+	SessionLogs    int  `yaml:"session-logs"`
+	SessionLogDays int  `yaml:"session-log-days"`
+	ShaderCaches   bool `yaml:"shader-caches"`
+	BackupDays     int  `yaml:"backup-days"`
+}
+
+// Free space, in MiB, the prefix drive needs for a launch to go ahead
+const DEFAULT_MIN_FREE_MB = 1024
+
+// HealthConfiguration checks the prefix, the game executable and the free space before launching.
+type HealthConfiguration struct {
+	Enabled   bool `yaml:"enabled"`
+	MinFreeMb int  `yaml:"min-free-mb"`
+}
+
+const RECORDING_BACKEND_GPU_SCREEN_RECORDER = "gpu-screen-recorder"
+const RECORDING_BACKEND_OBS = "obs"
+const RECORDING_MODE_REPLAY = "replay"
+const RECORDING_MODE_RECORD = "record"
+
+// RecordingConfiguration starts a replay buffer or a recording with the game and stops it on exit.
+// Args are appended to the gpu-screen-recorder command, or passed to obs-cmd before its command (e.g. -w <url>).
+type RecordingConfiguration struct {
+	Enabled bool     `yaml:"enabled"`
+	Backend string   `yaml:"backend"`
+	Mode    string   `yaml:"mode"`
+	Folder  string   `yaml:"folder"`
+	Args    []string `yaml:"args"`
+}
+
+const WATCHDOG_ACTION_NOTIFY = "notify"
+const WATCHDOG_ACTION_KILL = "kill"
+const WATCHDOG_ACTION_RESTART = "restart"
+
+// WatchdogConfiguration acts on sessions where the game shows no window for Timeout (e.g. 5m), disabled when empty.
+type WatchdogConfiguration struct {
+	Timeout string `yaml:"timeout"`
+	Action  string `yaml:"action"`
+}
+
+const METADATA_RESOLVER_STORE = "store"
+const METADATA_RESOLVER_STEAMSPY = "steamspy"
+const DEFAULT_METADATA_LANGUAGE = "english"
+const METADATA_RENAMES_WARN = "warn"
+const METADATA_RENAMES_MIGRATE = "migrate"
+const METADATA_RENAMES_IGNORE = "ignore"
+
+// MetadataConfiguration lists the services game names are resolved with, in order, and the Steam
+// language (e.g. english, french, schinese) the names are requested in. Providers adds services by URL.
+// Renames is what happens when Steam renamed a game: warn, migrate its override and prefix, or ignore.
+type MetadataConfiguration struct {
+	Resolvers []string                        `yaml:"resolvers"`
+	Language  string                          `yaml:"language"`
+	Providers []MetadataProviderConfiguration `yaml:"providers"`
+	Renames   string                          `yaml:"renames"`
+}
+
+// MetadataProviderConfiguration is a metadata service, e.g. a mirror, queried at Url with {appid} and {language}
+// replaced. NamePath is the dotted path to the name in its JSON response, empty when it answers the name alone.
+type MetadataProviderConfiguration struct {
+	Name     string `yaml:"name"`
+	Url      string `yaml:"url"`
+	NamePath string `yaml:"name-path"`
+}
+
+const DEFAULT_RECIPES_URL = "https://raw.githubusercontent.com/fpetros1/linux-game-launcher/main/pkg/recipes/recipes.yaml"
+
+// RecipesConfiguration applies the known tweaks of a game (see package recipes) unless disabled,
+// Url is where `plauncher recipes update` fetches them from.
+type RecipesConfiguration struct {
+	Enabled bool   `yaml:"enabled"`
+	Url     string `yaml:"url"`
+}
+
+// PrepareStep is a named shell command run before the wrappers are assembled, after the steps it Needs.
+// It is skipped when SkipIf exits successfully, and a failure only stops the launch when it is not Optional.
+// Overrides replace the steps with the same name and append the others.
+type PrepareStep struct {
+	Name     string   `yaml:"name"`
+	Run      string   `yaml:"run"`
+	Needs    []string `yaml:"needs,omitempty"`
+	SkipIf   string   `yaml:"skip-if,omitempty"`
+	Optional bool     `yaml:"optional,omitempty"`
+	OnUpdate bool     `yaml:"on-update,omitempty"`
+}
+
+const MODS_STRATEGY_SYMLINK = "symlink"
+const MODS_STRATEGY_HARDLINK = "hardlink"
+const MODS_STRATEGY_OVERLAY = "overlay"
+
+// ModsConfiguration deploys the Staging folder into Target, relative to the game folder, for the length
+// of the session. GameFolder defaults to the install folder Steam passes to Proton.
+type ModsConfiguration struct {
+	Staging    string `yaml:"staging"`
+	GameFolder string `yaml:"game-folder"`
+	Target     string `yaml:"target"`
+	Strategy   string `yaml:"strategy"`
+}
+
+// DllSwap puts the Source DLL (e.g. a newer nvngx_dlss.dll, or SpecialK as dxgi.dll) at Target, relative to
+// the game folder, for the length of the session. Without Target every file named like Source is replaced.
+type DllSwap struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target,omitempty"`
+}
+
+const INSTANCES_SPLIT_SIDE_BY_SIDE = "side-by-side"
+const INSTANCES_SPLIT_STACKED = "stacked"
+const INSTANCES_ISOLATION_SDL = "sdl"
+const INSTANCES_ISOLATION_DEVICES = "devices"
+
+// InstancesConfiguration shares the screen and the controllers between the instances started with --instance.
+// The display is split in Count gamescope windows, side-by-side or stacked. Controllers picks the controller of
+// each instance, in order, by a part of its name, by vendor:product (e.g. 045e:028e) or by evdev device (e.g.
+// /dev/input/by-id/usb-...-event-joystick), the controllers found are handed out in order otherwise. Isolation
+// sdl hides the other controllers from SDL only, devices runs each instance in bwrap with its own controller
+// alone in /dev/input, which also tells identical controllers apart.
+type InstancesConfiguration struct {
+	Count       int      `yaml:"count"`
+	Split       string   `yaml:"split"`
+	Controllers []string `yaml:"controllers"`
+	Isolation   string   `yaml:"isolation"`
+}
+
+// WakeConfiguration is read by plauncher wake, which launches Command (launch flags and game command, as given to
+// plauncher run) with Profile when the buttons of Combo are held together on a controller, the one Controller names
+// (a part of its name or vendor:product) or any of them, or when a Wake-on-LAN magic packet for this machine
+// arrives on UdpPort. A UdpPort of 0 leaves the network alone.
+type WakeConfiguration struct {
+	Command    string   `yaml:"command"`
+	Profile    string   `yaml:"profile"`
+	Combo      []string `yaml:"combo"`
+	Controller string   `yaml:"controller"`
+	UdpPort    int      `yaml:"udp-port"`
+}
+
+// ControlConfiguration opens a socket for every launch, through which plauncher control adjusts the running game,
+// e.g. from a macro pad.
+type ControlConfiguration struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type DebugConfiguration struct {
+	ProtonLog bool `yaml:"proton-log"`
+}
+
+// WineConfiguration sets up the prefix. Fonts are winetricks font verbs (e.g. corefonts, cjk) or font files,
+// relative to the configuration folder unless absolute.
+type WineConfiguration struct {
+	Alsa           bool     `yaml:"alsa"`
+	Debug          string   `yaml:"debug"`
+	Fonts          []string `yaml:"fonts"`
+	WindowsVersion string   `yaml:"windows-version"`
+}
+
+const WINDOWS_VERSION_WIN7 = "win7"
+const WINDOWS_VERSION_WIN10 = "win10"
+const WINDOWS_VERSION_WIN11 = "win11"
+
+type MangohudConfiguration struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type GamemodeConfiguration struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// GamescopeConfiguration runs the game in gamescope, validate-args checks args against `gamescope --help` first.
+type GamescopeConfiguration struct {
+	Enabled      bool                        `yaml:"enabled"`
+	Hdr          GamescopeHdrConfiguration   `yaml:"hdr"`
+	Input        GamescopeInputConfiguration `yaml:"input"`
+	ValidateArgs bool                        `yaml:"validate-args"`
+	Args         []string                    `yaml:"args"`
+}
+
+// GamescopeInputConfiguration maps to the gamescope input flags. grab-keyboard sends every key to the game,
+// Super included, instead of the desktop (Super+G toggles it), grab-cursor keeps the cursor locked in the
+// game window, hide-cursor-delay hides an idle cursor after that many milliseconds.
+type GamescopeInputConfiguration struct {
+	GrabKeyboard     bool    `yaml:"grab-keyboard"`
+	GrabCursor       bool    `yaml:"grab-cursor"`
+	MouseSensitivity float64 `yaml:"mouse-sensitivity"`
+	HideCursorDelay  int     `yaml:"hide-cursor-delay"`
+}
+
+// GamescopeHdrConfiguration turns HDR output on, the other settings map to gamescope --hdr-* flags and are left
+// to gamescope defaults when zero. hdr-itm upscales SDR games to HDR (inverse tone mapping).
+type GamescopeHdrConfiguration struct {
+	Enabled          bool    `yaml:"enabled"`
+	SdrContentNits   int     `yaml:"sdr-content-nits"`
+	Itm              bool    `yaml:"hdr-itm"`
+	ItmSdrNits       int     `yaml:"itm-sdr-nits"`
+	ItmTargetNits    int     `yaml:"itm-target-nits"`
+	SdrGamutWideness float64 `yaml:"sdr-gamut-wideness"`
+	WideGamutForSdr  bool    `yaml:"wide-gamut-for-sdr"`
+}
+
+type EosConfiguration struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type UmuConfiguration struct {
+	Enabled bool     `yaml:"enabled"`
+	Proton  string   `yaml:"proton"`
+	GameId  string   `yaml:"game-id"`
+	Store   string   `yaml:"store"`
+	Args    []string `yaml:"args"`
+}
+
+// DefaultConfiguration returns the configuration written to disk when no config file exists yet.
+func DefaultConfiguration() Configuration {
+	return Configuration{
+		CURRENT_CONFIG_VERSION,
+		"",
+		make([]string, 0),
+		make([]ConditionalSection, 0),
+		make(map[string]string),
+		WineConfiguration{true, "", make([]string, 0), ""},
+		MangohudConfiguration{false},
+		GamemodeConfiguration{true},
+		GamescopeConfiguration{false, GamescopeHdrConfiguration{false, 0, false, 0, 0, 0, false}, GamescopeInputConfiguration{false, false, 0, 0}, false, make([]string, 0)},
+		EosConfiguration{false},
+		UmuConfiguration{false, "", "", "", make([]string, 0)},
+		make([]string, 0),
+		make([]string, 0),
+		make([]PrepareStep, 0),
+		HomeShortcutsConfiguration{false, ""},
+		DebugConfiguration{false},
+		CompatDataConfiguration{COMPATDATA_MODE_RELOCATE, ""},
+		OverridesConfiguration{[]string{OVERRIDE_BY_ID, OVERRIDE_BY_NAME}},
+		make([]string, 0),
+		make([]string, 0),
+		false,
+		false,
+		AnticheatConfiguration{ANTICHEAT_ACTION_WARN},
+		DependenciesConfiguration{DEPENDENCIES_ACTION_SUGGEST},
+		NetworkConfiguration{"", "", ""},
+		DisplayConfiguration{false, "", ""},
+		AudioConfiguration{"", 0, 0, 0, 0, 0, "", "", ""},
+		TvConfiguration{false, "", TV_MODE_4K60},
+		HandheldConfiguration{"", false, 0},
+		HealthConfiguration{true, DEFAULT_MIN_FREE_MB},
+		GcConfiguration{90, 10, 30, true, 30},
+		SummaryConfiguration{false},
+		TimeoutsConfiguration{"10m", "5m", "10s"},
+		RecordingConfiguration{false, RECORDING_BACKEND_GPU_SCREEN_RECORDER, RECORDING_MODE_REPLAY, "~/Videos", make([]string, 0)},
+		WatchdogConfiguration{"", WATCHDOG_ACTION_NOTIFY},
+		MetadataConfiguration{[]string{METADATA_RESOLVER_STORE, METADATA_RESOLVER_STEAMSPY}, DEFAULT_METADATA_LANGUAGE, make([]MetadataProviderConfiguration, 0), METADATA_RENAMES_WARN},
+		RecipesConfiguration{true, DEFAULT_RECIPES_URL},
+		ModsConfiguration{"", "", "", MODS_STRATEGY_SYMLINK},
+		make([]DllSwap, 0),
+		InstancesConfiguration{2, INSTANCES_SPLIT_SIDE_BY_SIDE, make([]string, 0), INSTANCES_ISOLATION_SDL},
+		WakeConfiguration{"", "", []string{"guide", "start"}, "", 0},
+		ControlConfiguration{true},
+		SecretsConfiguration{SECRET_PROVIDER_FILE, DEFAULT_SECRETS_FILE},
+		make(map[string]bool),
+		make(map[string]string),
+		make(map[string]string),
+		make(map[string]string),
+		make(map[string][]string),
+	}
+}
+
+// LoadConfiguration reads the user configuration layered over the system-wide one, when present.
+// It also returns the configuration new files are seeded with: the system-wide one or the defaults.
+func LoadConfiguration(paths Paths) (Configuration, Configuration) {
+	defaultConfiguration := DefaultConfiguration()
+
+	if _, err := os.Stat(paths.SystemConfigurationFile); os.IsNotExist(err) {
+		return ReadOrCreateUserConfiguration(defaultConfiguration, paths.ConfigurationFile), defaultConfiguration
+	}
+
+	log.Printf("Using system configuration file: %s\n", paths.SystemConfigurationFile)
+
+	// Read once and never written back, /etc belongs to the administrator
+	systemConfiguration := readIncludingConfiguration(DefaultConfiguration(), paths.SystemConfigurationFile, MIGRATE_CONFIGURATION, nil)
+
+	if _, err := os.Stat(paths.ConfigurationFile); os.IsNotExist(err) {
+		return ReadOrCreateUserConfiguration(CloneConfiguration(systemConfiguration), paths.ConfigurationFile), systemConfiguration
+	}
+
+	// Keys the user file leaves out keep the system-wide values rather than the defaults
+	userConfiguration := readConfigurationOver(CloneConfiguration(systemConfiguration), paths.ConfigurationFile)
+	mergedConfiguration := CloneConfiguration(systemConfiguration)
+	ApplyConfigOverrides(&mergedConfiguration, userConfiguration)
+
+	return mergedConfiguration, systemConfiguration
+}
+
+// ReadOrCreateUserConfiguration reads configurationFile, writing defaultConfiguration to it first when it does not exist.
+func ReadOrCreateUserConfiguration(defaultConfiguration Configuration, configurationFile string) Configuration {
+	if _, err := os.Stat(configurationFile); os.IsNotExist(err) {
+		defaultYaml, err := yaml.Marshal(defaultConfiguration)
+		if err != nil {
+			system.Fatalf(system.EXIT_CONFIG_ERROR, "%s\n", err)
+		}
+		system.FS.WriteFile(configurationFile, defaultYaml, DEFAULT_PERMISSION)
+		return defaultConfiguration
+	}
+
+	return readConfiguration(configurationFile)
+}
+
+// CloneConfiguration copies configuration along with its maps, which reading a file over it or applying launch
+// settings would change otherwise.
+func CloneConfiguration(configuration Configuration) Configuration {
+	clone := configuration
+	clone.Environment = maps.Clone(configuration.Environment)
+	clone.SpecialFlags = maps.Clone(configuration.SpecialFlags)
+	clone.Props = maps.Clone(configuration.Props)
+	clone.Provenance = maps.Clone(configuration.Provenance)
+	clone.ListMerge = maps.Clone(configuration.ListMerge)
+	clone.FlagValues = maps.Clone(configuration.FlagValues)
+
+	return clone
+}
+
+func readConfiguration(configurationFile string) Configuration {
+	return readConfigurationOver(DefaultConfiguration(), configurationFile)
+}
+
+// Reads configurationFile on top of base, which gives the keys the file leaves out, e.g. the sections added
+// after it was written, their value
+func readConfigurationOver(base Configuration, configurationFile string) Configuration {
+	return readIncludingConfiguration(base, configurationFile, MIGRATE_USER_CONFIGURATION, nil)
+}
+
+// The first included file, read over base, is the configuration configurationFile starts from, the others merge on top of it
+// like overrides, then configurationFile itself
+func readIncludingConfiguration(base Configuration, configurationFile string, target migrationTarget, including []string) Configuration {
+	configurationFileContent, includes := readIncludingFile(configurationFile, target, including)
+	userConfiguration := base
+
+	if len(includes) > 0 {
+		including = append(including, configurationFile)
+		userConfiguration = readIncludingConfiguration(base, includes[0], MIGRATE_OVERLAY, including)
+
+		for _, includedFile := range includes[1:] {
+			applyOverlayFile(&userConfiguration, includedFile, including)
+		}
+	}
+
+	yamlErr := unmarshalConfiguration(configurationFileContent, &userConfiguration)
+
+	if yamlErr != nil {
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "Invalid configuration %s: %s\n", configurationFile, yamlErr)
+	}
+
+	// Only meaningful in the file, the configuration holds what they brought in
+	userConfiguration.Extends = ""
+	userConfiguration.Include = nil
+	userConfiguration.Conditional = nil
+
+	if userConfiguration.Environment == nil {
+		userConfiguration.Environment = make(map[string]string)
+	}
+
+	userConfiguration.SpecialFlags = make(map[string]bool)
+	userConfiguration.Props = make(map[string]string)
+	userConfiguration.Provenance = make(map[string]string)
+	userConfiguration.ListMerge = readListMergeStrategies(configurationFileContent)
+
+	applyConditionalSections(&userConfiguration, configurationFileContent, configurationFile)
+
+	return userConfiguration
+}
+
+// ApplyConfigOverrides merges a game override on top of currentConfiguration.
+func ApplyConfigOverrides(currentConfiguration *Configuration, overrideConfiguration Configuration) {
+	for key, value := range overrideConfiguration.Environment {
+		currentConfiguration.Environment[key] = value
+	}
+
+	currentConfiguration.Gamemode.Enabled = overrideConfiguration.Gamemode.Enabled
+	currentConfiguration.Mangohud.Enabled = overrideConfiguration.Mangohud.Enabled
+
+	currentConfiguration.Gamescope.Enabled = overrideConfiguration.Gamescope.Enabled
+	currentConfiguration.Gamescope.ValidateArgs = overrideConfiguration.Gamescope.ValidateArgs
+	applyHdrOverrides(&currentConfiguration.Gamescope.Hdr, overrideConfiguration.Gamescope.Hdr)
+	applyGamescopeInputOverrides(&currentConfiguration.Gamescope.Input, overrideConfiguration.Gamescope.Input)
+
+	currentConfiguration.EosOverlay.Enabled = overrideConfiguration.EosOverlay.Enabled
+
+	currentConfiguration.Umu.Enabled = overrideConfiguration.Umu.Enabled
+
+	currentConfiguration.Wine.Alsa = overrideConfiguration.Wine.Alsa
+
+	if overrideConfiguration.Wine.Debug != "" {
+		currentConfiguration.Wine.Debug = overrideConfiguration.Wine.Debug
+	}
+
+	if overrideConfiguration.Wine.WindowsVersion != "" {
+		currentConfiguration.Wine.WindowsVersion = overrideConfiguration.Wine.WindowsVersion
+	}
+
+	currentConfiguration.Debug.ProtonLog = overrideConfiguration.Debug.ProtonLog
+	currentConfiguration.Passthrough = overrideConfiguration.Passthrough
+	currentConfiguration.Strict = overrideConfiguration.Strict
+	currentConfiguration.Display.SuspendNightLight = overrideConfiguration.Display.SuspendNightLight
+	currentConfiguration.Recipes.Enabled = overrideConfiguration.Recipes.Enabled
+	currentConfiguration.Recording.Enabled = overrideConfiguration.Recording.Enabled
+
+	if overrideConfiguration.Display.Vrr != "" {
+		currentConfiguration.Display.Vrr = overrideConfiguration.Display.Vrr
+	}
+
+	if overrideConfiguration.Display.Output != "" {
+		currentConfiguration.Display.Output = overrideConfiguration.Display.Output
+	}
+
+	applyAudioOverrides(&currentConfiguration.Audio, overrideConfiguration.Audio)
+
+	currentConfiguration.Tv.Enabled = overrideConfiguration.Tv.Enabled
+
+	if overrideConfiguration.Tv.Output != "" {
+		currentConfiguration.Tv.Output = overrideConfiguration.Tv.Output
+	}
+
+	if overrideConfiguration.Tv.Mode != "" {
+		currentConfiguration.Tv.Mode = overrideConfiguration.Tv.Mode
+	}
+
+	currentConfiguration.Health.Enabled = overrideConfiguration.Health.Enabled
+
+	if overrideConfiguration.Health.MinFreeMb != 0 {
+		currentConfiguration.Health.MinFreeMb = overrideConfiguration.Health.MinFreeMb
+	}
+
+	currentConfiguration.Summary.Notify = overrideConfiguration.Summary.Notify
+
+	if overrideConfiguration.Timeouts.Winetricks != "" {
+		currentConfiguration.Timeouts.Winetricks = overrideConfiguration.Timeouts.Winetricks
+	}
+
+	if overrideConfiguration.Timeouts.Legendary != "" {
+		currentConfiguration.Timeouts.Legendary = overrideConfiguration.Timeouts.Legendary
+	}
+
+	if overrideConfiguration.Timeouts.Probe != "" {
+		currentConfiguration.Timeouts.Probe = overrideConfiguration.Timeouts.Probe
+	}
+
+	currentConfiguration.Gc.ShaderCaches = overrideConfiguration.Gc.ShaderCaches
+
+	if overrideConfiguration.Gc.NameCacheDays != 0 {
+		currentConfiguration.Gc.NameCacheDays = overrideConfiguration.Gc.NameCacheDays
+	}
+
+	if overrideConfiguration.Gc.SessionLogs != 0 {
+		currentConfiguration.Gc.SessionLogs = overrideConfiguration.Gc.SessionLogs
+	}
+
+	if overrideConfiguration.Gc.SessionLogDays != 0 {
+		currentConfiguration.Gc.SessionLogDays = overrideConfiguration.Gc.SessionLogDays
+	}
+
+	if overrideConfiguration.Gc.BackupDays != 0 {
+		currentConfiguration.Gc.BackupDays = overrideConfiguration.Gc.BackupDays
+	}
+
+	currentConfiguration.Handheld.AllowTdp = overrideConfiguration.Handheld.AllowTdp
+
+	if overrideConfiguration.Handheld.Preset != "" {
+		currentConfiguration.Handheld.Preset = overrideConfiguration.Handheld.Preset
+	}
+
+	if overrideConfiguration.Handheld.TdpWatts != 0 {
+		currentConfiguration.Handheld.TdpWatts = overrideConfiguration.Handheld.TdpWatts
+	}
+
+	if overrideConfiguration.Watchdog.Timeout != "" {
+		currentConfiguration.Watchdog.Timeout = overrideConfiguration.Watchdog.Timeout
+	}
+
+	if overrideConfiguration.Watchdog.Action != "" {
+		currentConfiguration.Watchdog.Action = overrideConfiguration.Watchdog.Action
+	}
+
+	if len(overrideConfiguration.DllSwaps) > 0 {
+		currentConfiguration.DllSwaps = overrideConfiguration.DllSwaps
+	}
+
+	if overrideConfiguration.Mods.Staging != "" {
+		currentConfiguration.Mods.Staging = overrideConfiguration.Mods.Staging
+	}
+
+	if overrideConfiguration.Mods.GameFolder != "" {
+		currentConfiguration.Mods.GameFolder = overrideConfiguration.Mods.GameFolder
+	}
+
+	if overrideConfiguration.Mods.Target != "" {
+		currentConfiguration.Mods.Target = overrideConfiguration.Mods.Target
+	}
+
+	if overrideConfiguration.Mods.Strategy != "" {
+		currentConfiguration.Mods.Strategy = overrideConfiguration.Mods.Strategy
+	}
+
+	if overrideConfiguration.Recipes.Url != "" {
+		currentConfiguration.Recipes.Url = overrideConfiguration.Recipes.Url
+	}
+
+	if overrideConfiguration.Metadata.Language != "" {
+		currentConfiguration.Metadata.Language = overrideConfiguration.Metadata.Language
+	}
+
+	if len(overrideConfiguration.Metadata.Resolvers) > 0 {
+		currentConfiguration.Metadata.Resolvers = overrideConfiguration.Metadata.Resolvers
+	}
+
+	if len(overrideConfiguration.Metadata.Providers) > 0 {
+		currentConfiguration.Metadata.Providers = overrideConfiguration.Metadata.Providers
+	}
+
+	if overrideConfiguration.Metadata.Renames != "" {
+		currentConfiguration.Metadata.Renames = overrideConfiguration.Metadata.Renames
+	}
+
+	if overrideConfiguration.Instances.Count != 0 {
+		currentConfiguration.Instances.Count = overrideConfiguration.Instances.Count
+	}
+
+	if overrideConfiguration.Instances.Split != "" {
+		currentConfiguration.Instances.Split = overrideConfiguration.Instances.Split
+	}
+
+	if len(overrideConfiguration.Instances.Controllers) > 0 {
+		currentConfiguration.Instances.Controllers = overrideConfiguration.Instances.Controllers
+	}
+
+	if overrideConfiguration.Instances.Isolation != "" {
+		currentConfiguration.Instances.Isolation = overrideConfiguration.Instances.Isolation
+	}
+
+	if overrideConfiguration.Recording.Backend != "" {
+		currentConfiguration.Recording.Backend = overrideConfiguration.Recording.Backend
+	}
+
+	if overrideConfiguration.Recording.Mode != "" {
+		currentConfiguration.Recording.Mode = overrideConfiguration.Recording.Mode
+	}
+
+	if overrideConfiguration.Recording.Folder != "" {
+		currentConfiguration.Recording.Folder = overrideConfiguration.Recording.Folder
+	}
+
+	if overrideConfiguration.CompatData.Mode != "" {
+		currentConfiguration.CompatData.Mode = overrideConfiguration.CompatData.Mode
+	}
+
+	if overrideConfiguration.CompatData.Base != "" {
+		currentConfiguration.CompatData.Base = overrideConfiguration.CompatData.Base
+	}
+
+	if overrideConfiguration.Network.Limit != "" {
+		currentConfiguration.Network.Limit = overrideConfiguration.Network.Limit
+	}
+
+	if overrideConfiguration.Network.Interface != "" {
+		currentConfiguration.Network.Interface = overrideConfiguration.Network.Interface
+	}
+
+	if overrideConfiguration.Network.Namespace != "" {
+		currentConfiguration.Network.Namespace = overrideConfiguration.Network.Namespace
+	}
+
+	if overrideConfiguration.Anticheat.Action != "" {
+		currentConfiguration.Anticheat.Action = overrideConfiguration.Anticheat.Action
+	}
+
+	if overrideConfiguration.Dependencies.Action != "" {
+		currentConfiguration.Dependencies.Action = overrideConfiguration.Dependencies.Action
+	}
+
+	if len(overrideConfiguration.Overrides.Precedence) > 0 {
+		currentConfiguration.Overrides.Precedence = overrideConfiguration.Overrides.Precedence
+	}
+
+	applyWakeOverrides(&currentConfiguration.Wake, overrideConfiguration.Wake)
+
+	currentConfiguration.Control.Enabled = overrideConfiguration.Control.Enabled
+
+	if overrideConfiguration.Secrets.Provider != "" {
+		currentConfiguration.Secrets.Provider = overrideConfiguration.Secrets.Provider
+	}
+
+	if overrideConfiguration.Secrets.File != "" {
+		currentConfiguration.Secrets.File = overrideConfiguration.Secrets.File
+	}
+
+	if overrideConfiguration.Umu.Proton != "" {
+		currentConfiguration.Umu.Proton = overrideConfiguration.Umu.Proton
+	}
+
+	if overrideConfiguration.Umu.Store != "" {
+		currentConfiguration.Umu.Store = overrideConfiguration.Umu.Store
+	}
+
+	if overrideConfiguration.Umu.GameId != "" {
+		currentConfiguration.Umu.GameId = overrideConfiguration.Umu.GameId
+	}
+
+	currentConfiguration.Umu.Args = mergeList(currentConfiguration.Umu.Args, overrideConfiguration.Umu.Args, overrideConfiguration.ListMerge["umu.args"])
+	currentConfiguration.Gamescope.Args = mergeList(currentConfiguration.Gamescope.Args, overrideConfiguration.Gamescope.Args, overrideConfiguration.ListMerge["gamescope.args"])
+	currentConfiguration.Wine.Fonts = mergeList(currentConfiguration.Wine.Fonts, overrideConfiguration.Wine.Fonts, overrideConfiguration.ListMerge["wine.fonts"])
+	currentConfiguration.PreScripts = mergeList(currentConfiguration.PreScripts, overrideConfiguration.PreScripts, overrideConfiguration.ListMerge["pre-scripts"])
+	currentConfiguration.PostScripts = mergeList(currentConfiguration.PostScripts, overrideConfiguration.PostScripts, overrideConfiguration.ListMerge["post-scripts"])
+	currentConfiguration.Prepare = mergePrepareSteps(currentConfiguration.Prepare, overrideConfiguration.Prepare)
+	currentConfiguration.Recording.Args = mergeList(currentConfiguration.Recording.Args, overrideConfiguration.Recording.Args, overrideConfiguration.ListMerge["recording.args"])
+	currentConfiguration.OnlyFor = mergeList(currentConfiguration.OnlyFor, overrideConfiguration.OnlyFor, overrideConfiguration.ListMerge["only-for"])
+	currentConfiguration.SkipFor = mergeList(currentConfiguration.SkipFor, overrideConfiguration.SkipFor, overrideConfiguration.ListMerge["skip-for"])
+}
+
+func applyWakeOverrides(current *WakeConfiguration, override WakeConfiguration) {
+	if override.Command != "" {
+		current.Command = override.Command
+	}
+
+	if override.Profile != "" {
+		current.Profile = override.Profile
+	}
+
+	if len(override.Combo) > 0 {
+		current.Combo = override.Combo
+	}
+
+	if override.Controller != "" {
+		current.Controller = override.Controller
+	}
+
+	if override.UdpPort != 0 {
+		current.UdpPort = override.UdpPort
+	}
+}
+
+func applyAudioOverrides(current *AudioConfiguration, override AudioConfiguration) {
+	if override.Sink != "" {
+		current.Sink = override.Sink
+	}
+
+	if override.LatencyMsec != 0 {
+		current.LatencyMsec = override.LatencyMsec
+	}
+
+	if override.Quantum != 0 {
+		current.Quantum = override.Quantum
+	}
+
+	if override.Rate != 0 {
+		current.Rate = override.Rate
+	}
+
+	if override.WinePeriodUsec != 0 {
+		current.WinePeriodUsec = override.WinePeriodUsec
+	}
+
+	if override.WineDurationUsec != 0 {
+		current.WineDurationUsec = override.WineDurationUsec
+	}
+
+	if override.Card != "" {
+		current.Card = override.Card
+	}
+
+	if override.Profile != "" {
+		current.Profile = override.Profile
+	}
+
+	if override.FilterChain != "" {
+		current.FilterChain = override.FilterChain
+	}
+}
+
+func applyGamescopeInputOverrides(current *GamescopeInputConfiguration, override GamescopeInputConfiguration) {
+	current.GrabKeyboard = override.GrabKeyboard
+	current.GrabCursor = override.GrabCursor
+
+	if override.MouseSensitivity != 0 {
+		current.MouseSensitivity = override.MouseSensitivity
+	}
+
+	if override.HideCursorDelay != 0 {
+		current.HideCursorDelay = override.HideCursorDelay
+	}
+}
+
+func applyHdrOverrides(current *GamescopeHdrConfiguration, override GamescopeHdrConfiguration) {
+	current.Enabled = override.Enabled
+	current.Itm = override.Itm
+	current.WideGamutForSdr = override.WideGamutForSdr
+
+	if override.SdrContentNits != 0 {
+		current.SdrContentNits = override.SdrContentNits
+	}
+
+	if override.ItmSdrNits != 0 {
+		current.ItmSdrNits = override.ItmSdrNits
+	}
+
+	if override.ItmTargetNits != 0 {
+		current.ItmTargetNits = override.ItmTargetNits
+	}
+
+	if override.SdrGamutWideness != 0 {
+		current.SdrGamutWideness = override.SdrGamutWideness
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ENV_OVERRIDE_PREFIX starts the variables setting a configuration key, e.g. PLAUNCHER_GAMESCOPE_ENABLED=1 sets
+// gamescope.enabled and PLAUNCHER_ENVIRONMENT_DXVK_HUD=fps sets the DXVK_HUD variable of the game.
+const ENV_OVERRIDE_PREFIX = "PLAUNCHER_"
+
+// envOverrideKey is a configuration key variables can set, with the YAML tag of its value
+type envOverrideKey struct {
+	key string
+	tag string
+}
+
+// ApplyEnvironmentOverrides merges the PLAUNCHER_* variables of environ on top of configuration, above the launch
+// overlay and below --set. Variables naming no configuration key are left alone, plauncher sets some for scripts.
+func ApplyEnvironmentOverrides(configuration *Configuration, environ []string) error {
+	keys := envOverrideKeys()
+
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+
+		if !strings.HasPrefix(name, ENV_OVERRIDE_PREFIX) {
+			continue
+		}
+
+		setting, found := envOverrideSetting(keys, strings.TrimPrefix(name, ENV_OVERRIDE_PREFIX), value)
+
+		if !found {
+			log.Printf("Ignoring %s, it names no configuration key\n", name)
+			continue
+		}
+
+		if err := applySettings(configuration, []string{setting}, "$"+name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Turns the variable name, without its prefix, and value into a key=value setting as --set takes them
+func envOverrideSetting(keys map[string]envOverrideKey, name string, value string) (string, bool) {
+	// Variable names are kept as they are, only their section is matched
+	if variable, found := strings.CutPrefix(name, "ENVIRONMENT_"); found && variable != "" {
+		return "environment." + variable + "=" + quoteYamlString(value), true
+	}
+
+	key, found := keys[strings.ToLower(name)]
+
+	if !found {
+		return "", false
+	}
+
+	switch key.tag {
+	case "!!bool":
+		// Launch options are written 1 and 0 more often than true and false
+		if value == "1" {
+			value = "true"
+		} else if value == "0" {
+			value = "false"
+		}
+	case "!!seq":
+		if !strings.HasPrefix(strings.TrimSpace(value), "[") {
+			words := make([]string, 0)
+
+			for _, word := range strings.Fields(value) {
+				words = append(words, quoteYamlString(word))
+			}
+
+			value = "[" + strings.Join(words, ", ") + "]"
+		}
+	case "!!str":
+		value = quoteYamlString(value)
+	}
+
+	return key.key + "=" + value, true
+}
+
+// The keys of the configuration by variable name, lowercase and with dots and dashes as underscores
+func envOverrideKeys() map[string]envOverrideKey {
+	document := yaml.Node{}
+	document.Encode(DefaultConfiguration())
+
+	keys := make(map[string]envOverrideKey)
+	collectEnvOverrideKeys(&document, "", keys)
+
+	return keys
+}
+
+func collectEnvOverrideKeys(node *yaml.Node, prefix string, keys map[string]envOverrideKey) {
+	if node.Kind != yaml.MappingNode {
+		keys[strings.NewReplacer(".", "_", "-", "_").Replace(prefix)] = envOverrideKey{prefix, node.Tag}
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+
+		if prefix == "" && slices.Contains(notOverridableKeys, key) {
+			continue
+		}
+
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		collectEnvOverrideKeys(node.Content[i+1], key, keys)
+	}
+}
+
+// A JSON string is a valid double quoted YAML scalar, the value is kept as a string whatever it looks like
+func quoteYamlString(value string) string {
+	quoted, _ := json.Marshal(value)
+
+	return string(quoted)
+}
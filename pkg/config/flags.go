@@ -0,0 +1,248 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const HELP_FLAG = "help"
+const SET_FLAG = "set"
+const ENV_FLAG = "env"
+const DRY_RUN_FLAG = "dry-run"
+const PRINT_CONFIG_FLAG = "print-config"
+const INSTANCE_FLAG = "instance"
+const PROFILE_FLAG = "profile"
+
+// LaunchFlag is a --flag given before the game command. Flags with a Value take it as --flag=value or
+// --flag value and land in Props, or in FlagValues when Repeatable, the others are switches landing in
+// SpecialFlags. OptionalValue flags are switches unless given a value with =.
+type LaunchFlag struct {
+	Name          string
+	Value         string
+	OptionalValue bool
+	Repeatable    bool
+	Usage         string
+}
+
+// LAUNCH_FLAGS lists every flag plauncher understands before the game command.
+var LAUNCH_FLAGS = []LaunchFlag{
+	{"name", "NAME", false, false, "game name, used for overrides, prefixes and logs of games outside Steam"},
+	{"id", "ID", false, false, "game id, e.g. an appid or a store codename, used for overrides"},
+	{"steam-appid", "APPID", false, false, "Steam appid, skips its detection from the Steam environment"},
+	{"store", "STORE", false, false, "umu store of the game (egs, gog...), detected from the command otherwise"},
+	{PROFILE_FLAG, "NAME", false, false, "merge the profile NAME (profiles/NAME.yaml) above the configuration and below the game overrides"},
+	{OVERLAY_PROP, "FILE", false, false, "merge FILE, in the format of the overrides, above the game overrides for this launch"},
+	{SET_FLAG, "KEY=VALUE", false, true, "set a configuration key for this launch, above the overlay, e.g. umu.proton=GE-Proton9-21"},
+	{ENV_FLAG, "KEY=VALUE", false, true, "set an environment variable for this launch, above the configuration, overrides and --set"},
+	{INSTANCE_FLAG, "N", false, false, "run instance N of the game for local co-op, with its own prefix clone, gamescope window and controller"},
+	{"wine-debug", "CHANNELS", true, false, "set WINEDEBUG, to CHANNELS or to the default channels without a value"},
+	{"events-fd", "FD", false, false, "write launch events as JSON lines to file descriptor FD"},
+	{"events-pipe", "PATH", false, false, "write launch events as JSON lines to the named pipe PATH"},
+	{PASSTHROUGH_FLAG, "", false, false, "run the game command untouched"},
+	{"simulate", "", false, false, "print what would run instead of running it"},
+	{DRY_RUN_FLAG, "", false, false, "print only the final command and environment changes, running and changing nothing"},
+	{PRINT_CONFIG_FLAG, "yaml|json", true, false, "print the configuration the game would run with, overrides and flags merged, without launching"},
+	{"save-name", "", false, false, "save the resulting configuration as the override of the game name"},
+	{"save-id", "", false, false, "save the resulting configuration as the override of the game id"},
+	{HELP_FLAG, "", false, false, "show this help"},
+}
+
+// Single character switches, -G enables gamescope and -!G disables it
+var shortFlags = map[rune]string{
+	'G': "gamescope",
+	'g': "gamemode",
+	'h': "gamescope HDR",
+	'm': "MangoHud",
+	'e': "EOS overlay",
+}
+
+// EnrichConfigurationWithArgvFlags applies the plauncher flags found in args (argv, program name included)
+// and returns the index of the first argument belonging to the game command.
+func EnrichConfigurationWithArgvFlags(configuration *Configuration, args []string) (int, error) {
+	return scanArgvFlags(args, func(flag LaunchFlag, value string, hasValue bool) {
+		if hasValue && flag.Repeatable {
+			if configuration.FlagValues == nil {
+				configuration.FlagValues = make(map[string][]string)
+			}
+
+			configuration.FlagValues[flag.Name] = append(configuration.FlagValues[flag.Name], value)
+			return
+		}
+
+		if hasValue {
+			configuration.Props[flag.Name] = value
+			return
+		}
+
+		configuration.SpecialFlags[flag.Name] = true
+	}, func(char rune, value bool) {
+		parseBooleanDashParam(configuration, char, value)
+	})
+}
+
+// CheckArgvFlags reports the first unknown or malformed plauncher flag in args, invalid --set settings, --env
+// variables and --print-config formats included, or a missing game command.
+func CheckArgvFlags(args []string) error {
+	settings := make([]string, 0)
+	var valueErr error
+
+	_, err := scanArgvFlags(args, func(flag LaunchFlag, value string, hasValue bool) {
+		if flag.Name == SET_FLAG {
+			settings = append(settings, value)
+		}
+
+		if key, _, found := strings.Cut(value, "="); flag.Name == ENV_FLAG && (!found || !isEnvironmentName(key)) {
+			valueErr = fmt.Errorf("Invalid --%s %s, expected KEY=VALUE", ENV_FLAG, value)
+		}
+
+		if flag.Name == PRINT_CONFIG_FLAG && hasValue && value != "yaml" && value != "json" {
+			valueErr = fmt.Errorf("Unknown format for --%s: %s, expected yaml or json", PRINT_CONFIG_FLAG, value)
+		}
+
+		if instance, err := strconv.Atoi(value); flag.Name == INSTANCE_FLAG && (err != nil || instance < 1) {
+			valueErr = fmt.Errorf("Invalid --%s %s, expected a number from 1", INSTANCE_FLAG, value)
+		}
+	}, func(rune, bool) {})
+
+	if err != nil {
+		return err
+	}
+
+	if valueErr != nil {
+		return valueErr
+	}
+
+	_, err = launchSettingsContent(settings)
+
+	return err
+}
+
+// Reports whether name can be an environment variable, letters, digits and underscores not starting with a digit
+func isEnvironmentName(name string) bool {
+	for index, char := range name {
+		if char != '_' && !(char >= 'A' && char <= 'Z') && !(char >= 'a' && char <= 'z') && !(index > 0 && char >= '0' && char <= '9') {
+			return false
+		}
+	}
+
+	return name != ""
+}
+
+// Instance returns the instance number given with --instance, 0 when the game is launched on its own.
+func Instance(configuration Configuration) int {
+	instance, _ := strconv.Atoi(configuration.Props[INSTANCE_FLAG])
+
+	return instance
+}
+
+// HasArgvFlag reports whether --flag is among the plauncher flags in args, before the game command.
+func HasArgvFlag(args []string, flag string) bool {
+	found := false
+
+	scanArgvFlags(args, func(launchFlag LaunchFlag, value string, hasValue bool) {
+		if launchFlag.Name == flag {
+			found = true
+		}
+	}, func(rune, bool) {})
+
+	return found
+}
+
+// PrintLaunchUsage writes the --help text to out, subcommands being the names of the management subcommands.
+func PrintLaunchUsage(out io.Writer, subcommands []string) {
+	fmt.Fprintf(out, "Usage: %s [run] [flags] [--] <game command>\n", APP_NAME)
+	fmt.Fprintf(out, "       %s <%s> ...\n\n", APP_NAME, strings.Join(subcommands, "|"))
+	fmt.Fprintf(out, "Flags:\n")
+
+	for _, flag := range LAUNCH_FLAGS {
+		usage := "--" + flag.Name
+
+		if flag.OptionalValue {
+			usage += "[=" + flag.Value + "]"
+		} else if flag.Value != "" {
+			usage += " " + flag.Value
+		}
+
+		fmt.Fprintf(out, "  %-26s %s\n", usage, flag.Usage)
+	}
+
+	chars := make([]string, 0, len(shortFlags))
+
+	for _, char := range "Gghme" {
+		chars = append(chars, fmt.Sprintf("-%c %s", char, shortFlags[char]))
+	}
+
+	fmt.Fprintf(out, "\nSwitches, combinable (-Gm) and turned off with -! (-!G): %s\n", strings.Join(chars, ", "))
+}
+
+// Walks the flags before the game command, handing every known one to onFlag and onShort. Returns the index of
+// the game command, or an error for unknown flags, flags missing their value and a missing game command.
+func scanArgvFlags(args []string, onFlag func(flag LaunchFlag, value string, hasValue bool), onShort func(char rune, value bool)) (int, error) {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--":
+			if i+1 < len(args) {
+				return i + 1, nil
+			}
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			index := slices.IndexFunc(LAUNCH_FLAGS, func(flag LaunchFlag) bool { return flag.Name == name })
+
+			if index < 0 {
+				return -1, fmt.Errorf("Unknown flag --%s", name)
+			}
+
+			flag := LAUNCH_FLAGS[index]
+
+			if hasValue && flag.Value == "" {
+				return -1, fmt.Errorf("Flag --%s takes no value", name)
+			}
+
+			if !hasValue && flag.Value != "" && !flag.OptionalValue {
+				if i+1 >= len(args) {
+					return -1, fmt.Errorf("Flag --%s needs a value: %s", name, flag.Value)
+				}
+
+				i++
+				value, hasValue = args[i], true
+			}
+
+			onFlag(flag, value, hasValue)
+		case strings.HasPrefix(arg, "-!") || (strings.HasPrefix(arg, "-") && len(arg) > 1):
+			value := !strings.HasPrefix(arg, "-!")
+
+			for _, char := range strings.TrimLeft(arg, "-!") {
+				if _, exists := shortFlags[char]; !exists {
+					return -1, fmt.Errorf("Unknown switch -%c in %s", char, arg)
+				}
+
+				onShort(char, value)
+			}
+		default:
+			return i, nil
+		}
+	}
+
+	return -1, errors.New(fmt.Sprintf("Could not find command in: %s", args))
+}
+
+func parseBooleanDashParam(configuration *Configuration, char rune, value bool) {
+	switch char {
+	case 'G':
+		configuration.Gamescope.Enabled = value
+	case 'g':
+		configuration.Gamemode.Enabled = value
+	case 'h':
+		configuration.Gamescope.Hdr.Enabled = value
+	case 'm':
+		configuration.Mangohud.Enabled = value
+	case 'e':
+		configuration.EosOverlay.Enabled = value
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// The keys naming the files a configuration or override file builds on
+type includesDocument struct {
+	Extends string   `yaml:"extends"`
+	Include []string `yaml:"include"`
+}
+
+// Returns the files configurationFile builds on, extends first then include in order, relative paths being
+// relative to the folder of configurationFile
+func includedFiles(configurationFile string, content []byte) ([]string, error) {
+	document := includesDocument{}
+
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, nil
+	}
+
+	includes := document.Include
+
+	if document.Extends != "" {
+		includes = append([]string{document.Extends}, includes...)
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	files := make([]string, 0, len(includes))
+
+	for _, include := range includes {
+		file := ExpandUserPath(include, homeDir)
+
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(configurationFile), file)
+		}
+
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("%s includes %s: %w", configurationFile, include, err)
+		}
+
+		files = append(files, filepath.Clean(file))
+	}
+
+	return files, nil
+}
+
+// Reads configurationFile, migrated, along with the files it includes, stopping the launch on an invalid or
+// missing file or on a file including itself
+func readIncludingFile(configurationFile string, target migrationTarget, including []string) ([]byte, []string) {
+	if slices.Contains(including, configurationFile) {
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "%s includes itself: %s\n", configurationFile, strings.Join(append(including, configurationFile), " -> "))
+	}
+
+	content, err := os.ReadFile(configurationFile)
+
+	if err != nil {
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "%s\n", err)
+	}
+
+	content = migrateConfigurationFile(configurationFile, content, target)
+
+	if problems := fileProblems(configurationFile, content); len(problems) > 0 {
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "Invalid configuration:\n%s\n", ConfigurationErrors(problems))
+	}
+
+	includes, err := includedFiles(configurationFile, content)
+
+	if err != nil {
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "%s\n", err)
+	}
+
+	return content, includes
+}
+
+// Merges overlayFile on top of configuration after the files it includes, in the way of the game overrides.
+// Overlays are migrated in memory only, without the defaults configurations get, so they stay sparse.
+func applyOverlayFile(configuration *Configuration, overlayFile string, including []string) {
+	content, includes := readIncludingFile(overlayFile, MIGRATE_OVERLAY, including)
+
+	for _, includedFile := range includes {
+		applyOverlayFile(configuration, includedFile, append(including, overlayFile))
+	}
+
+	overlay, keys := overlayConfiguration(*configuration, content, overlayFile)
+	applyOverlay(configuration, overlay, keys, overlayFile)
+	applyConditionalSections(configuration, content, overlayFile)
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// List fields are appended to by default, tagging them changes how an override merges them:
+//
+//	gamescope:
+//	    args: !replace [-W, "1920", -H, "1080"]
+const LIST_MERGE_APPEND = "!append"
+const LIST_MERGE_REPLACE = "!replace"
+const LIST_MERGE_PREPEND = "!prepend"
+
+// Entries written as -"value" remove value from the list instead of adding it.
+const LIST_REMOVAL_PREFIX = "-\""
+const LIST_REMOVAL_SUFFIX = "\""
+
+// Returns the merge strategy of every tagged list in content, by dotted key
+func readListMergeStrategies(content []byte) map[string]string {
+	strategies := make(map[string]string)
+	document := yaml.Node{}
+
+	if err := yaml.Unmarshal(content, &document); err != nil || len(document.Content) == 0 {
+		return strategies
+	}
+
+	collectListMergeStrategies(document.Content[0], "", strategies)
+
+	return strategies
+}
+
+func collectListMergeStrategies(node *yaml.Node, prefix string, strategies map[string]string) {
+	if node.Kind == yaml.SequenceNode && (node.Tag == LIST_MERGE_REPLACE || node.Tag == LIST_MERGE_PREPEND) {
+		strategies[prefix] = node.Tag
+		return
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		collectListMergeStrategies(node.Content[i+1], key, strategies)
+	}
+}
+
+// Merges override into current following strategy, appending when it is empty
+func mergeList(current []string, override []string, strategy string) []string {
+	added := make([]string, 0, len(override))
+	removed := make([]string, 0)
+
+	for _, value := range override {
+		if removal, isRemoval := listRemovalEntry(value); isRemoval {
+			removed = append(removed, expandEnvKeepingPlaceholders(removal))
+			continue
+		}
+
+		value = expandEnvKeepingPlaceholders(value)
+
+		if strategy == LIST_MERGE_REPLACE || (!slices.Contains(current, value) && !slices.Contains(added, value)) {
+			added = append(added, value)
+		}
+	}
+
+	if strategy == LIST_MERGE_REPLACE {
+		return added
+	}
+
+	current = slices.DeleteFunc(slices.Clone(current), func(value string) bool {
+		return slices.Contains(removed, value)
+	})
+
+	if strategy == LIST_MERGE_PREPEND {
+		return append(added, current...)
+	}
+
+	return append(current, added...)
+}
+
+func listRemovalEntry(value string) (string, bool) {
+	if len(value) > len(LIST_REMOVAL_PREFIX) && strings.HasPrefix(value, LIST_REMOVAL_PREFIX) && strings.HasSuffix(value, LIST_REMOVAL_SUFFIX) {
+		return value[len(LIST_REMOVAL_PREFIX) : len(value)-len(LIST_REMOVAL_SUFFIX)], true
+	}
+
+	return "", false
+}
+
+// Steps of override replace the current ones with the same name, the others are appended
+func mergePrepareSteps(current []PrepareStep, override []PrepareStep) []PrepareStep {
+	merged := slices.Clone(current)
+
+	for _, step := range override {
+		index := slices.IndexFunc(merged, func(currentStep PrepareStep) bool {
+			return currentStep.Name == step.Name
+		})
+
+		if index < 0 {
+			merged = append(merged, step)
+			continue
+		}
+
+		merged[index] = step
+	}
+
+	return merged
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestListMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  []string
+		override string
+		expected []string
+	}{
+		{"append", []string{"-f"}, "[-W, \"1920\"]", []string{"-f", "-W", "1920"}},
+		{"append skips values already there", []string{"-f", "-W"}, "[-W, -H]", []string{"-f", "-W", "-H"}},
+		{"replace", []string{"-f"}, "!replace [-W, \"1920\"]", []string{"-W", "1920"}},
+		{"replace with an empty list", []string{"-f"}, "!replace []", []string{}},
+		{"prepend", []string{"-f"}, "!prepend [-W, \"1920\"]", []string{"-W", "1920", "-f"}},
+		{"prepend on an empty list", []string{}, "!prepend [-W, \"1920\"]", []string{"-W", "1920"}},
+		{"removal", []string{"-f", "-W", "1920"}, "['-\"-f\"']", []string{"-W", "1920"}},
+		{"removal of an entry not in the list", []string{"-f"}, "['-\"--hdr-enabled\"']", []string{"-f"}},
+		{"removal and addition", []string{"-f"}, "['-\"-f\"', -b]", []string{"-b"}},
+		{"quoted value with spaces", []string{"-f"}, "[\"--cursor=My Cursor.png\"]", []string{"-f", "--cursor=My Cursor.png"}},
+		{"removal of a quoted value with spaces", []string{"-f", "--cursor=My Cursor.png"}, "['-\"--cursor=My Cursor.png\"']", []string{"-f"}},
+		{"dash value kept as is", []string{"-f"}, "['-\"']", []string{"-f", "-\""}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			overlay, _ := overlayConfiguration(DefaultConfiguration(), []byte("gamescope:\n    args: "+test.override+"\n"), "test")
+			merged := mergeList(test.current, overlay.Gamescope.Args, overlay.ListMerge["gamescope.args"])
+
+			if !slices.Equal(merged, test.expected) {
+				t.Errorf("merged %q, expected %q", merged, test.expected)
+			}
+		})
+	}
+}
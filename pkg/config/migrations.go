@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// CURRENT_CONFIG_VERSION is written to new files as config-version, files without it are version 0.
+const CURRENT_CONFIG_VERSION = 4
+
+// How a file is migrated, overlays have to stay sparse: a key they set beats the configuration
+type migrationTarget int
+
+// MIGRATE_OVERLAY reshapes overrides, profiles and included files in memory, adding no key
+const MIGRATE_OVERLAY migrationTarget = 0
+
+// MIGRATE_CONFIGURATION also gives the system-wide configuration the defaults of its version, in memory
+const MIGRATE_CONFIGURATION migrationTarget = 1
+
+// MIGRATE_USER_CONFIGURATION migrates config.yaml like MIGRATE_CONFIGURATION and writes it back, with a backup
+const MIGRATE_USER_CONFIGURATION migrationTarget = 2
+
+type configMigration struct {
+	version     int
+	description string
+	// Adds a key the file leaves out, keeping the behavior of its version, configurations only
+	addsDefault bool
+	apply       func(root *yaml.Node)
+}
+
+// Each migration brings a file from version-1 to version, they run in order.
+var configMigrations = []configMigration{
+	{
+		1,
+		"keep the ~/.plauncher and ~/.compatdata shortcuts that used to be always created",
+		true,
+		func(root *yaml.Node) {
+			if mappingValue(root, "home-shortcuts") != nil {
+				return
+			}
+
+			shortcuts := &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(shortcuts, "config", boolNode(true))
+			setMappingValue(shortcuts, "compatdata", boolNode(true))
+			setMappingValue(root, "home-shortcuts", shortcuts)
+		},
+	},
+	{
+		2,
+		"replace the ~/.compatdata shortcut with a folder of per-game prefix links in the same place",
+		false,
+		func(root *yaml.Node) {
+			shortcuts := mappingValue(root, "home-shortcuts")
+
+			if shortcuts == nil || shortcuts.Kind != yaml.MappingNode {
+				return
+			}
+
+			compatData := mappingValue(shortcuts, "compatdata")
+			deleteMappingValue(shortcuts, "compatdata")
+
+			if compatData != nil && compatData.Value == "true" {
+				setMappingValue(shortcuts, "prefix-links", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "~/" + COMPATDATA_SHORTCUT_NAME})
+			}
+		},
+	},
+	{
+		3,
+		"apply game recipes, which are on by default, unless the file already decides",
+		true,
+		func(root *yaml.Node) {
+			recipes := mappingValue(root, "recipes")
+
+			if recipes == nil {
+				recipes = &yaml.Node{Kind: yaml.MappingNode}
+				setMappingValue(root, "recipes", recipes)
+			}
+
+			if recipes.Kind == yaml.MappingNode && mappingValue(recipes, "enabled") == nil {
+				setMappingValue(recipes, "enabled", boolNode(true))
+			}
+		},
+	},
+	{
+		4,
+		"turn gamescope.hdr into a section, to make room for the HDR brightness and color settings",
+		false,
+		func(root *yaml.Node) {
+			gamescope := mappingValue(root, "gamescope")
+
+			if gamescope == nil || gamescope.Kind != yaml.MappingNode {
+				return
+			}
+
+			hdr := mappingValue(gamescope, "hdr")
+
+			if hdr == nil || hdr.Kind != yaml.ScalarNode {
+				return
+			}
+
+			enabled, _ := strconv.ParseBool(hdr.Value)
+			section := &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(section, "enabled", boolNode(enabled))
+			setMappingValue(gamescope, "hdr", section)
+		},
+	},
+}
+
+// Upgrades content to CURRENT_CONFIG_VERSION as target, backing up configurationFile before rewriting it for
+// MIGRATE_USER_CONFIGURATION
+func migrateConfigurationFile(configurationFile string, content []byte, target migrationTarget) []byte {
+	document := yaml.Node{}
+
+	if err := yaml.Unmarshal(content, &document); err != nil || len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return content
+	}
+
+	root := document.Content[0]
+	fileVersion := 0
+
+	if versionNode := mappingValue(root, "config-version"); versionNode != nil {
+		fileVersion, _ = strconv.Atoi(versionNode.Value)
+	}
+
+	if fileVersion >= CURRENT_CONFIG_VERSION {
+		return content
+	}
+
+	for _, migration := range configMigrations {
+		if migration.version <= fileVersion || (migration.addsDefault && target == MIGRATE_OVERLAY) {
+			continue
+		}
+
+		log.Printf("Migrating %s to config-version %d: %s\n", configurationFile, migration.version, migration.description)
+		migration.apply(root)
+	}
+
+	setMappingValue(root, "config-version", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(CURRENT_CONFIG_VERSION)})
+
+	migratedContent, err := yaml.Marshal(&document)
+
+	if err != nil {
+		log.Printf("Failed to migrate %s: %s\n", configurationFile, err)
+		return content
+	}
+
+	if target != MIGRATE_USER_CONFIGURATION {
+		return migratedContent
+	}
+
+	backupFile := fmt.Sprintf("%s.v%d.bak", configurationFile, fileVersion)
+
+	if err := system.FS.WriteFile(backupFile, content, DEFAULT_PERMISSION); err != nil {
+		log.Printf("Failed to back up %s, migrating in memory only: %s\n", configurationFile, err)
+		return migratedContent
+	}
+
+	if err := system.FS.WriteFile(configurationFile, migratedContent, DEFAULT_PERMISSION); err != nil {
+		log.Printf("Failed to write migrated %s, migrating in memory only: %s\n", configurationFile, err)
+	}
+
+	return migratedContent
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			if value.LineComment == "" {
+				value.LineComment = mapping.Content[i+1].LineComment
+			}
+
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func deleteMappingValue(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func boolNode(value bool) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// An override written before config-version existed
+const LEGACY_OVERRIDE = `gamescope:
+    enabled: true
+    hdr: true
+`
+
+func TestMigratedOverrideStaysSparse(t *testing.T) {
+	overrideFile := filepath.Join(t.TempDir(), "Foo.yaml")
+
+	if err := os.WriteFile(overrideFile, []byte(LEGACY_OVERRIDE), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	configuration := DefaultConfiguration()
+	configuration.Recipes.Enabled = false
+	configuration.HomeShortcuts = HomeShortcutsConfiguration{}
+
+	applyOverlayFile(&configuration, overrideFile, nil)
+
+	if configuration.Recipes.Enabled || configuration.HomeShortcuts.Config {
+		t.Errorf("the override brought in migration defaults: recipes %+v, home-shortcuts %+v", configuration.Recipes, configuration.HomeShortcuts)
+	}
+
+	if !configuration.Gamescope.Enabled || !configuration.Gamescope.Hdr.Enabled {
+		t.Errorf("the override was not applied migrated: %+v", configuration.Gamescope)
+	}
+
+	if content, _ := os.ReadFile(overrideFile); string(content) != LEGACY_OVERRIDE {
+		t.Errorf("the override was rewritten:\n%s", content)
+	}
+
+	if backups, _ := filepath.Glob(overrideFile + ".v*.bak"); len(backups) > 0 {
+		t.Errorf("the override was backed up: %v", backups)
+	}
+}
+
+func TestRecipesMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"no recipes section", "gamemode:\n    enabled: true\n", true},
+		{"recipes section without enabled", "recipes:\n    url: https://example.com/recipes.yaml\n", true},
+		{"recipes turned off", "recipes:\n    enabled: false\n", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configurationFile := filepath.Join(t.TempDir(), "config.yaml")
+
+			if err := os.WriteFile(configurationFile, []byte(test.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			configuration := DefaultConfiguration()
+			configuration.Recipes.Enabled = !test.expected
+			configuration = readConfigurationOver(configuration, configurationFile)
+
+			if configuration.Recipes.Enabled != test.expected {
+				t.Errorf("recipes.enabled is %t, expected %t", configuration.Recipes.Enabled, test.expected)
+			}
+		})
+	}
+}
+
+func TestLoadConfigurationLeavesSystemConfigurationAlone(t *testing.T) {
+	base := t.TempDir()
+	paths := Paths{
+		SystemConfigurationFile: filepath.Join(base, "etc", "config.yaml"),
+		ConfigurationFile:       filepath.Join(base, "config", "config.yaml"),
+	}
+
+	os.MkdirAll(filepath.Dir(paths.SystemConfigurationFile), 0o755)
+	os.MkdirAll(filepath.Dir(paths.ConfigurationFile), 0o755)
+
+	if err := os.WriteFile(paths.SystemConfigurationFile, []byte(LEGACY_OVERRIDE), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(paths.ConfigurationFile, []byte("config-version: 4\ngamemode:\n    enabled: false\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	configuration, systemConfiguration := LoadConfiguration(paths)
+
+	if !configuration.Gamescope.Hdr.Enabled || configuration.Gamemode.Enabled {
+		t.Errorf("files not layered: gamescope %+v, gamemode %+v", configuration.Gamescope, configuration.Gamemode)
+	}
+
+	if !systemConfiguration.Gamemode.Enabled {
+		t.Error("reading the user configuration changed the system-wide one")
+	}
+
+	if content, _ := os.ReadFile(paths.SystemConfigurationFile); string(content) != LEGACY_OVERRIDE {
+		t.Errorf("the system configuration was rewritten:\n%s", content)
+	}
+
+	if backups, _ := filepath.Glob(paths.SystemConfigurationFile + ".v*.bak"); len(backups) > 0 {
+		t.Errorf("the system configuration was backed up: %v", backups)
+	}
+}
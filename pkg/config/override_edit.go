@@ -0,0 +1,151 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// OverrideFile returns the user override file of game, a name or an appid.
+func OverrideFile(paths Paths, game string) string {
+	return gameOverrideFile(paths.OverridesFolder, game)
+}
+
+// SetOverrideValue sets the dotted key (e.g. gamescope.hdr.enabled) to the YAML value in overrideFile, creating it when missing.
+// Comments and key order of the file are kept, the file is left untouched when the result is not a valid configuration.
+func SetOverrideValue(overrideFile string, key string, value string) error {
+	content, err := os.ReadFile(overrideFile)
+
+	if os.IsNotExist(err) {
+		content = []byte(fmt.Sprintf("config-version: %d\n", CURRENT_CONFIG_VERSION))
+	} else if err != nil {
+		return err
+	}
+
+	document := yaml.Node{}
+
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("%s is not valid YAML: %s", overrideFile, err)
+	}
+
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	if err := setDocumentValue(&document, key, value); err != nil {
+		return err
+	}
+
+	newContent, err := yaml.Marshal(&document)
+
+	if err != nil {
+		return err
+	}
+
+	if err := validateConfigurationContent(newContent); err != nil {
+		return fmt.Errorf("Setting %s=%s would make %s invalid: %s", key, value, overrideFile, err)
+	}
+
+	return system.FS.WriteFile(overrideFile, newContent, DEFAULT_PERMISSION)
+}
+
+// Sets the dotted key to the YAML value in the top mapping of document, creating the sections on the way
+func setDocumentValue(document *yaml.Node, key string, value string) error {
+	valueDocument := yaml.Node{}
+
+	if err := yaml.Unmarshal([]byte(value), &valueDocument); err != nil || len(valueDocument.Content) == 0 {
+		return fmt.Errorf("Invalid value for %s: %s", key, value)
+	}
+
+	mapping := document.Content[0]
+	keys := strings.Split(key, ".")
+
+	for _, intermediateKey := range keys[:len(keys)-1] {
+		child := mappingValue(mapping, intermediateKey)
+
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(mapping, intermediateKey, child)
+		}
+
+		if child.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a section", intermediateKey)
+		}
+
+		mapping = child
+	}
+
+	setMappingValue(mapping, keys[len(keys)-1], valueDocument.Content[0])
+
+	return nil
+}
+
+// Turns key=value settings, as given to --set, into the content of an override file setting them
+func launchSettingsContent(settings []string) ([]byte, error) {
+	document := yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+
+	for _, setting := range settings {
+		key, value, found := strings.Cut(setting, "=")
+
+		if !found || key == "" {
+			return nil, fmt.Errorf("Expected key=value, got: %s", setting)
+		}
+
+		if err := setDocumentValue(&document, key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := yaml.Marshal(&document)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateConfigurationContent(content); err != nil {
+		return nil, fmt.Errorf("Invalid setting in %s: %s", strings.Join(settings, " "), err)
+	}
+
+	return content, nil
+}
+
+// CheckConfigurationFile reports why configurationFile, a configuration or an override, would be rejected: invalid
+// YAML, unknown keys or values of the wrong type, as ConfigurationErrors, or a missing include. Older config-versions
+// are checked as migrated, the file is not changed.
+func CheckConfigurationFile(configurationFile string) error {
+	content, err := os.ReadFile(configurationFile)
+
+	if err != nil {
+		return err
+	}
+
+	content = migrateConfigurationFile(configurationFile, content, MIGRATE_CONFIGURATION)
+
+	if problems := fileProblems(configurationFile, content); len(problems) > 0 {
+		return ConfigurationErrors(problems)
+	}
+
+	_, err = includedFiles(configurationFile, content)
+
+	return err
+}
+
+func validateConfigurationContent(content []byte) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	configuration := Configuration{}
+
+	if err := decoder.Decode(&configuration); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+package config
+
+import (
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// ApplyGameOverrides merges the id and name override files found in gameOverridesFolders on top of configuration,
+// lowest precedence first (see OverridesConfiguration), and records in Provenance the file that set each key.
+// Keys missing from an override file keep their current value. Only the files of the last folder, the user
+// one, are rewritten by migrations, the others are migrated in memory.
+func ApplyGameOverrides(configuration *Configuration, gameOverridesFolders ...string) {
+	precedence := overridePrecedence(configuration.Overrides.Precedence)
+
+	for i := len(precedence) - 1; i >= 0; i-- {
+		game := configuration.Props[precedence[i]]
+
+		if game == "" {
+			continue
+		}
+
+		for _, gameOverridesFolder := range gameOverridesFolders {
+			overrideFile := gameOverrideFile(gameOverridesFolder, game)
+
+			if _, err := os.Stat(overrideFile); os.IsNotExist(err) {
+				continue
+			}
+
+			log.Printf("Found game %s override file: %s\n", precedence[i], overrideFile)
+
+			applyOverlayFile(configuration, overrideFile, nil)
+		}
+	}
+}
+
+// ApplyLaunchOverlay merges overlayFile, in the format of the override files, on top of configuration for
+// this launch only, above every game override.
+func ApplyLaunchOverlay(configuration *Configuration, overlayFile string) {
+	log.Printf("Applying launch overlay: %s\n", overlayFile)
+
+	applyOverlayFile(configuration, overlayFile, nil)
+}
+
+// ApplyLaunchSettings merges the key=value settings given with --set on top of configuration, above the launch overlay.
+func ApplyLaunchSettings(configuration *Configuration, settings []string) error {
+	return applySettings(configuration, settings, "--"+SET_FLAG)
+}
+
+// ApplyLaunchEnvironment sets the KEY=VALUE variables given with --env in the environment of configuration, above
+// everything else. It is applied again right before the launch, over the variables wrappers add.
+func ApplyLaunchEnvironment(configuration *Configuration) {
+	for _, variable := range configuration.FlagValues[ENV_FLAG] {
+		if key, value, found := strings.Cut(variable, "="); found {
+			configuration.Environment[key] = value
+		}
+	}
+}
+
+// Merges key=value settings, given by source, on top of configuration
+func applySettings(configuration *Configuration, settings []string, source string) error {
+	content, err := launchSettingsContent(settings)
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Applying settings from %s: %s\n", source, strings.Join(settings, " "))
+
+	overlay, keys := overlayConfiguration(*configuration, content, source)
+	applyOverlay(configuration, overlay, keys, source)
+
+	return nil
+}
+
+// Merges overlay, read from source, into configuration and records source as the origin of the keys it sets
+func applyOverlay(configuration *Configuration, overlay Configuration, keys []string, source string) {
+	ApplyConfigOverrides(configuration, overlay)
+
+	for _, key := range keys {
+		if overlay.ListMerge[key] == LIST_MERGE_REPLACE {
+			delete(configuration.Provenance, key)
+		}
+
+		recordProvenance(configuration, key, source)
+	}
+}
+
+// Returns precedence without unknown or repeated entries, the default order when nothing is left
+func overridePrecedence(precedence []string) []string {
+	valid := make([]string, 0, 2)
+
+	for _, entry := range precedence {
+		if entry != OVERRIDE_BY_ID && entry != OVERRIDE_BY_NAME {
+			log.Printf("Ignoring unknown overrides.precedence entry: %s\n", entry)
+			continue
+		}
+
+		if !slices.Contains(valid, entry) {
+			valid = append(valid, entry)
+		}
+	}
+
+	if len(valid) == 0 {
+		return DefaultConfiguration().Overrides.Precedence
+	}
+
+	return valid
+}
+
+// Reads content, in the format of the override files, on top of a copy of base
+func overlayConfiguration(base Configuration, content []byte, source string) (Configuration, []string) {
+	overlay := base
+	overlay.Environment = maps.Clone(base.Environment)
+	overlay.Gamescope.Args = slices.Clone(base.Gamescope.Args)
+	overlay.Umu.Args = slices.Clone(base.Umu.Args)
+	overlay.Wine.Fonts = slices.Clone(base.Wine.Fonts)
+	overlay.PreScripts = slices.Clone(base.PreScripts)
+	overlay.PostScripts = slices.Clone(base.PostScripts)
+	overlay.Prepare = slices.Clone(base.Prepare)
+
+	if err := unmarshalConfiguration(content, &overlay); err != nil {
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "Invalid override %s: %s\n", source, err)
+	}
+
+	overlay.ListMerge = readListMergeStrategies(content)
+
+	document := yaml.Node{}
+	yaml.Unmarshal(content, &document)
+
+	keys := make([]string, 0)
+
+	if len(document.Content) > 0 {
+		keys = collectKeys(document.Content[0], "", keys)
+	}
+
+	return overlay, keys
+}
+
+// Keys the overrides do not merge, they stay attributed to the global configuration
+var notOverridableKeys = []string{"config-version", "extends", "include", "conditional", "home-shortcuts", "overrides", "wake", "only-for", "skip-for", "metadata"}
+
+func collectKeys(node *yaml.Node, prefix string, keys []string) []string {
+	if node.Kind != yaml.MappingNode {
+		return append(keys, prefix)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+
+		if prefix == "" && slices.Contains(notOverridableKeys, key) {
+			continue
+		}
+
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		keys = collectKeys(node.Content[i+1], key, keys)
+	}
+
+	return keys
+}
+
+// List keys are merged, so every file contributing to them is kept
+func recordProvenance(configuration *Configuration, key string, source string) {
+	if configuration.Provenance == nil {
+		configuration.Provenance = make(map[string]string)
+	}
+
+	if current, exists := configuration.Provenance[key]; exists && isListKey(key) && !strings.Contains(current, source) {
+		configuration.Provenance[key] = current + ", " + source
+		return
+	}
+
+	configuration.Provenance[key] = source
+}
+
+func isListKey(key string) bool {
+	return key == "pre-scripts" || key == "post-scripts" || key == "prepare" || key == "only-for" || key == "skip-for" || key == "wine.fonts" || strings.HasSuffix(key, ".args")
+}
+
+// ExplainedKey is a configuration key with its effective value and the file that set it.
+type ExplainedKey struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// ExplainConfiguration lists every key of configuration, sources default to globalSource.
+func ExplainConfiguration(configuration Configuration, globalSource string) []ExplainedKey {
+	document := yaml.Node{}
+
+	if err := document.Encode(configuration); err != nil {
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "Failed to encode configuration: %s\n", err)
+	}
+
+	explained := make([]ExplainedKey, 0)
+
+	for _, key := range collectKeys(&document, "", make([]string, 0)) {
+		source, exists := configuration.Provenance[key]
+
+		if !exists {
+			source = globalSource
+		}
+
+		explained = append(explained, ExplainedKey{key, explainValue(&document, key), source})
+	}
+
+	return explained
+}
+
+func explainValue(document *yaml.Node, key string) string {
+	node := document
+
+	for _, part := range strings.SplitN(key, ".", 2) {
+		if node = mappingValue(node, part); node == nil {
+			return ""
+		}
+	}
+
+	if node.Kind == yaml.SequenceNode {
+		node.Style = yaml.FlowStyle
+	}
+
+	value, _ := yaml.Marshal(node)
+
+	return strings.TrimSpace(string(value))
+}
+
+// FindOverrideFiles returns the existing override files of games, names or appids, in every override folder.
+func FindOverrideFiles(paths Paths, games ...string) []string {
+	overrideFiles := make([]string, 0)
+
+	for _, folder := range OverrideFolders(paths) {
+		for _, game := range games {
+			if game == "" {
+				continue
+			}
+
+			overrideFile := gameOverrideFile(folder, game)
+
+			if _, err := os.Stat(overrideFile); err == nil && !slices.Contains(overrideFiles, overrideFile) {
+				overrideFiles = append(overrideFiles, overrideFile)
+			}
+		}
+	}
+
+	return overrideFiles
+}
+
+// Returns the override file of game in gameOverridesFolder, falling back to the raw name earlier versions used
+func gameOverrideFile(gameOverridesFolder string, game string) string {
+	overrideFile := filepath.Join(gameOverridesFolder, GameSlug(game)+".yaml")
+	rawOverrideFile := filepath.Join(gameOverridesFolder, game+".yaml")
+
+	if _, err := os.Stat(overrideFile); os.IsNotExist(err) && filepath.Dir(rawOverrideFile) == gameOverridesFolder {
+		if _, err := os.Stat(rawOverrideFile); err == nil {
+			return rawOverrideFile
+		}
+	}
+
+	return overrideFile
+}
+
+// ProcessSpecialFlags handles the --save-name and --save-id flags.
+func ProcessSpecialFlags(specialFlags map[string]bool, configuration Configuration, gameOverridesFolder string) {
+	if _, exists := specialFlags["save-name"]; exists {
+		createNameOverrideFile(configuration, gameOverridesFolder)
+	}
+
+	if _, exists := specialFlags["save-id"]; exists {
+		createIdOverrideFile(configuration, gameOverridesFolder)
+	}
+}
+
+func createNameOverrideFile(configuration Configuration, gameOverridesFolder string) {
+	if name, exists := configuration.Props["name"]; exists {
+		CreateOverrideFile(configuration, gameOverrideFile(gameOverridesFolder, name))
+	}
+}
+
+func createIdOverrideFile(configuration Configuration, gameOverridesFolder string) {
+	if id, exists := configuration.Props["id"]; exists {
+		CreateOverrideFile(configuration, gameOverrideFile(gameOverridesFolder, id))
+	}
+}
+
+// CreateOverrideFile writes configuration, minus launch specific variables, to overrideFile unless it already exists.
+func CreateOverrideFile(configuration Configuration, overrideFile string) {
+	if _, err := os.Stat(overrideFile); os.IsNotExist(err) {
+		configuration.Environment = maps.Clone(configuration.Environment)
+		stripUnecessaryData(&configuration)
+
+		yamlData, err := yaml.Marshal(configuration)
+
+		if err != nil {
+			system.Fatalf(system.EXIT_CONFIG_ERROR, "Failed to create configuration yaml: %s\n", err)
+		}
+
+		log.Printf("Creating override file in %s, with value: \n%s\n", overrideFile, yamlData)
+
+		system.FS.WriteFile(overrideFile, yamlData, DEFAULT_PERMISSION)
+	}
+}
+
+func stripUnecessaryData(configuration *Configuration) {
+	delete(configuration.Environment, "STEAM_COMPAT_DATA_PATH")
+	delete(configuration.Environment, "MANGOHUD")
+	delete(configuration.Environment, "DISABLE_MANGOAPP")
+	delete(configuration.Environment, "MANGOHUD_CONFIGFILE")
+	delete(configuration.Environment, "PROTON_LOG")
+	delete(configuration.Environment, "PROTON_LOG_DIR")
+	delete(configuration.Environment, "WINEDEBUG")
+}
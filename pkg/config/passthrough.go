@@ -0,0 +1,32 @@
+package config
+
+import (
+	"slices"
+)
+
+// PASSTHROUGH_FLAG marks a launch where the game command runs untouched.
+const PASSTHROUGH_FLAG = "passthrough"
+
+// ExcludedGame reports why the game of configuration is left out by only-for/skip-for, entries being appids or names.
+func ExcludedGame(configuration Configuration) (string, bool) {
+	matches := func(entries []string) bool {
+		return slices.ContainsFunc(entries, func(entry string) bool {
+			return entry != "" && (entry == configuration.Props["id"] || entry == configuration.Props["name"])
+		})
+	}
+
+	if matches(configuration.SkipFor) {
+		return "listed in skip-for", true
+	}
+
+	if len(configuration.OnlyFor) > 0 && !matches(configuration.OnlyFor) {
+		return "not listed in only-for", true
+	}
+
+	return "", false
+}
+
+// IsPassthrough reports whether the game command must run untouched, from --passthrough or passthrough: true.
+func IsPassthrough(configuration Configuration) bool {
+	return configuration.Passthrough || configuration.SpecialFlags[PASSTHROUGH_FLAG]
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const SYSTEM_CONFIG_FOLDER = "/etc/" + APP_NAME
+
+// Paths gathers every folder and file plauncher reads from or writes to.
+type Paths struct {
+	HomeDir                  string
+	UserConfigDir            string
+	UserCacheDir             string
+	UserDataDir              string
+	UserStateDir             string
+	AppConfigFolder          string
+	AppDataFolder            string
+	AppStateFolder           string
+	CompatDataBase           string
+	MirrorsFolder            string
+	CommunityOverridesFolder string
+	AppNamesCacheFolder      string
+	UmuIdsCacheFolder        string
+	LaunchesFolder           string
+	LogsFolder               string
+	ControlFolder            string
+	ModsStateFolder          string
+	ScriptsFolder            string
+	OverridesFolder          string
+	TemplatesFolder          string
+	ProfilesFolder           string
+	ConfigurationFile        string
+	AnticheatFile            string
+	RecipesFile              string
+	FetchedRecipesFile       string
+	DebugFile                string
+
+	SystemConfigFolder      string
+	SystemConfigurationFile string
+	SystemOverridesFolder   string
+	SystemTemplatesFolder   string
+	SystemProfilesFolder    string
+	SystemAnticheatFile     string
+	SystemRecipesFile       string
+}
+
+// ResolvePaths computes Paths from the user HOME and XDG folders.
+func ResolvePaths() (Paths, error) {
+	homeDir, err := os.UserHomeDir()
+
+	if err != nil {
+		return Paths{}, fmt.Errorf("Failed to determine user HOME folder: %s", err)
+	}
+
+	userCacheDir, err := os.UserCacheDir()
+
+	if err != nil {
+		return Paths{}, fmt.Errorf("Failed to determine user CACHE folder: %s", err)
+	}
+
+	userConfigDir, err := os.UserConfigDir()
+
+	if err != nil {
+		return Paths{}, fmt.Errorf("Failed to determine user CONFIG folder: %s", err)
+	}
+
+	userDataDir := determineBaseDataDir(homeDir)
+	appConfigFolder := filepath.Join(userConfigDir, APP_NAME)
+	appDataFolder := filepath.Join(userDataDir, APP_NAME)
+	userStateDir := determineBaseStateDir(homeDir)
+	appStateFolder := filepath.Join(userStateDir, APP_NAME)
+
+	return Paths{
+		HomeDir:                  homeDir,
+		UserConfigDir:            userConfigDir,
+		UserCacheDir:             userCacheDir,
+		UserDataDir:              userDataDir,
+		UserStateDir:             userStateDir,
+		AppConfigFolder:          appConfigFolder,
+		AppDataFolder:            appDataFolder,
+		AppStateFolder:           appStateFolder,
+		CompatDataBase:           filepath.Join(appDataFolder, "compatdata"),
+		MirrorsFolder:            filepath.Join(appDataFolder, "mirrors"),
+		CommunityOverridesFolder: filepath.Join(appDataFolder, "community-overrides"),
+		AppNamesCacheFolder:      filepath.Join(userCacheDir, APP_NAME, "appnames"),
+		UmuIdsCacheFolder:        filepath.Join(userCacheDir, APP_NAME, "umuids"),
+		LaunchesFolder:           filepath.Join(appStateFolder, "launches"),
+		LogsFolder:               filepath.Join(appStateFolder, "logs"),
+		ControlFolder:            filepath.Join(determineBaseRuntimeDir(appStateFolder), "control"),
+		ModsStateFolder:          filepath.Join(appStateFolder, "mods"),
+		ScriptsFolder:            filepath.Join(appConfigFolder, "scripts"),
+		OverridesFolder:          filepath.Join(appConfigFolder, "overrides"),
+		TemplatesFolder:          filepath.Join(appConfigFolder, "templates"),
+		ProfilesFolder:           filepath.Join(appConfigFolder, "profiles"),
+		ConfigurationFile:        filepath.Join(appConfigFolder, "config.yaml"),
+		AnticheatFile:            filepath.Join(appConfigFolder, "anticheat.yaml"),
+		RecipesFile:              filepath.Join(appConfigFolder, "recipes.yaml"),
+		FetchedRecipesFile:       filepath.Join(appDataFolder, "recipes.yaml"),
+		DebugFile:                filepath.Join(appStateFolder, "debug.log"),
+
+		SystemConfigFolder:      SYSTEM_CONFIG_FOLDER,
+		SystemConfigurationFile: filepath.Join(SYSTEM_CONFIG_FOLDER, "config.yaml"),
+		SystemOverridesFolder:   filepath.Join(SYSTEM_CONFIG_FOLDER, "overrides"),
+		SystemTemplatesFolder:   filepath.Join(SYSTEM_CONFIG_FOLDER, "templates"),
+		SystemProfilesFolder:    filepath.Join(SYSTEM_CONFIG_FOLDER, "profiles"),
+		SystemAnticheatFile:     filepath.Join(SYSTEM_CONFIG_FOLDER, "anticheat.yaml"),
+		SystemRecipesFile:       filepath.Join(SYSTEM_CONFIG_FOLDER, "recipes.yaml"),
+	}, nil
+}
+
+// GameLogsFolder returns the folder collecting the logs of the game being launched.
+func GameLogsFolder(paths Paths, configuration Configuration) string {
+	for _, key := range []string{"name", "id"} {
+		if value := configuration.Props[key]; value != "" {
+			return filepath.Join(paths.LogsFolder, GameSlug(value))
+		}
+	}
+
+	return filepath.Join(paths.LogsFolder, "unknown")
+}
+
+// CompatDataBase returns the folder holding the prefix of the game being launched, compatdata.base when set.
+func CompatDataBase(paths Paths, configuration Configuration) string {
+	if configuration.CompatData.Base != "" {
+		return ExpandUserPath(configuration.CompatData.Base, paths.HomeDir)
+	}
+
+	return paths.CompatDataBase
+}
+
+// CompatDataBases returns the default prefix folder along with every compatdata.base set in the configuration
+// or in a game override, so moved prefixes can be listed.
+func CompatDataBases(paths Paths) []string {
+	bases := []string{paths.CompatDataBase}
+	files := []string{paths.SystemConfigurationFile, paths.ConfigurationFile}
+
+	for _, folder := range append(ProfileFolders(paths), OverrideFolders(paths)...) {
+		overrideFiles, _ := filepath.Glob(filepath.Join(folder, "*.yaml"))
+		files = append(files, overrideFiles...)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+
+		if err != nil {
+			continue
+		}
+
+		partial := struct {
+			CompatData CompatDataConfiguration `yaml:"compatdata"`
+		}{}
+
+		if yaml.Unmarshal(content, &partial) != nil || partial.CompatData.Base == "" {
+			continue
+		}
+
+		if base := ExpandUserPath(partial.CompatData.Base, paths.HomeDir); !slices.Contains(bases, base) {
+			bases = append(bases, base)
+		}
+	}
+
+	return bases
+}
+
+// ExpandUserPath expands environment variables and a leading ~ in path.
+func ExpandUserPath(path string, homeDir string) string {
+	path = os.ExpandEnv(path)
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(homeDir, path[1:])
+	}
+
+	return path
+}
+
+// MakeSureFoldersExist creates every missing folder.
+func MakeSureFoldersExist(folders ...string) {
+	for _, folder := range folders {
+		if _, err := os.Stat(folder); os.IsNotExist(err) {
+			system.FS.MkdirAll(folder, DEFAULT_PERMISSION)
+		}
+	}
+}
+
+func determineBaseDataDir(home string) string {
+	xdgDataHomeValue, xdgDataHomeExists := os.LookupEnv(ENV_XDG_DATA_HOME)
+
+	if xdgDataHomeExists {
+		return xdgDataHomeValue
+	}
+
+	return filepath.Join(home, ".local", "share")
+}
+
+func determineBaseStateDir(home string) string {
+	xdgStateHomeValue, xdgStateHomeExists := os.LookupEnv(ENV_XDG_STATE_HOME)
+
+	if xdgStateHomeExists {
+		return xdgStateHomeValue
+	}
+
+	return filepath.Join(home, ".local", "state")
+}
+
+// Sockets belong in the runtime folder, the state folder stands in for it outside of a login session
+func determineBaseRuntimeDir(appStateFolder string) string {
+	if xdgRuntimeDirValue, xdgRuntimeDirExists := os.LookupEnv(ENV_XDG_RUNTIME_DIR); xdgRuntimeDirExists && xdgRuntimeDirValue != "" {
+		return filepath.Join(xdgRuntimeDirValue, APP_NAME)
+	}
+
+	return appStateFolder
+}
+
+// MigrateLegacyStateFiles moves the debug log and launch records from the folders
+// older versions kept them in (XDG data and cache) to the XDG state folder.
+func MigrateLegacyStateFiles(paths Paths) {
+	legacyLocations := map[string]string{
+		filepath.Join(paths.AppDataFolder, "debug.log"):         paths.DebugFile,
+		filepath.Join(paths.UserCacheDir, APP_NAME, "launches"): paths.LaunchesFolder,
+	}
+
+	for legacyPath, newPath := range legacyLocations {
+		if _, err := os.Lstat(legacyPath); os.IsNotExist(err) {
+			continue
+		}
+
+		if _, err := os.Lstat(newPath); !os.IsNotExist(err) {
+			continue
+		}
+
+		if err := system.FS.Rename(legacyPath, newPath); err != nil {
+			log.Printf("Failed to migrate %s to %s: %s\n", legacyPath, newPath, err)
+		}
+	}
+}
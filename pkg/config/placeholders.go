@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"slices"
+)
+
+// PLACEHOLDERS are the variables plauncher fills in itself once the game and its prefix are known, in environment
+// values and in the gamescope and umu arguments
+var PLACEHOLDERS = []string{"GAME_NAME", "GAME_ID", "GAME_SLUG", "PREFIX", "CONFIG_DIR"}
+
+// Expands the variables of the process environment in value, the placeholders being left for the launch
+func expandEnvKeepingPlaceholders(value string) string {
+	return os.Expand(value, func(name string) string {
+		if slices.Contains(PLACEHOLDERS, name) {
+			return "${" + name + "}"
+		}
+
+		return os.Getenv(name)
+	})
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ProfileFolders returns the folders profiles are read from, the system-wide one first so the user one wins.
+func ProfileFolders(paths Paths) []string {
+	return []string{paths.SystemProfilesFolder, paths.ProfilesFolder}
+}
+
+// ApplyProfile merges the profile named profile, selected with --profile, on top of configuration, above the
+// global configuration and below the game overrides. A profile is profiles/<name>.yaml, in the format of the
+// overrides, in the configuration folder or the system-wide one; when both exist the user one is merged last.
+func ApplyProfile(configuration *Configuration, paths Paths, profile string) error {
+	if profile == "" || strings.HasPrefix(profile, ".") || strings.ContainsRune(profile, filepath.Separator) {
+		return fmt.Errorf("Invalid profile name: %s", profile)
+	}
+
+	folders := ProfileFolders(paths)
+	found := false
+
+	for _, folder := range folders {
+		profileFile := filepath.Join(folder, profile+".yaml")
+
+		if _, err := os.Stat(profileFile); err != nil {
+			continue
+		}
+
+		log.Printf("Applying profile %s: %s\n", profile, profileFile)
+
+		applyOverlayFile(configuration, profileFile, nil)
+		found = true
+	}
+
+	if found {
+		return nil
+	}
+
+	if profiles := ListProfiles(paths); len(profiles) > 0 {
+		return fmt.Errorf("Unknown profile %s, expected one of: %s", profile, strings.Join(profiles, ", "))
+	}
+
+	return fmt.Errorf("Unknown profile %s, no profile exists in %s", profile, paths.ProfilesFolder)
+}
+
+// ListProfiles returns the names of the profiles found in the profile folders, sorted.
+func ListProfiles(paths Paths) []string {
+	profiles := make([]string, 0)
+
+	for _, folder := range ProfileFolders(paths) {
+		profileFiles, _ := filepath.Glob(filepath.Join(folder, "*.yaml"))
+
+		for _, profileFile := range profileFiles {
+			profiles = append(profiles, strings.TrimSuffix(filepath.Base(profileFile), ".yaml"))
+		}
+	}
+
+	slices.Sort(profiles)
+
+	return slices.Compact(profiles)
+}
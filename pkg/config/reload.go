@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CONFIGURATION_RELOAD_INTERVAL is how often WatchConfiguration looks for saved changes of the configuration
+const CONFIGURATION_RELOAD_INTERVAL = 2 * time.Second
+
+// WatchConfiguration sends on reload every time the configuration, profiles or overrides are saved, added or
+// removed, and every time the process gets SIGHUP, until stop is closed. Long running commands use it to pick up
+// edits without a restart.
+func WatchConfiguration(paths Paths, reload chan<- struct{}, stop <-chan struct{}) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+
+	changes := time.NewTicker(CONFIGURATION_RELOAD_INTERVAL)
+	defer changes.Stop()
+
+	filesState := configurationFilesState(paths)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-hangups:
+		case <-changes.C:
+			if configurationFilesState(paths) == filesState {
+				continue
+			}
+		}
+
+		filesState = configurationFilesState(paths)
+
+		select {
+		case reload <- struct{}{}:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// ReloadConfiguration is LoadConfiguration for long running commands: the configuration files are checked first,
+// so that an invalid edit is returned as an error and the previous configuration can be kept, where a launch stops.
+func ReloadConfiguration(paths Paths) (Configuration, error) {
+	for _, file := range []string{paths.SystemConfigurationFile, paths.ConfigurationFile} {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			continue
+		}
+
+		if err := CheckConfigurationFile(file); err != nil {
+			return Configuration{}, err
+		}
+	}
+
+	configuration, _ := LoadConfiguration(paths)
+
+	return configuration, nil
+}
+
+// The modification times and sizes of the configuration, profiles and overrides, changing when one of them is
+// saved, added or removed
+func configurationFilesState(paths Paths) string {
+	files := []string{paths.SystemConfigurationFile, paths.ConfigurationFile}
+
+	for _, folder := range append(ProfileFolders(paths), OverrideFolders(paths)...) {
+		folderFiles, _ := filepath.Glob(filepath.Join(folder, "*.yaml"))
+		files = append(files, folderFiles...)
+	}
+
+	var state strings.Builder
+
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			fmt.Fprintf(&state, "%s %d %d\n", file, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+
+	return state.String()
+}
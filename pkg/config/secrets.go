@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const SECRET_TAG = "!secret"
+
+const SECRET_PROVIDER_FILE = "file"
+const SECRET_PROVIDER_PASS = "pass"
+const SECRET_PROVIDER_SECRET_SERVICE = "secret-service"
+
+const DEFAULT_SECRETS_FILE = "secrets.yaml"
+
+// SecretsConfiguration is where the environment values written as !secret NAME are looked up, right before the
+// launch: in File, a YAML mapping of names to values kept out of shared dotfiles (relative to the configuration
+// folder), in pass or in the freedesktop Secret Service. NAME may pick another provider, e.g. !secret pass:games/epic.
+type SecretsConfiguration struct {
+	Provider string `yaml:"provider"`
+	File     string `yaml:"file"`
+}
+
+// SecretReference is a !secret value of the environment
+type SecretReference struct {
+	Provider string
+	Name     string
+}
+
+// ParseSecretReference reads value as a !secret reference, the provider defaulting to defaultProvider, or to the
+// secrets file when it is empty. It reports false for values that are not one.
+func ParseSecretReference(value string, defaultProvider string) (SecretReference, bool) {
+	reference, found := strings.CutPrefix(value, SECRET_TAG+" ")
+
+	if !found {
+		return SecretReference{}, false
+	}
+
+	if defaultProvider == "" {
+		defaultProvider = SECRET_PROVIDER_FILE
+	}
+
+	reference = strings.TrimSpace(reference)
+
+	for _, provider := range SecretProviders() {
+		if name, found := strings.CutPrefix(reference, provider+":"); found {
+			return SecretReference{provider, name}, true
+		}
+	}
+
+	return SecretReference{defaultProvider, reference}, true
+}
+
+// SecretProviders lists the providers secrets can be looked up in.
+func SecretProviders() []string {
+	return []string{SECRET_PROVIDER_FILE, SECRET_PROVIDER_PASS, SECRET_PROVIDER_SECRET_SERVICE}
+}
+
+func (reference SecretReference) String() string {
+	return fmt.Sprintf("%s %s:%s", SECRET_TAG, reference.Provider, reference.Name)
+}
+
+// Decodes content into out like yaml.Unmarshal, keeping the !secret tags, which yaml.v3 drops on strings, as values
+// starting with SECRET_TAG
+func unmarshalConfiguration(content []byte, out any) error {
+	document := yaml.Node{}
+
+	if err := yaml.Unmarshal(content, &document); err != nil || len(document.Content) == 0 {
+		return err
+	}
+
+	markSecrets(&document)
+
+	return document.Decode(out)
+}
+
+func markSecrets(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == SECRET_TAG {
+		node.Tag = "!!str"
+		node.Value = SECRET_TAG + " " + node.Value
+	}
+
+	for _, child := range node.Content {
+		markSecrets(child)
+	}
+}
+
+// Lists the !secret tags of content, read from file, outside of the environment values, where nothing resolves them
+func secretProblems(file string, content []byte) []ConfigurationProblem {
+	document := yaml.Node{}
+
+	if yaml.Unmarshal(content, &document) != nil || len(document.Content) == 0 {
+		return nil
+	}
+
+	return misplacedSecrets(file, document.Content[0], "", false)
+}
+
+// The problems of node, the value of key, inEnvironment when it is a variable of the environment
+func misplacedSecrets(file string, node *yaml.Node, key string, inEnvironment bool) []ConfigurationProblem {
+	problems := make([]ConfigurationProblem, 0)
+
+	if node.Tag == SECRET_TAG && !inEnvironment {
+		problems = append(problems, ConfigurationProblem{file, node.Line, fmt.Sprintf("%s %s: secrets are only looked up in environment values", SECRET_TAG, node.Value)})
+	}
+
+	for index, child := range node.Content {
+		if node.Kind != yaml.MappingNode {
+			problems = append(problems, misplacedSecrets(file, child, "", false)...)
+		} else if index%2 == 1 {
+			problems = append(problems, misplacedSecrets(file, child, node.Content[index-1].Value, key == "environment")...)
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,60 @@
+package config
+
+import "testing"
+
+func TestParseSecretReference(t *testing.T) {
+	tests := []struct {
+		name            string
+		value           string
+		defaultProvider string
+		expected        SecretReference
+		isSecret        bool
+	}{
+		{"plain value", "token", "", SecretReference{}, false},
+		{"tag without a space", "!secrettoken", "", SecretReference{}, false},
+		{"default provider", "!secret token", "", SecretReference{SECRET_PROVIDER_FILE, "token"}, true},
+		{"configured provider", "!secret token", SECRET_PROVIDER_PASS, SecretReference{SECRET_PROVIDER_PASS, "token"}, true},
+		{"provider in the name", "!secret pass:games/epic", SECRET_PROVIDER_FILE, SecretReference{SECRET_PROVIDER_PASS, "games/epic"}, true},
+		{"secret service", "!secret secret-service:epic", "", SecretReference{SECRET_PROVIDER_SECRET_SERVICE, "epic"}, true},
+		{"unknown provider stays in the name", "!secret vault:epic", "", SecretReference{SECRET_PROVIDER_FILE, "vault:epic"}, true},
+		{"name with spaces", "!secret  epic games ", "", SecretReference{SECRET_PROVIDER_FILE, "epic games"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reference, isSecret := ParseSecretReference(test.value, test.defaultProvider)
+
+			if isSecret != test.isSecret || reference != test.expected {
+				t.Errorf("got %+v, %t, expected %+v, %t", reference, isSecret, test.expected, test.isSecret)
+			}
+		})
+	}
+}
+
+func TestUnmarshalConfigurationKeepsSecretTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"secret", "environment:\n    TOKEN: !secret token\n", "!secret token"},
+		{"secret with a provider", "environment:\n    TOKEN: !secret pass:games/epic\n", "!secret pass:games/epic"},
+		{"quoted secret with spaces", "environment:\n    TOKEN: !secret \"epic games\"\n", "!secret epic games"},
+		{"plain value", "environment:\n    TOKEN: token\n", "token"},
+		{"string tag", "environment:\n    TOKEN: !!str token\n", "token"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configuration := Configuration{}
+
+			if err := unmarshalConfiguration([]byte(test.content), &configuration); err != nil {
+				t.Fatal(err)
+			}
+
+			if configuration.Environment["TOKEN"] != test.expected {
+				t.Errorf("TOKEN is %q, expected %q", configuration.Environment["TOKEN"], test.expected)
+			}
+		})
+	}
+}
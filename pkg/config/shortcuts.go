@@ -0,0 +1,55 @@
+package config
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const CONFIG_SHORTCUT_NAME = ".plauncher"
+const COMPATDATA_SHORTCUT_NAME = ".compatdata"
+
+// CreateHomeShortcut (re)creates the symlink name in homeDir pointing at target.
+func CreateHomeShortcut(homeDir string, name string, target string) {
+	shortcut := filepath.Join(homeDir, name)
+
+	if _, err := os.Lstat(shortcut); !os.IsNotExist(err) {
+		system.FS.Remove(shortcut)
+	}
+
+	if err := system.FS.Symlink(target, shortcut); err != nil {
+		log.Printf("Failed to create shortcut %s: %s\n", shortcut, err)
+	}
+}
+
+// RemoveHomeShortcuts removes the HOME shortcuts that still point into plauncher folders and returns them.
+// .compatdata is only a shortcut for versions that linked it to the whole compat data folder.
+func RemoveHomeShortcuts(paths Paths) []string {
+	removed := make([]string, 0)
+
+	shortcuts := map[string]string{
+		CONFIG_SHORTCUT_NAME:     paths.AppConfigFolder,
+		COMPATDATA_SHORTCUT_NAME: paths.AppDataFolder,
+	}
+
+	for name, ownedFolder := range shortcuts {
+		shortcut := filepath.Join(paths.HomeDir, name)
+		target, err := os.Readlink(shortcut)
+
+		if err != nil || !strings.HasPrefix(filepath.Clean(target), ownedFolder) {
+			continue
+		}
+
+		if err := system.FS.Remove(shortcut); err != nil {
+			log.Printf("Failed to remove shortcut %s: %s\n", shortcut, err)
+			continue
+		}
+
+		removed = append(removed, shortcut)
+	}
+
+	return removed
+}
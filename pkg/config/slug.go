@@ -0,0 +1,112 @@
+package config
+
+import (
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Longest slug in bytes, leaving room for suffixes like .yaml or .copying within NAME_MAX
+const MAX_SLUG_LENGTH = 200
+
+const UNKNOWN_GAME_SLUG = "unknown"
+
+// Unicode punctuation with a plain ASCII look-alike
+var slugReplacements = strings.NewReplacer(
+	"‘", "'", "’", "'", "ʼ", "'",
+	"“", "", "”", "",
+	"–", "-", "—", "-",
+	"…", "...",
+)
+
+// NormalizeGameName cleans up a game name as resolvers return it: HTML entities some of them leave in, letters
+// sent decomposed, invisible characters and stray whitespace. Names that are already clean are left untouched.
+func NormalizeGameName(name string) string {
+	// Some stores and file systems send letters decomposed into a base letter and a combining mark
+	name = norm.NFC.String(html.UnescapeString(name))
+	normalized := make([]rune, 0, len(name))
+
+	for _, char := range name {
+		switch {
+		case unicode.Is(unicode.Cf, char):
+			// zero width spaces and joiners, byte order marks
+		case unicode.IsControl(char):
+			normalized = append(normalized, ' ')
+		default:
+			normalized = append(normalized, char)
+		}
+	}
+
+	return strings.Join(strings.Fields(string(normalized)), " ")
+}
+
+// GameSlug turns a game name into a file name usable for its prefix, override and other per game files.
+// Names that are already safe are returned as they are, so existing files keep their names.
+func GameSlug(name string) string {
+	name = slugReplacements.Replace(NormalizeGameName(name))
+	slug := strings.Builder{}
+
+	for _, char := range name {
+		// Fullwidth forms of ASCII, common in CJK titles, so both spellings share their files
+		if char >= '！' && char <= '～' {
+			char -= '！' - '!'
+		}
+
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, char), unicode.IsControl(char):
+			slug.WriteRune(' ')
+		case char > unicode.MaxASCII && (unicode.IsSymbol(char) || unicode.IsMark(char) && !unicode.IsLetter(char)):
+			// ™, ® and the like
+		case char > unicode.MaxASCII && unicode.IsPunct(char):
+			slug.WriteRune(' ')
+		default:
+			slug.WriteRune(char)
+		}
+	}
+
+	result := strings.Join(strings.Fields(slug.String()), " ")
+	result = strings.TrimLeft(result, ".")
+
+	for len(result) > MAX_SLUG_LENGTH {
+		_, size := utf8.DecodeLastRuneInString(result)
+		result = strings.TrimSpace(result[:len(result)-size])
+	}
+
+	if result == "" {
+		return UNKNOWN_GAME_SLUG
+	}
+
+	return result
+}
+
+// GameFolder returns the folder of name under base, renaming the one earlier versions created from the raw name.
+func GameFolder(base string, name string) string {
+	slugged := filepath.Join(base, GameSlug(name))
+	raw := filepath.Join(base, name)
+
+	if raw == slugged || !strings.HasPrefix(raw, base+string(filepath.Separator)) {
+		return slugged
+	}
+
+	if _, err := os.Stat(slugged); !os.IsNotExist(err) {
+		return slugged
+	}
+
+	if info, err := os.Stat(raw); err == nil && info.IsDir() {
+		log.Printf("Renaming %s to %s\n", raw, slugged)
+
+		if err := system.FS.Rename(raw, slugged); err != nil {
+			log.Printf("Failed to rename %s: %s\n", raw, err)
+			return raw
+		}
+	}
+
+	return slugged
+}
@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestGameSlugComposesDecomposedNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"acute accent", "Pokémon", "Pokémon"},
+		{"macron", "Ōkami", "Ōkami"},
+		{"stacked marks", "Tiếng Việt", "Tiếng Việt"},
+		{"ogonek", "Żółw Gęsi", "Żółw Gęsi"},
+		{"hangul jamo", "한글", "한글"},
+		{"already composed", "Pokémon", "Pokémon"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if slug := GameSlug(test.input); slug != test.expected {
+				t.Errorf("GameSlug(%q) = %q, expected %q", test.input, slug, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// Templates new overrides can start from, templates folders can add more or replace these by name
+var bundledOverrideTemplates = map[string]string{
+	"performance": `gamemode:
+    enabled: true
+mangohud:
+    enabled: false
+gamescope:
+    enabled: false
+environment:
+    __GL_SHADER_DISK_CACHE_SKIP_CLEANUP: "1"
+`,
+	"quality": `gamescope:
+    enabled: true
+    hdr:
+        enabled: true
+mangohud:
+    enabled: false
+`,
+	"handheld": `gamemode:
+    enabled: true
+mangohud:
+    enabled: true
+gamescope:
+    enabled: true
+    args: !replace [-W, "1280", -H, "800", -r, "60", -F, fsr]
+`,
+}
+
+// OverrideTemplateNames lists the bundled templates and the ones of the templates folders, sorted.
+func OverrideTemplateNames(paths Paths) []string {
+	names := slices.Collect(maps.Keys(bundledOverrideTemplates))
+
+	for _, folder := range []string{paths.SystemTemplatesFolder, paths.TemplatesFolder} {
+		entries, _ := os.ReadDir(folder)
+
+		for _, entry := range entries {
+			if name, isYaml := strings.CutSuffix(entry.Name(), ".yaml"); isYaml && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// OverrideTemplate returns the content of the template called name, user templates winning over system-wide and bundled ones.
+func OverrideTemplate(paths Paths, name string) ([]byte, error) {
+	for _, folder := range []string{paths.TemplatesFolder, paths.SystemTemplatesFolder} {
+		if content, err := os.ReadFile(filepath.Join(folder, GameSlug(name)+".yaml")); err == nil {
+			return content, nil
+		}
+	}
+
+	if content, exists := bundledOverrideTemplates[name]; exists {
+		return []byte(content), nil
+	}
+
+	return nil, fmt.Errorf("Unknown override template %s, expected one of: %s", name, strings.Join(OverrideTemplateNames(paths), ", "))
+}
+
+// CreateOverrideFromTemplate writes the user override of game prefilled with the template called name,
+// keys missing from it keep their global value. It fails when the override already exists.
+func CreateOverrideFromTemplate(paths Paths, game string, name string) (string, error) {
+	overrideFile := OverrideFile(paths, game)
+
+	if _, err := os.Stat(overrideFile); err == nil {
+		return overrideFile, fmt.Errorf("%s already exists", overrideFile)
+	}
+
+	content, err := OverrideTemplate(paths, name)
+
+	if err != nil {
+		return overrideFile, err
+	}
+
+	if err := yaml.Unmarshal(content, &Configuration{}); err != nil {
+		return overrideFile, fmt.Errorf("Invalid override template %s: %s", name, err)
+	}
+
+	content = append([]byte(fmt.Sprintf("config-version: %d\n# from the %s template\n", CURRENT_CONFIG_VERSION, name)), content...)
+	MakeSureFoldersExist(paths.OverridesFolder)
+
+	return overrideFile, system.FS.WriteFile(overrideFile, content, DEFAULT_PERMISSION)
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigurationProblem is a mistake in a configuration or override file, or in a --set or PLAUNCHER_* setting.
+// Line is 0 when it is not known.
+type ConfigurationProblem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (problem ConfigurationProblem) String() string {
+	if problem.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", problem.File, problem.Line, problem.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", problem.File, problem.Message)
+}
+
+// ConfigurationErrors is every problem found in the configuration, reported at once so they can all be fixed
+// before the next launch.
+type ConfigurationErrors []ConfigurationProblem
+
+func (problems ConfigurationErrors) Error() string {
+	lines := make([]string, 0, len(problems))
+
+	for _, problem := range problems {
+		lines = append(lines, problem.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+var yamlLineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+var yamlUnknownFieldRegex = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+var yamlWrongTypeRegex = regexp.MustCompile("^cannot unmarshal !!\\w+ `(.*)` into (\\S+)$")
+
+// Lists the invalid YAML, unknown keys and values of the wrong type of content, read from file
+func fileProblems(file string, content []byte) []ConfigurationProblem {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(&Configuration{})
+
+	if err == nil {
+		return append(conditionProblems(file, content), secretProblems(file, content)...)
+	}
+
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	messages := []string{err.Error()}
+
+	var typeErr *yaml.TypeError
+
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	problems := make([]ConfigurationProblem, 0, len(messages))
+
+	for _, message := range messages {
+		problem := ConfigurationProblem{File: file, Message: message}
+
+		if match := yamlLineRegex.FindStringSubmatch(message); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Message = match[2]
+		}
+
+		if match := yamlUnknownFieldRegex.FindStringSubmatch(problem.Message); match != nil {
+			problem.Message = fmt.Sprintf("unknown key %s, plauncher overrides explain lists the valid ones", match[1])
+		} else if match := yamlWrongTypeRegex.FindStringSubmatch(problem.Message); match != nil {
+			problem.Message = fmt.Sprintf("%s is not %s", match[1], describeYamlType(match[2]))
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems
+}
+
+func describeYamlType(goType string) string {
+	switch {
+	case goType == "bool":
+		return "true or false"
+	case goType == "int" || goType == "float64":
+		return "a number"
+	case goType == "string":
+		return "a text"
+	case strings.HasPrefix(goType, "[]"):
+		return "a list"
+	default:
+		return "a section of keys"
+	}
+}
+
+// ValidateConfiguration reports the values of configuration plauncher can not launch with, each one traced back to
+// the file, or the setting, that set it. Keys set by no file are attributed to configurationFile.
+func ValidateConfiguration(configuration Configuration, configurationFile string) []ConfigurationProblem {
+	problems := make([]ConfigurationProblem, 0)
+
+	invalid := func(key string, format string, args ...any) {
+		problems = append(problems, keyProblem(configuration, configurationFile, key, fmt.Sprintf(format, args...)))
+	}
+
+	oneOf := func(key string, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			invalid(key, "%s is not one of %s", value, strings.Join(slices.DeleteFunc(allowed, func(entry string) bool { return entry == "" }), ", "))
+		}
+	}
+
+	oneOf("compatdata.mode", configuration.CompatData.Mode, COMPATDATA_MODE_RELOCATE, COMPATDATA_MODE_MIRROR)
+	oneOf("anticheat.action", configuration.Anticheat.Action, ANTICHEAT_ACTION_WARN, ANTICHEAT_ACTION_PASSTHROUGH, ANTICHEAT_ACTION_IGNORE)
+	oneOf("dependencies.action", configuration.Dependencies.Action, DEPENDENCIES_ACTION_SUGGEST, DEPENDENCIES_ACTION_INSTALL, DEPENDENCIES_ACTION_IGNORE)
+	oneOf("display.vrr", configuration.Display.Vrr, "", DISPLAY_VRR_ON, DISPLAY_VRR_OFF, DISPLAY_VRR_GAME_ONLY)
+	oneOf("recording.backend", configuration.Recording.Backend, RECORDING_BACKEND_GPU_SCREEN_RECORDER, RECORDING_BACKEND_OBS)
+	oneOf("recording.mode", configuration.Recording.Mode, RECORDING_MODE_REPLAY, RECORDING_MODE_RECORD)
+	oneOf("watchdog.action", configuration.Watchdog.Action, "", WATCHDOG_ACTION_NOTIFY, WATCHDOG_ACTION_KILL, WATCHDOG_ACTION_RESTART)
+	oneOf("metadata.renames", configuration.Metadata.Renames, METADATA_RENAMES_WARN, METADATA_RENAMES_MIGRATE, METADATA_RENAMES_IGNORE)
+	oneOf("mods.strategy", configuration.Mods.Strategy, MODS_STRATEGY_SYMLINK, MODS_STRATEGY_HARDLINK, MODS_STRATEGY_OVERLAY)
+	oneOf("instances.split", configuration.Instances.Split, INSTANCES_SPLIT_SIDE_BY_SIDE, INSTANCES_SPLIT_STACKED)
+	oneOf("instances.isolation", configuration.Instances.Isolation, INSTANCES_ISOLATION_SDL, INSTANCES_ISOLATION_DEVICES)
+
+	oneOf("secrets.provider", configuration.Secrets.Provider, append([]string{""}, SecretProviders()...)...)
+
+	for key, value := range configuration.Environment {
+		if reference, isSecret := ParseSecretReference(value, configuration.Secrets.Provider); isSecret && reference.Name == "" {
+			invalid("environment."+key, "%s needs the name of the secret, e.g. %s epic_token", SECRET_TAG, SECRET_TAG)
+		}
+	}
+
+	for _, entry := range configuration.Overrides.Precedence {
+		oneOf("overrides.precedence", entry, OVERRIDE_BY_ID, OVERRIDE_BY_NAME)
+	}
+
+	for _, resolver := range configuration.Metadata.Resolvers {
+		oneOf("metadata.resolvers", resolver, METADATA_RESOLVER_STORE, METADATA_RESOLVER_STEAMSPY)
+	}
+
+	if configuration.Instances.Count < 1 {
+		invalid("instances.count", "%d instances can not share the display, expected 1 or more", configuration.Instances.Count)
+	}
+
+	if timeout := configuration.Watchdog.Timeout; timeout != "" {
+		if duration, err := time.ParseDuration(timeout); err != nil || duration <= 0 {
+			invalid("watchdog.timeout", "%s is not a duration like 5m", timeout)
+		}
+	}
+
+	// umu only runs games outside Steam, Steam brings its own Proton
+	if _, isSteamLaunch := os.LookupEnv("STEAM_COMPAT_DATA_PATH"); configuration.Umu.Enabled && !isSteamLaunch {
+		if configuration.Umu.Proton == "" {
+			invalid("umu.proton", "umu is enabled and needs the folder of a Proton build")
+		} else if info, err := os.Stat(configuration.Umu.Proton); err != nil || !info.IsDir() {
+			invalid("umu.proton", "%s is not a folder, umu is enabled and needs the folder of a Proton build", configuration.Umu.Proton)
+		}
+	}
+
+	return problems
+}
+
+// Attributes a problem with key to the last file that set it, at the line of the key when the file can be read
+func keyProblem(configuration Configuration, configurationFile string, key string, message string) ConfigurationProblem {
+	sources := strings.Split(configuration.Provenance[key], ", ")
+	source := sources[len(sources)-1]
+
+	if source == "" {
+		source = configurationFile
+	}
+
+	problem := ConfigurationProblem{File: source, Message: fmt.Sprintf("%s: %s", key, message)}
+	content, err := os.ReadFile(source)
+
+	if err != nil {
+		return problem
+	}
+
+	document := yaml.Node{}
+
+	if yaml.Unmarshal(content, &document) != nil || len(document.Content) == 0 {
+		return problem
+	}
+
+	node := document.Content[0]
+
+	for _, part := range strings.Split(key, ".") {
+		keyNode := mappingKey(node, part)
+
+		if keyNode == nil {
+			return problem
+		}
+
+		problem.Line = keyNode.Line
+		node = mappingValue(node, part)
+	}
+
+	return problem
+}
+
+func mappingKey(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i]
+		}
+	}
+
+	return nil
+}
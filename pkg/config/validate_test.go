@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// config.yaml as the first releases wrote it, before most sections existed
+const BASELINE_CONFIGURATION = `environment: {}
+wine:
+    alsa: true
+mangohud:
+    enabled: false
+gamemode:
+    enabled: true
+gamescope:
+    enabled: false
+    hdr: false
+    args: []
+eos-overlay:
+    enabled: false
+umu:
+    enabled: false
+    proton: ""
+    game-id: ""
+    store: ""
+    args: []
+pre-scripts: []
+post-scripts: []
+`
+
+func TestValidateBaselineConfiguration(t *testing.T) {
+	configurationFile := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := os.WriteFile(configurationFile, []byte(BASELINE_CONFIGURATION), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	configuration := readConfiguration(configurationFile)
+
+	if problems := ValidateConfiguration(configuration, configurationFile); len(problems) > 0 {
+		t.Fatalf("baseline configuration rejected: %s", ConfigurationErrors(problems))
+	}
+
+	defaults := DefaultConfiguration()
+
+	if configuration.Instances.Count != defaults.Instances.Count || configuration.Secrets.Provider != defaults.Secrets.Provider {
+		t.Errorf("sections missing from the file did not get their defaults: instances %+v, secrets %+v", configuration.Instances, configuration.Secrets)
+	}
+
+	if !configuration.Gamemode.Enabled || configuration.Gamescope.Enabled {
+		t.Errorf("values set in the file were not kept: gamemode %+v, gamescope %+v", configuration.Gamemode, configuration.Gamescope)
+	}
+}
+
+func TestValidateConfigurationWithoutSecretsSection(t *testing.T) {
+	configurationFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := BASELINE_CONFIGURATION + "environment:\n    TOKEN: !secret token\n"
+
+	if err := os.WriteFile(configurationFile, []byte(content[len("environment: {}\n"):]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	configuration := readConfiguration(configurationFile)
+
+	if problems := ValidateConfiguration(configuration, configurationFile); len(problems) > 0 {
+		t.Fatalf("configuration without a secrets section rejected: %s", ConfigurationErrors(problems))
+	}
+
+	if reference, isSecret := ParseSecretReference(configuration.Environment["TOKEN"], configuration.Secrets.Provider); !isSecret || reference.Provider != SECRET_PROVIDER_FILE || reference.Name != "token" {
+		t.Errorf("TOKEN is %q, expected a reference to the secret token in the secrets file", configuration.Environment["TOKEN"])
+	}
+}
+
+func TestValidateEmptySecretsProvider(t *testing.T) {
+	configuration := DefaultConfiguration()
+	configuration.Secrets = SecretsConfiguration{}
+	configuration.Environment["TOKEN"] = SECRET_TAG + " token"
+
+	if problems := ValidateConfiguration(configuration, ""); len(problems) > 0 {
+		t.Fatalf("empty secrets section rejected: %s", ConfigurationErrors(problems))
+	}
+
+	if reference, _ := ParseSecretReference(configuration.Environment["TOKEN"], configuration.Secrets.Provider); reference.Provider != SECRET_PROVIDER_FILE {
+		t.Errorf("secret looked up in %q, expected the secrets file", reference.Provider)
+	}
+}
@@ -0,0 +1,202 @@
+// Package doctor checks the setup plauncher runs in: the tools it wraps, the configuration files and the prefixes.
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/steam"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const STATUS_OK = "ok"
+const STATUS_WARN = "warn"
+const STATUS_FAIL = "fail"
+
+// Check is the outcome of one check, with what to do about it unless it passed.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// tool is a binary plauncher wraps games with, missing it fails the checks when the configuration enables it
+type tool struct {
+	binName string
+	purpose string
+	enabled func(configuration config.Configuration) bool
+	fix     string
+}
+
+var tools = []tool{
+	{wrappers.GAMEMODE_BIN_NAME, "gamemode", func(configuration config.Configuration) bool { return configuration.Gamemode.Enabled }, "install gamemode, or set gamemode.enabled: false"},
+	{wrappers.MANGOHUD_BIN_NAME, "MangoHud", func(configuration config.Configuration) bool { return configuration.Mangohud.Enabled }, "install MangoHud, or set mangohud.enabled: false"},
+	{wrappers.GAMESCOPE_BIN_NAME, "gamescope", func(configuration config.Configuration) bool { return configuration.Gamescope.Enabled }, "install gamescope, or set gamescope.enabled: false"},
+	{wrappers.UMU_RUN_BIN_NAME, "umu", func(configuration config.Configuration) bool { return configuration.Umu.Enabled }, "install umu-launcher, or set umu.enabled: false"},
+	{wrappers.LEGENDARY_BIN_NAME, "the EOS overlay", func(configuration config.Configuration) bool { return configuration.EosOverlay.Enabled }, "install legendary, or set eos-overlay.enabled: false"},
+	{wrappers.WINETRICKS_BIN_NAME, "prefix verbs and dependencies", func(configuration config.Configuration) bool {
+		return configuration.Dependencies.Action == config.DEPENDENCIES_ACTION_INSTALL
+	}, "install winetricks, or set dependencies.action: suggest"},
+	{wrappers.BWRAP_BIN_NAME, "controller isolation of --instance", func(configuration config.Configuration) bool {
+		return configuration.Instances.Isolation == config.INSTANCES_ISOLATION_DEVICES
+	}, "install bubblewrap, or set instances.isolation: sdl"},
+}
+
+// Run goes through every check. The configuration is only loaded once its files are known to be valid, the tools
+// are checked against the defaults otherwise.
+func Run(paths config.Paths) []Check {
+	checks := checkConfigurationFiles(paths)
+	configuration := config.DefaultConfiguration()
+	configurationValid := true
+
+	for _, check := range checks {
+		configurationValid = configurationValid && check.Status != STATUS_FAIL
+	}
+
+	if configurationValid {
+		configuration, _ = config.LoadConfiguration(paths)
+	}
+
+	checks = append(checks, checkTools(configuration)...)
+	checks = append(checks, checkCompatData(paths)...)
+
+	return append(checks, checkSteam(paths))
+}
+
+// Failed reports whether one of checks failed.
+func Failed(checks []Check) bool {
+	for _, check := range checks {
+		if check.Status == STATUS_FAIL {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkTools(configuration config.Configuration) []Check {
+	checks := make([]Check, 0, len(tools))
+
+	for _, tool := range tools {
+		check := Check{Name: tool.binName, Status: STATUS_OK}
+
+		if path, exists := system.Exec.LookPath(tool.binName); exists {
+			check.Detail = path
+		} else if tool.enabled(configuration) {
+			check.Status = STATUS_FAIL
+			check.Detail = fmt.Sprintf("not found, %s is enabled", tool.purpose)
+			check.Fix = tool.fix
+		} else {
+			check.Status = STATUS_WARN
+			check.Detail = fmt.Sprintf("not found, needed for %s", tool.purpose)
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// Invalid files stop launches without a word on the terminal, they are the first thing to look at
+func checkConfigurationFiles(paths config.Paths) []Check {
+	checks := make([]Check, 0)
+	files := []string{paths.SystemConfigurationFile, paths.ConfigurationFile}
+
+	for _, folder := range append(config.ProfileFolders(paths), config.OverrideFolders(paths)...) {
+		overrideFiles, _ := filepath.Glob(filepath.Join(folder, "*.yaml"))
+		files = append(files, overrideFiles...)
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			if file == paths.ConfigurationFile {
+				checks = append(checks, Check{"configuration", STATUS_OK, file + " is created on the first launch", ""})
+			}
+
+			continue
+		}
+
+		if err := config.CheckConfigurationFile(file); err != nil {
+			var problems config.ConfigurationErrors
+
+			if !errors.As(err, &problems) {
+				checks = append(checks, Check{"configuration", STATUS_FAIL, fmt.Sprintf("%s: %s", file, strings.Join(strings.Fields(err.Error()), " ")), "fix or remove the reported keys"})
+				continue
+			}
+
+			for _, problem := range problems {
+				checks = append(checks, Check{"configuration", STATUS_FAIL, problem.String(), "fix or remove the reported keys"})
+			}
+
+			continue
+		}
+
+		if file == paths.ConfigurationFile || file == paths.SystemConfigurationFile {
+			checks = append(checks, Check{"configuration", STATUS_OK, file, ""})
+		}
+	}
+
+	return checks
+}
+
+func checkCompatData(paths config.Paths) []Check {
+	checks := make([]Check, 0)
+
+	for _, base := range config.CompatDataBases(paths) {
+		info, err := os.Stat(base)
+
+		switch {
+		case os.IsNotExist(err):
+			checks = append(checks, Check{"compatdata", STATUS_OK, base + " is created on the first launch", ""})
+			continue
+		case err != nil:
+			checks = append(checks, Check{"compatdata", STATUS_FAIL, err.Error(), "check the permissions of " + filepath.Dir(base)})
+			continue
+		case !info.IsDir():
+			checks = append(checks, Check{"compatdata", STATUS_FAIL, base + " is not a folder", "move it away or change compatdata.base"})
+			continue
+		}
+
+		if probe, err := os.CreateTemp(base, ".plauncher-doctor-"); err != nil {
+			checks = append(checks, Check{"compatdata", STATUS_FAIL, fmt.Sprintf("%s is not writable: %s", base, err), "check the permissions of " + base})
+			continue
+		} else {
+			probe.Close()
+			os.Remove(probe.Name())
+		}
+
+		dangling, err := prefix.DanglingCompatDataLinks(base)
+
+		if err != nil {
+			checks = append(checks, Check{"compatdata", STATUS_FAIL, err.Error(), ""})
+			continue
+		}
+
+		for _, link := range dangling {
+			checks = append(checks, Check{"compatdata", STATUS_FAIL, link + " points to a prefix that no longer exists", config.APP_NAME + " prefix repair"})
+		}
+
+		if len(dangling) == 0 {
+			checks = append(checks, Check{"compatdata", STATUS_OK, base, ""})
+		}
+	}
+
+	return checks
+}
+
+func checkSteam(paths config.Paths) Check {
+	apps := steam.InstalledApps(paths.HomeDir)
+
+	if len(apps) == 0 {
+		return Check{"steam", STATUS_WARN, "no Steam library with installed games found", "games outside Steam need --name and umu.enabled"}
+	}
+
+	return Check{"steam", STATUS_OK, fmt.Sprintf("%d installed games", len(apps)), ""}
+}
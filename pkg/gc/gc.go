@@ -0,0 +1,182 @@
+// Package gc finds the stale data plauncher and Steam leave behind, by the retention limits of the gc section.
+package gc
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/steam"
+)
+
+const KIND_NAME_CACHE = "name-cache"
+const KIND_SESSION_LOG = "session-log"
+const KIND_SHADER_CACHE = "shader-cache"
+const KIND_BACKUP = "backup"
+
+const DAY = 24 * time.Hour
+
+// Removal is a file or folder past its retention limit.
+type Removal struct {
+	Kind  string `json:"kind"`
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Kept is stale looking data gc leaves alone, with the reason.
+type Kept struct {
+	Kind   string `json:"kind"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Find returns everything past the retention limits of configuration at now, and what it could not tell was stale.
+func Find(paths config.Paths, configuration config.Configuration, now time.Time) ([]Removal, []Kept) {
+	removals := make([]Removal, 0)
+	kept := make([]Kept, 0)
+	limits := configuration.Gc
+
+	if limits.NameCacheDays > 0 {
+		removals = append(removals, staleNameCacheEntries(paths, now.Add(-time.Duration(limits.NameCacheDays)*DAY))...)
+	}
+
+	if limits.SessionLogs > 0 || limits.SessionLogDays > 0 {
+		removals = append(removals, oldSessionLogs(paths.LogsFolder, limits.SessionLogs, now.Add(-time.Duration(limits.SessionLogDays)*DAY), limits.SessionLogDays > 0)...)
+	}
+
+	if limits.ShaderCaches {
+		for _, shaderCache := range steam.OrphanedShaderCaches(paths.HomeDir) {
+			removals = append(removals, newRemoval(KIND_SHADER_CACHE, shaderCache))
+		}
+	}
+
+	if limits.BackupDays > 0 {
+		backups, keptMirrors := expiredBackups(paths, now.Add(-time.Duration(limits.BackupDays)*DAY))
+		removals = append(removals, backups...)
+		kept = append(kept, keptMirrors...)
+	}
+
+	return removals, kept
+}
+
+// Names of installed apps are kept whatever their age, they are needed on the next launch
+func staleNameCacheEntries(paths config.Paths, before time.Time) []Removal {
+	removals := make([]Removal, 0)
+	installed := make([]string, 0)
+
+	for _, app := range steam.InstalledApps(paths.HomeDir) {
+		installed = append(installed, app.Id)
+	}
+
+	// English names are at the root of the cache, other languages in a folder each
+	filepath.WalkDir(paths.AppNamesCacheFolder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || slices.Contains(installed, entry.Name()) {
+			return nil
+		}
+
+		if info, err := entry.Info(); err == nil && info.ModTime().Before(before) {
+			removals = append(removals, Removal{KIND_NAME_CACHE, path, info.Size()})
+		}
+
+		return nil
+	})
+
+	return removals
+}
+
+// Session logs are named after the time they started, the newest keep of every game are kept
+func oldSessionLogs(logsFolder string, keep int, before time.Time, expire bool) []Removal {
+	removals := make([]Removal, 0)
+	gameFolders, _ := os.ReadDir(logsFolder)
+
+	for _, gameFolder := range gameFolders {
+		if !gameFolder.IsDir() {
+			continue
+		}
+
+		sessionLogs, _ := filepath.Glob(filepath.Join(logsFolder, gameFolder.Name(), "session-*.log"))
+		slices.Sort(sessionLogs)
+		slices.Reverse(sessionLogs)
+
+		for index, sessionLog := range sessionLogs {
+			info, err := os.Stat(sessionLog)
+
+			if err != nil {
+				continue
+			}
+
+			if (keep > 0 && index >= keep) || (expire && info.ModTime().Before(before)) {
+				removals = append(removals, Removal{KIND_SESSION_LOG, sessionLog, info.Size()})
+			}
+		}
+	}
+
+	return removals
+}
+
+// Configuration files left by migrations, and compat data mirrors of games Steam no longer has. A mirror whose
+// compat data is missing while its game is installed, or its library can not be checked, may be the only copy
+// left, it is kept.
+func expiredBackups(paths config.Paths, before time.Time) ([]Removal, []Kept) {
+	kept := make([]Kept, 0)
+	removals := make([]Removal, 0)
+	backupFiles := make([]string, 0)
+
+	for _, folder := range []string{paths.AppConfigFolder, paths.OverridesFolder} {
+		matches, _ := filepath.Glob(filepath.Join(folder, "*.v*.bak"))
+		backupFiles = append(backupFiles, matches...)
+	}
+
+	for _, backupFile := range backupFiles {
+		if info, err := os.Stat(backupFile); err == nil && info.ModTime().Before(before) {
+			removals = append(removals, Removal{KIND_BACKUP, backupFile, info.Size()})
+		}
+	}
+
+	mirrors, _ := prefix.ListManagedPrefixes(paths.MirrorsFolder)
+
+	for _, mirror := range mirrors {
+		if mirror.Manifest.SteamCompatData == "" || mirror.Manifest.UpdatedAt.After(before) {
+			continue
+		}
+
+		if _, err := os.Stat(mirror.Manifest.SteamCompatData); !os.IsNotExist(err) {
+			continue
+		}
+
+		installed, checked := steam.CompatDataAppInstalled(paths.HomeDir, mirror.Manifest.SteamCompatData)
+
+		switch {
+		case !checked:
+			kept = append(kept, Kept{KIND_BACKUP, mirror.Folder, "the Steam library of " + mirror.Manifest.SteamCompatData + " is not listed or not mounted"})
+		case installed:
+			kept = append(kept, Kept{KIND_BACKUP, mirror.Folder, "the game is installed but " + mirror.Manifest.SteamCompatData + " is missing, the mirror may be its only copy"})
+		default:
+			removals = append(removals, newRemoval(KIND_BACKUP, mirror.Folder))
+		}
+	}
+
+	return removals, kept
+}
+
+func newRemoval(kind string, folder string) Removal {
+	size := int64(0)
+
+	filepath.WalkDir(folder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return Removal{kind, folder, size}
+}
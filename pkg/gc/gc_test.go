@@ -0,0 +1,45 @@
+package gc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+)
+
+func TestExpiredBackupsKeepsMirrorsOutOfReach(t *testing.T) {
+	base := t.TempDir()
+	library := filepath.Join(base, ".steam", "steam")
+	paths := config.Paths{HomeDir: base, MirrorsFolder: filepath.Join(base, "mirrors")}
+
+	os.MkdirAll(filepath.Join(library, "steamapps", "compatdata"), 0o755)
+	os.WriteFile(filepath.Join(library, "steamapps", "appmanifest_1145360.acf"), []byte("\"AppState\"\n{\n\t\"appid\"\t\t\"1145360\"\n\t\"name\"\t\t\"Hades\"\n}\n"), 0o644)
+
+	mirrors := map[string]string{
+		"uninstalled": filepath.Join(library, "steamapps", "compatdata", "620"),
+		"installed":   filepath.Join(library, "steamapps", "compatdata", "1145360"),
+		"unmounted":   filepath.Join(base, "mnt", "games", "steamapps", "compatdata", "620"),
+	}
+
+	for name, steamCompatData := range mirrors {
+		folder := filepath.Join(paths.MirrorsFolder, name)
+		manifest, _ := json.Marshal(prefix.Manifest{Name: name, SteamCompatData: steamCompatData})
+
+		os.MkdirAll(folder, 0o755)
+		os.WriteFile(filepath.Join(folder, prefix.MANIFEST_FILENAME), manifest, 0o644)
+	}
+
+	removals, kept := expiredBackups(paths, time.Now())
+
+	if len(removals) != 1 || removals[0].Path != filepath.Join(paths.MirrorsFolder, "uninstalled") {
+		t.Errorf("removals %+v, expected the mirror of the uninstalled app alone", removals)
+	}
+
+	if len(kept) != 2 {
+		t.Errorf("kept %+v, expected the mirrors of the installed app and of the unmounted library", kept)
+	}
+}
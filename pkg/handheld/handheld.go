@@ -0,0 +1,102 @@
+// Package handheld knows the gaming handhelds plauncher has presets for and detects them from DMI.
+package handheld
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const PRESET_AUTO = "auto"
+const PRESET_ROG_ALLY = "rog-ally"
+const PRESET_LEGION_GO = "legion-go"
+const PRESET_STEAM_DECK = "steam-deck"
+
+const DMI_FOLDER = "/sys/class/dmi/id"
+
+// Preset holds the defaults of a handheld: gamescope flags (panel size and refresh rate, orientation of
+// panels mounted in portrait, upscaling) and the sustained power limit ryzenadj applies when allowed.
+type Preset struct {
+	Name          string
+	Device        string
+	GamescopeArgs [][]string
+	TdpWatts      int
+	// Substrings of the DMI sys_vendor and product_name (or board_name) identifying the device
+	dmiVendor  string
+	dmiProduct []string
+}
+
+var presets = []Preset{
+	{
+		PRESET_ROG_ALLY,
+		"ASUS ROG Ally",
+		[][]string{{"-W", "1920"}, {"-H", "1080"}, {"-r", "120"}, {"-F", "fsr"}},
+		15,
+		"ASUSTeK",
+		[]string{"ROG Ally", "RC71L", "RC72LA"},
+	},
+	{
+		PRESET_LEGION_GO,
+		"Lenovo Legion Go",
+		[][]string{{"-W", "2560"}, {"-H", "1600"}, {"-r", "144"}, {"--force-orientation", "left"}, {"-F", "fsr"}},
+		15,
+		"LENOVO",
+		[]string{"83E1", "Legion Go"},
+	},
+	{
+		PRESET_STEAM_DECK,
+		"Steam Deck",
+		[][]string{{"-W", "1280"}, {"-H", "800"}, {"-r", "60"}, {"--force-orientation", "right"}, {"-F", "fsr"}},
+		0,
+		"Valve",
+		[]string{"Jupiter", "Galileo"},
+	},
+}
+
+// Presets returns every known preset.
+func Presets() []Preset {
+	return presets
+}
+
+// FindPreset returns the preset named name, or the one of the device plauncher runs on for auto.
+func FindPreset(name string) (Preset, bool) {
+	if name == PRESET_AUTO {
+		return Detect()
+	}
+
+	for _, preset := range presets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+
+	return Preset{}, false
+}
+
+// Detect returns the preset of the device plauncher runs on, when it is a known handheld.
+func Detect() (Preset, bool) {
+	vendor := readDmi("sys_vendor")
+	products := []string{readDmi("product_name"), readDmi("board_name")}
+
+	for _, preset := range presets {
+		if !strings.Contains(vendor, preset.dmiVendor) {
+			continue
+		}
+
+		for _, dmiProduct := range preset.dmiProduct {
+			for _, product := range products {
+				if product != "" && strings.Contains(product, dmiProduct) {
+					return preset, true
+				}
+			}
+		}
+	}
+
+	return Preset{}, false
+}
+
+func readDmi(field string) string {
+	content, _ := os.ReadFile(filepath.Join(DMI_FOLDER, field))
+
+	return strings.TrimSpace(string(content))
+}
@@ -0,0 +1,21 @@
+// Package hooks runs the user pre/post launch scripts.
+package hooks
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// ExecuteScripts runs each script from scriptsFolder with the user SHELL.
+func ExecuteScripts(scripts []string, scriptsFolder string) {
+	for _, script := range scripts {
+		fullScriptPath := filepath.Join(scriptsFolder, script)
+		log.Printf("Executing script: %s\n", fullScriptPath)
+		cmdHandle := exec.Command(os.Getenv("SHELL"), script)
+		system.Exec.Run(cmdHandle)
+	}
+}
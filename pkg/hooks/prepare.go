@@ -0,0 +1,135 @@
+package hooks
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"slices"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const PREPARE_STEP_RAN = "ran"
+const PREPARE_STEP_SKIPPED = "skipped"
+const PREPARE_STEP_FAILED = "failed"
+
+// Set to 1 in the environment of the launch when the game executable changed since the previous one
+const GAME_UPDATED_ENV = "PLAUNCHER_GAME_UPDATED"
+
+// PrepareResult is the outcome of a prepare step.
+type PrepareResult struct {
+	Step   string `json:"step"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// RunPrepareSteps runs steps with sh in scriptsFolder, each after the steps it needs, in configuration order
+// otherwise. Steps needing a step that failed or was skipped are skipped. It stops at the first failure of a
+// step that is not optional, returning the results so far along with the error.
+func RunPrepareSteps(steps []config.PrepareStep, scriptsFolder string, environment []string) ([]PrepareResult, error) {
+	results := make([]PrepareResult, 0, len(steps))
+	ordered, err := orderPrepareSteps(steps)
+
+	if err != nil {
+		return results, err
+	}
+
+	statuses := make(map[string]string)
+
+	for _, step := range ordered {
+		result := runPrepareStep(step, statuses, scriptsFolder, environment)
+		statuses[step.Name] = result.Status
+		results = append(results, result)
+
+		log.Printf("Prepare step %s %s %s\n", step.Name, result.Status, result.Reason)
+
+		if result.Status == PREPARE_STEP_FAILED && !step.Optional {
+			return results, fmt.Errorf("Prepare step %s failed: %s", step.Name, result.Reason)
+		}
+	}
+
+	return results, nil
+}
+
+func runPrepareStep(step config.PrepareStep, statuses map[string]string, scriptsFolder string, environment []string) PrepareResult {
+	for _, need := range step.Needs {
+		if statuses[need] != PREPARE_STEP_RAN {
+			return PrepareResult{step.Name, PREPARE_STEP_SKIPPED, fmt.Sprintf("needs %s, which %s", need, statuses[need])}
+		}
+	}
+
+	// Provisioning steps skipped once done run again for a new game build, which may have undone them
+	if step.OnUpdate && slices.Contains(environment, GAME_UPDATED_ENV+"=1") {
+		log.Printf("Game updated, running prepare step %s again\n", step.Name)
+	} else if step.SkipIf != "" && system.Exec.Run(prepareCommand(step.SkipIf, scriptsFolder, environment)) == nil {
+		return PrepareResult{step.Name, PREPARE_STEP_SKIPPED, "skip-if condition met"}
+	}
+
+	if err := system.Exec.Run(prepareCommand(step.Run, scriptsFolder, environment)); err != nil {
+		return PrepareResult{step.Name, PREPARE_STEP_FAILED, err.Error()}
+	}
+
+	return PrepareResult{step.Name, PREPARE_STEP_RAN, ""}
+}
+
+func prepareCommand(commandLine string, scriptsFolder string, environment []string) *exec.Cmd {
+	cmdHandle := exec.Command("sh", "-c", commandLine)
+	cmdHandle.Dir = scriptsFolder
+	cmdHandle.Env = environment
+
+	return cmdHandle
+}
+
+// Sorts steps so that each comes after the steps it needs, keeping the configuration order otherwise
+func orderPrepareSteps(steps []config.PrepareStep) ([]config.PrepareStep, error) {
+	names := make([]string, 0, len(steps))
+
+	for _, step := range steps {
+		if step.Name == "" || step.Run == "" {
+			return nil, fmt.Errorf("Prepare steps need a name and a run command: %+v", step)
+		}
+
+		if slices.Contains(names, step.Name) {
+			return nil, fmt.Errorf("Prepare step %s is defined twice", step.Name)
+		}
+
+		names = append(names, step.Name)
+	}
+
+	ordered := make([]config.PrepareStep, 0, len(steps))
+	placed := make([]string, 0, len(steps))
+
+	for len(ordered) < len(steps) {
+		progressed := false
+
+		for _, step := range steps {
+			if slices.Contains(placed, step.Name) {
+				continue
+			}
+
+			ready := true
+
+			for _, need := range step.Needs {
+				if !slices.Contains(names, need) {
+					return nil, fmt.Errorf("Prepare step %s needs unknown step %s", step.Name, need)
+				}
+
+				ready = ready && slices.Contains(placed, need)
+			}
+
+			if ready {
+				ordered = append(ordered, step)
+				placed = append(placed, step.Name)
+				progressed = true
+				break
+			}
+		}
+
+		if !progressed {
+			return nil, fmt.Errorf("Prepare steps depend on each other in a cycle")
+		}
+	}
+
+	return ordered, nil
+}
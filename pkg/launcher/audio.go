@@ -0,0 +1,57 @@
+package launcher
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+)
+
+// Rate the PipeWire quantum is expressed in when audio.rate is not set
+const DEFAULT_AUDIO_RATE = 48000
+
+// Wine staging takes its audio buffer sizes in 100 ns units
+const WINE_AUDIO_UNITS_PER_USEC = 10
+
+// EnrichEnvironmentWithAudio maps the audio latency settings to the variables PulseAudio, PipeWire and Wine read,
+// variables already in the environment section win.
+func EnrichEnvironmentWithAudio(configuration *config.Configuration) {
+	audio := configuration.Audio
+	variables := make(map[string]string)
+
+	if audio.LatencyMsec > 0 {
+		variables["PULSE_LATENCY_MSEC"] = strconv.Itoa(audio.LatencyMsec)
+	}
+
+	rate := audio.Rate
+
+	if rate <= 0 {
+		rate = DEFAULT_AUDIO_RATE
+	}
+
+	if audio.Quantum > 0 {
+		variables["PIPEWIRE_QUANTUM"] = fmt.Sprintf("%d/%d", audio.Quantum, rate)
+	}
+
+	if audio.Rate > 0 {
+		variables["PIPEWIRE_RATE"] = fmt.Sprintf("1/%d", audio.Rate)
+	}
+
+	if audio.WinePeriodUsec > 0 {
+		variables["STAGING_AUDIO_PERIOD"] = strconv.Itoa(audio.WinePeriodUsec * WINE_AUDIO_UNITS_PER_USEC)
+	}
+
+	if audio.WineDurationUsec > 0 {
+		variables["STAGING_AUDIO_DURATION"] = strconv.Itoa(audio.WineDurationUsec * WINE_AUDIO_UNITS_PER_USEC)
+	}
+
+	for key, value := range variables {
+		if _, configured := configuration.Environment[key]; configured {
+			continue
+		}
+
+		log.Printf("Audio setting: %s=%s\n", key, value)
+		configuration.Environment[key] = value
+	}
+}
@@ -0,0 +1,75 @@
+package launcher
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// Channels used by a bare --wine-debug
+const DEFAULT_WINE_DEBUG_CHANNELS = "+seh,+loaddll"
+
+// EnrichEnvironmentWithDebug sets the Proton logging variables requested in the debug section.
+// It returns the Proton log file, empty when Proton logging is disabled.
+func EnrichEnvironmentWithDebug(configuration *config.Configuration, paths config.Paths) string {
+	if !configuration.Debug.ProtonLog {
+		return ""
+	}
+
+	logsFolder := config.GameLogsFolder(paths, *configuration)
+	config.MakeSureFoldersExist(logsFolder)
+
+	configuration.Environment["PROTON_LOG"] = "1"
+	configuration.Environment["PROTON_LOG_DIR"] = logsFolder
+
+	// Proton names its log after SteamAppId, umu after GAMEID
+	logId := configuration.Props["steam-appid"]
+
+	if logId == "" {
+		logId = configuration.Environment["GAMEID"]
+	}
+
+	if logId == "" {
+		logId = "0"
+	}
+
+	protonLogFile := filepath.Join(logsFolder, "steam-"+logId+".log")
+	log.Printf("Proton log enabled: %s\n", protonLogFile)
+
+	return protonLogFile
+}
+
+// EnrichEnvironmentWithWineDebug maps wine.debug, or --wine-debug[=channels], to WINEDEBUG.
+func EnrichEnvironmentWithWineDebug(configuration *config.Configuration) {
+	if channels, exists := configuration.Props["wine-debug"]; exists {
+		configuration.Wine.Debug = channels
+	} else if _, exists := configuration.SpecialFlags["wine-debug"]; exists {
+		configuration.Wine.Debug = DEFAULT_WINE_DEBUG_CHANNELS
+	}
+
+	if configuration.Wine.Debug == "" {
+		return
+	}
+
+	log.Printf("Wine debug channels: %s\n", configuration.Wine.Debug)
+	configuration.Environment["WINEDEBUG"] = configuration.Wine.Debug
+}
+
+// Opens a new log file in the game logs folder to collect the game output of this session
+func openSessionLog(configuration config.Configuration, paths config.Paths) (*os.File, error) {
+	if system.Simulating() {
+		return nil, fmt.Errorf("Session log is not written when simulating")
+	}
+
+	logsFolder := config.GameLogsFolder(paths, configuration)
+	config.MakeSureFoldersExist(logsFolder)
+
+	sessionLogFile := filepath.Join(logsFolder, fmt.Sprintf("session-%s.log", time.Now().Format("20060102-150405")))
+
+	return os.OpenFile(sessionLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, config.DEFAULT_PERMISSION)
+}
@@ -0,0 +1,65 @@
+package launcher
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/session"
+)
+
+// Scans the game folder once per prefix, on the first launch after Proton created it, for the runtimes the game
+// ships installers for and suggests or installs their winetricks verbs
+func applyDependencies(configuration config.Configuration, paths config.Paths, gameArgs []string) {
+	if configuration.Dependencies.Action == config.DEPENDENCIES_ACTION_IGNORE {
+		return
+	}
+
+	gameExe := gameExecutable(gameArgs)
+	prefixRoot := gamePrefixRoot(configuration, paths)
+	pfx := prefix.PfxFolder(prefixRoot)
+
+	if _, err := os.Stat(filepath.Join(pfx, "system.reg")); gameExe == "" || prefixRoot == "" || err != nil {
+		return
+	}
+
+	if manifest, err := prefix.ReadManifest(prefixRoot); err == nil && manifest.DependenciesChecked {
+		return
+	}
+
+	verbs := prefix.DetectDependencies(gameExe)
+
+	if len(verbs) > 0 && configuration.Dependencies.Action == config.DEPENDENCIES_ACTION_INSTALL {
+		log.Printf("Installing the dependencies shipped with the game: %s\n", strings.Join(verbs, " "))
+
+		// Checked again next launch, a failed install may work then
+		if err := prefix.InstallWinetricksVerbs(pfx, verbs); err != nil {
+			log.Printf("Failed to install the game dependencies: %s\n", err)
+			return
+		}
+	} else if len(verbs) > 0 {
+		suggestion := fmt.Sprintf("%s ships installers for %s, set dependencies.action: install in its override to install them with winetricks", configuration.Props["name"], strings.Join(verbs, " "))
+		log.Printf("%s\n", suggestion)
+		fmt.Fprintf(os.Stderr, "plauncher: %s\n", suggestion)
+		session.Notify(suggestion)
+	}
+
+	if err := prefix.MarkDependenciesChecked(prefixRoot); err != nil {
+		log.Printf("Failed to record the dependency scan in %s: %s\n", prefixRoot, err)
+	}
+}
+
+// The first Windows executable given by absolute path, the game itself
+func gameExecutable(gameArgs []string) string {
+	for _, arg := range gameArgs {
+		if filepath.IsAbs(arg) && strings.EqualFold(filepath.Ext(arg), ".exe") {
+			return arg
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,51 @@
+package launcher
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// PrintDryRun writes command, shell quoted, and the variables environment adds to or changes in the current
+// environment to out, for --dry-run.
+func PrintDryRun(out io.Writer, command []string, environment map[string]string) {
+	quotedCommand := make([]string, len(command))
+
+	for i, arg := range command {
+		quotedCommand[i] = ShellQuote(arg)
+	}
+
+	fmt.Fprintf(out, "Command:\n  %s\n", strings.Join(quotedCommand, " "))
+	fmt.Fprintf(out, "Environment:\n")
+
+	keys := make([]string, 0, len(environment))
+
+	for key := range environment {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+	changed := 0
+
+	for _, key := range keys {
+		value := os.ExpandEnv(environment[key])
+		current, exists := os.LookupEnv(key)
+
+		switch {
+		case !exists:
+			fmt.Fprintf(out, "  + %s=%s\n", key, ShellQuote(value))
+		case current != value:
+			fmt.Fprintf(out, "  ~ %s=%s (was %s)\n", key, ShellQuote(value), ShellQuote(current))
+		default:
+			continue
+		}
+
+		changed++
+	}
+
+	if changed == 0 {
+		fmt.Fprintf(out, "  unchanged\n")
+	}
+}
@@ -0,0 +1,88 @@
+package launcher
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+)
+
+const EVENT_CONFIG_RESOLVED = "config-resolved"
+const EVENT_PREFIX_READY = "prefix-ready"
+const EVENT_GAME_STARTED = "game-started"
+const EVENT_GAME_EXITED = "game-exited"
+
+// EventStream receives the launch phase transitions as JSON lines.
+type EventStream struct {
+	handle *os.File
+}
+
+type LaunchEvent struct {
+	Event  string         `json:"event"`
+	Time   time.Time      `json:"time"`
+	Pid    int            `json:"pid"`
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+// OpenEventStream opens the stream requested by --events-fd=N or --events-pipe=PATH, returns nil when none was requested
+func OpenEventStream(configuration config.Configuration) *EventStream {
+	if fdValue, exists := configuration.Props["events-fd"]; exists {
+		fd, err := strconv.Atoi(fdValue)
+
+		if err != nil || fd < 0 {
+			log.Printf("Invalid events file descriptor: %s\n", fdValue)
+			return nil
+		}
+
+		// Inherited without close-on-exec, the game and the scripts would hold the stream open past the
+		// game-exited event and whoever reads it would wait for them
+		syscall.CloseOnExec(fd)
+
+		log.Printf("Emitting launch events to file descriptor: %d\n", fd)
+		return &EventStream{os.NewFile(uintptr(fd), "events")}
+	}
+
+	if pipePath, exists := configuration.Props["events-pipe"]; exists {
+		handle, err := os.OpenFile(pipePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE|syscall.O_CLOEXEC, config.DEFAULT_PERMISSION)
+
+		if err != nil {
+			log.Printf("Failed to open events pipe %s: %s\n", pipePath, err)
+			return nil
+		}
+
+		log.Printf("Emitting launch events to: %s\n", pipePath)
+		return &EventStream{handle}
+	}
+
+	return nil
+}
+
+// Emit writes event as a single JSON line, it is a no-op on a nil stream.
+func (stream *EventStream) Emit(event string, fields map[string]any) {
+	if stream == nil {
+		return
+	}
+
+	line, err := json.Marshal(LaunchEvent{event, time.Now(), os.Getpid(), fields})
+
+	if err != nil {
+		log.Printf("Failed to serialize launch event %s: %s\n", event, err)
+		return
+	}
+
+	if _, err := stream.handle.Write(append(line, '\n')); err != nil {
+		log.Printf("Failed to emit launch event %s: %s\n", event, err)
+	}
+}
+
+func (stream *EventStream) Close() {
+	if stream == nil {
+		return
+	}
+
+	stream.handle.Close()
+}
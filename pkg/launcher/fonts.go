@@ -0,0 +1,64 @@
+package launcher
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// Installs the configured fonts in the game prefix, prefixes Proton did not create yet get them next launch
+func applyFonts(configuration config.Configuration, paths config.Paths) {
+	if len(configuration.Wine.Fonts) == 0 {
+		return
+	}
+
+	prefixRoot := gamePrefixRoot(configuration, paths)
+	pfx := prefix.PfxFolder(prefixRoot)
+
+	if _, err := os.Stat(filepath.Join(pfx, "system.reg")); prefixRoot == "" || err != nil {
+		log.Printf("Prefix is not created yet, fonts are installed next launch\n")
+		return
+	}
+
+	fonts := make([]string, 0, len(configuration.Wine.Fonts))
+
+	for _, font := range configuration.Wine.Fonts {
+		fonts = append(fonts, config.ExpandUserPath(font, paths.HomeDir))
+	}
+
+	if err := prefix.InstallFonts(pfx, fonts, paths.AppConfigFolder, runnerWineBinary(configuration)); err != nil {
+		log.Printf("Failed to install fonts: %s\n", err)
+	}
+}
+
+// The wine of the Proton running the game, falling back to the system one
+func runnerWineBinary(configuration config.Configuration) string {
+	protonFolders := make([]string, 0, 2)
+
+	if configuration.Umu.Proton != "" {
+		protonFolders = append(protonFolders, configuration.Umu.Proton)
+	}
+
+	if toolPaths := os.Getenv("STEAM_COMPAT_TOOL_PATHS"); toolPaths != "" {
+		protonFolders = append(protonFolders, strings.Split(toolPaths, ":")[0])
+	}
+
+	for _, protonFolder := range protonFolders {
+		wine := filepath.Join(protonFolder, "files", "bin", "wine")
+
+		if _, err := os.Stat(wine); err == nil {
+			return wine
+		}
+	}
+
+	if wine, exists := wrappers.CheckIfBinExists(wrappers.WINE_BIN_NAME); exists {
+		return wine
+	}
+
+	return ""
+}
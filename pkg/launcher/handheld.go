@@ -0,0 +1,19 @@
+package launcher
+
+import (
+	"log"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/handheld"
+)
+
+// Presets are opt-in, running on a known handheld without one only points to it
+func suggestHandheldPreset(configuration config.Configuration) {
+	if configuration.Handheld.Preset != "" {
+		return
+	}
+
+	if preset, found := handheld.Detect(); found {
+		log.Printf("Running on a %s, set handheld.preset to %s (or %s) to use its defaults\n", preset.Device, preset.Name, handheld.PRESET_AUTO)
+	}
+}
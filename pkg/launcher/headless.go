@@ -0,0 +1,176 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const LOGINCTL_BIN_NAME = "loginctl"
+const OPENVT_BIN_NAME = "openvt"
+
+// Processes outside of any login session have this audit session id
+const NO_SESSION_ID = "4294967295"
+
+// SESSION_VARIABLES are what a launch needs from a graphical session to reach its display, audio and bus
+var SESSION_VARIABLES = []string{"DISPLAY", "WAYLAND_DISPLAY", "XAUTHORITY", "XDG_RUNTIME_DIR", "DBUS_SESSION_BUS_ADDRESS", "XDG_SESSION_TYPE", "XDG_CURRENT_DESKTOP", "PULSE_SERVER"}
+
+// GraphicalSession is a login session of the current user showing a desktop, which a headless launch, e.g. over
+// SSH, borrows the display of. Type, Seat, VT and Active come from logind and are empty without it.
+type GraphicalSession struct {
+	Id          string   `json:"id"`
+	Type        string   `json:"type"`
+	Seat        string   `json:"seat"`
+	VT          string   `json:"vt"`
+	Active      bool     `json:"active"`
+	Environment []string `json:"-"`
+}
+
+// FindGraphicalSessions lists the sessions of the current user with a display, the active ones first. Their
+// variables are read from the processes running in them, the session plauncher runs in is left out.
+func FindGraphicalSessions() []GraphicalSession {
+	ownSession := processSessionId(os.Getpid())
+	uid := os.Getuid()
+	sessions := make([]GraphicalSession, 0)
+
+	for _, processFolder := range listProcessFolders() {
+		pid, _ := strconv.Atoi(filepath.Base(processFolder))
+		id := processSessionId(pid)
+
+		if id == "" || id == NO_SESSION_ID || id == ownSession || slices.ContainsFunc(sessions, func(session GraphicalSession) bool { return session.Id == id }) {
+			continue
+		}
+
+		if info, err := os.Stat(processFolder); err != nil || !ownedBy(info, uid) {
+			continue
+		}
+
+		if environment := sessionEnvironment(pid); environment != nil {
+			sessions = append(sessions, describeSession(GraphicalSession{Id: id, Environment: environment}))
+		}
+	}
+
+	slices.SortStableFunc(sessions, func(a GraphicalSession, b GraphicalSession) int {
+		if a.Active == b.Active {
+			return strings.Compare(a.Id, b.Id)
+		}
+
+		if a.Active {
+			return -1
+		}
+
+		return 1
+	})
+
+	return sessions
+}
+
+// HeadlessCommand wraps command to run it on the virtual terminal vt, through openvt, switching to it. Without a
+// display to nest in, gamescope then drives the screen itself.
+func HeadlessCommand(vt int, command []string) ([]string, error) {
+	bin, exists := wrappers.CheckIfBinExists(OPENVT_BIN_NAME)
+
+	if !exists {
+		return nil, fmt.Errorf("%s is needed to launch on a virtual terminal, it comes with the kbd package", OPENVT_BIN_NAME)
+	}
+
+	return append([]string{bin, "-c", strconv.Itoa(vt), "-s", "--"}, command...), nil
+}
+
+func listProcessFolders() []string {
+	folders, _ := filepath.Glob("/proc/[0-9]*")
+
+	return folders
+}
+
+func processSessionId(pid int) string {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/sessionid", pid))
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
+
+func ownedBy(info os.FileInfo, uid int) bool {
+	stat, isStat := info.Sys().(*syscall.Stat_t)
+
+	return isStat && int(stat.Uid) == uid
+}
+
+// The session variables of the process pid, nil when it has no display
+func sessionEnvironment(pid int) []string {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+
+	if err != nil {
+		return nil
+	}
+
+	environment := make([]string, 0, len(SESSION_VARIABLES))
+	hasDisplay := false
+
+	for _, variable := range strings.Split(string(content), "\x00") {
+		name, value, _ := strings.Cut(variable, "=")
+
+		if !slices.Contains(SESSION_VARIABLES, name) || value == "" {
+			continue
+		}
+
+		// A display forwarded over SSH is not a screen to play on
+		if name == "DISPLAY" && strings.Contains(value, ":") && !strings.HasPrefix(value, ":") {
+			continue
+		}
+
+		hasDisplay = hasDisplay || name == "DISPLAY" || name == "WAYLAND_DISPLAY"
+		environment = append(environment, variable)
+	}
+
+	if !hasDisplay {
+		return nil
+	}
+
+	return environment
+}
+
+// Fills in what logind knows about session, when it is there
+func describeSession(session GraphicalSession) GraphicalSession {
+	bin, exists := wrappers.CheckIfBinExists(LOGINCTL_BIN_NAME)
+
+	if !exists {
+		return session
+	}
+
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(bin, "show-session", session.Id, "-p", "Type", "-p", "Seat", "-p", "VTNr", "-p", "Active"))
+
+	if err != nil {
+		return session
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+
+		switch key {
+		case "Type":
+			session.Type = value
+		case "Seat":
+			session.Seat = value
+		case "VTNr":
+			if value != "0" {
+				session.VT = value
+			}
+		case "Active":
+			session.Active = value == "yes"
+		}
+	}
+
+	return session
+}
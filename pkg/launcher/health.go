@@ -0,0 +1,130 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/session"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const PHASE_HEALTH = "health"
+
+// HealthError is a problem found before launching, Code is the exit code plauncher ends with.
+type HealthError struct {
+	Code    int
+	Message string
+}
+
+func (err *HealthError) Error() string {
+	return err.Message
+}
+
+// CheckHealth verifies the prefix structure, the game executable and the free space of the prefix drive,
+// so the launch fails with a specific message instead of a Proton error later on.
+func CheckHealth(configuration config.Configuration, paths config.Paths, gameArgs []string, timings *PhaseTimings) *HealthError {
+	if !configuration.Health.Enabled {
+		return nil
+	}
+
+	defer timings.Track(PHASE_HEALTH)()
+
+	prefixRoot := gamePrefixRoot(configuration, paths)
+
+	if problem := checkGameExecutable(gameArgs); problem != nil {
+		return problem
+	}
+
+	if prefixRoot == "" {
+		return nil
+	}
+
+	if problem := checkPrefixStructure(prefixRoot); problem != nil {
+		return problem
+	}
+
+	return checkFreeSpace(prefixRoot, configuration.Health.MinFreeMb)
+}
+
+// ReportHealthProblem shows the problem on the terminal and as a desktop notification, launches from Steam have no terminal.
+func ReportHealthProblem(problem *HealthError) {
+	fmt.Fprintf(os.Stderr, "plauncher: %s\n", problem.Message)
+	session.Notify(problem.Message)
+}
+
+// Windows executables given by absolute path must exist, other args may be anything the game understands
+func checkGameExecutable(gameArgs []string) *HealthError {
+	for _, arg := range gameArgs {
+		if !filepath.IsAbs(arg) || !strings.EqualFold(filepath.Ext(arg), ".exe") {
+			continue
+		}
+
+		if _, err := os.Stat(arg); errors.Is(err, os.ErrNotExist) {
+			return &HealthError{system.EXIT_GAME_START_FAILED, fmt.Sprintf("The game executable %s does not exist", arg)}
+		}
+	}
+
+	return nil
+}
+
+// A prefix wine has not created yet is left alone, Proton creates it on the first launch
+func checkPrefixStructure(prefixRoot string) *HealthError {
+	pfx := prefix.PfxFolder(prefixRoot)
+
+	if _, err := os.Stat(filepath.Join(pfx, "system.reg")); err != nil {
+		return nil
+	}
+
+	for _, folder := range []string{"dosdevices", "drive_c"} {
+		if info, err := os.Stat(filepath.Join(pfx, folder)); err != nil || !info.IsDir() {
+			return &HealthError{system.EXIT_PREFIX_ERROR, fmt.Sprintf("The prefix %s is broken, %s is missing", pfx, folder)}
+		}
+	}
+
+	userReg, err := os.Open(filepath.Join(pfx, "user.reg"))
+
+	if err != nil {
+		return &HealthError{system.EXIT_PREFIX_ERROR, fmt.Sprintf("The prefix %s is broken, user.reg cannot be read: %s", pfx, err)}
+	}
+
+	userReg.Close()
+
+	return nil
+}
+
+func checkFreeSpace(prefixRoot string, minFreeMb int) *HealthError {
+	if minFreeMb <= 0 {
+		return nil
+	}
+
+	// The prefix may not exist yet, the drive it will be created on is checked instead
+	folder := prefixRoot
+
+	for {
+		if _, err := os.Stat(folder); err == nil || filepath.Dir(folder) == folder {
+			break
+		}
+
+		folder = filepath.Dir(folder)
+	}
+
+	var stat syscall.Statfs_t
+
+	if err := syscall.Statfs(folder, &stat); err != nil {
+		return nil
+	}
+
+	freeMb := int64(stat.Bavail) * int64(stat.Bsize) / (1024 * 1024)
+
+	if freeMb < int64(minFreeMb) {
+		return &HealthError{system.EXIT_PREFIX_ERROR, fmt.Sprintf("Only %d MiB free on the drive of %s, at least %d MiB are needed (health.min-free-mb)", freeMb, prefixRoot, minFreeMb)}
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package launcher
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// PROC_INPUT_DEVICES lists the input devices the kernel knows, controllers are the ones with a joystick handler
+const PROC_INPUT_DEVICES = "/proc/bus/input/devices"
+
+// SDL, in the game or in Wine, only opens the controllers listed there, as 0xVENDOR/0xPRODUCT
+const SDL_CONTROLLER_ALLOWLIST_ENV = "SDL_GAMECONTROLLER_IGNORE_DEVICES_EXCEPT"
+
+// SDL_HIDAPI_ENV turns off the hidraw drivers of SDL, they would reach the controllers hidden from /dev/input
+const SDL_HIDAPI_ENV = "SDL_JOYSTICK_HIDAPI"
+
+const INPUT_DEVICES_FOLDER = "/dev/input"
+
+var controllerIdRegex = regexp.MustCompile(`^[0-9A-Fa-f]{4}:[0-9A-Fa-f]{4}$`)
+
+// inputController is a game controller as the kernel lists it, vendor and product in hexadecimal
+type inputController struct {
+	name     string
+	vendor   string
+	product  string
+	parent   string
+	handlers []string
+}
+
+func (controller inputController) id() string {
+	return controller.vendor + ":" + controller.product
+}
+
+// ApplyInstance moves an instance started with --instance to its own copy of the prefix and to its own controller,
+// returning command, run in bwrap when instances.isolation is devices. The first instance keeps the prefix of the
+// game, the gamescope wrapper sizes the window of each instance.
+func ApplyInstance(configuration *config.Configuration, command []string) []string {
+	instance := config.Instance(*configuration)
+
+	if instance == 0 {
+		return command
+	}
+
+	if instance > configuration.Instances.Count {
+		log.Printf("WARNING: instance %d is beyond instances.count %d, its window overlaps another one\n", instance, configuration.Instances.Count)
+	}
+
+	if instance > 1 {
+		cloneInstancePrefix(configuration, instance)
+	}
+
+	controller, found := assignController(configuration, instance)
+
+	if !found || configuration.Instances.Isolation != config.INSTANCES_ISOLATION_DEVICES {
+		return command
+	}
+
+	return isolateController(configuration, controller, instance, command)
+}
+
+func cloneInstancePrefix(configuration *config.Configuration, instance int) {
+	for _, key := range []string{"STEAM_COMPAT_DATA_PATH", "WINEPREFIX"} {
+		prefixRoot := configuration.Environment[key]
+
+		if prefixRoot == "" {
+			prefixRoot = os.Getenv(key)
+		}
+
+		if prefixRoot == "" {
+			continue
+		}
+
+		clone, err := prefix.ClonePrefix(prefixRoot, instance)
+
+		if err != nil {
+			system.Warnf(system.EXIT_PREFIX_ERROR, "%s, instance %d runs in the prefix of the first one\n", err, instance)
+			return
+		}
+
+		log.Printf("Instance %d runs in prefix %s\n", instance, clone)
+		configuration.Environment[key] = clone
+
+		return
+	}
+
+	log.Printf("No prefix to clone for instance %d, the game is native\n", instance)
+}
+
+func assignController(configuration *config.Configuration, instance int) (inputController, bool) {
+	if _, exists := configuration.Environment[SDL_CONTROLLER_ALLOWLIST_ENV]; exists {
+		log.Printf("%s is configured, leaving the controllers of instance %d alone\n", SDL_CONTROLLER_ALLOWLIST_ENV, instance)
+		return inputController{}, false
+	}
+
+	controllers := listControllers(listInputDevices())
+	selector := ""
+
+	if instance <= len(configuration.Instances.Controllers) {
+		selector = configuration.Instances.Controllers[instance-1]
+	}
+
+	controller, found := pickController(controllers, selector, instance)
+
+	if !found {
+		log.Printf("WARNING: no controller found for instance %d, it sees every controller\n", instance)
+		return inputController{}, false
+	}
+
+	log.Printf("Instance %d uses the controller %s (%s)\n", instance, controller.name, controller.id())
+	configuration.Environment[SDL_CONTROLLER_ALLOWLIST_ENV] = fmt.Sprintf("0x%s/0x%s", controller.vendor, controller.product)
+
+	if configuration.Instances.Isolation == config.INSTANCES_ISOLATION_DEVICES {
+		return controller, true
+	}
+
+	sameModel := 0
+
+	for _, other := range controllers {
+		if other.id() == controller.id() {
+			sameModel++
+		}
+	}
+
+	// SDL tells controllers apart by model only
+	if sameModel > 1 {
+		log.Printf("WARNING: %d controllers are a %s, instance %d sees all of them, instances.isolation: devices keeps them apart\n", sameModel, controller.id(), instance)
+	}
+
+	return controller, true
+}
+
+// Runs command in bwrap with an empty /dev/input holding only the event and joystick nodes of controller, and of
+// the devices of the same hardware (e.g. the touchpad and motion sensors of a DualSense)
+func isolateController(configuration *config.Configuration, controller inputController, instance int, command []string) []string {
+	bwrapBin, exists := wrappers.CheckIfBinExists(wrappers.BWRAP_BIN_NAME)
+
+	if !exists {
+		system.Warnf(system.EXIT_MISSING_DEPENDENCY, "instances.isolation devices needs %s, instance %d is isolated through SDL only\n", wrappers.BWRAP_BIN_NAME, instance)
+		return command
+	}
+
+	args := []string{bwrapBin, "--dev-bind", "/", "/", "--tmpfs", INPUT_DEVICES_FOLDER}
+
+	for _, device := range listInputDevices() {
+		if !slices.Equal(device.handlers, controller.handlers) && (device.parent == "" || device.parent != controller.parent) {
+			continue
+		}
+
+		for _, handler := range device.handlers {
+			if !strings.HasPrefix(handler, "event") && !strings.HasPrefix(handler, "js") {
+				continue
+			}
+
+			node := filepath.Join(INPUT_DEVICES_FOLDER, handler)
+			args = append(args, "--dev-bind", node, node)
+		}
+	}
+
+	log.Printf("Instance %d only sees the input devices of %s\n", instance, controller.name)
+	configuration.Environment[SDL_HIDAPI_ENV] = "0"
+
+	return append(append(args, "--"), command...)
+}
+
+// The controller named by selector, a part of its name, its vendor:product or one of its /dev/input nodes, or the
+// instance-th one without selector
+func pickController(controllers []inputController, selector string, instance int) (inputController, bool) {
+	if selector == "" {
+		if instance <= len(controllers) {
+			return controllers[instance-1], true
+		}
+
+		return inputController{}, false
+	}
+
+	if strings.HasPrefix(selector, INPUT_DEVICES_FOLDER+"/") {
+		if resolved, err := filepath.EvalSymlinks(selector); err == nil {
+			selector = resolved
+		}
+	}
+
+	index := slices.IndexFunc(controllers, func(controller inputController) bool {
+		if strings.HasPrefix(selector, INPUT_DEVICES_FOLDER+"/") {
+			return slices.Contains(controller.handlers, filepath.Base(selector))
+		}
+
+		if controllerIdRegex.MatchString(selector) {
+			return strings.EqualFold(controller.id(), selector)
+		}
+
+		return strings.Contains(strings.ToLower(controller.name), strings.ToLower(selector))
+	})
+
+	if index < 0 {
+		return inputController{}, false
+	}
+
+	return controllers[index], true
+}
+
+// Controllers are the devices with a joystick handler, in the order the kernel registered them, usually the order
+// they were connected in
+func listControllers(devices []inputController) []inputController {
+	return slices.DeleteFunc(devices, func(device inputController) bool {
+		return !slices.ContainsFunc(device.handlers, func(handler string) bool { return strings.HasPrefix(handler, "js") })
+	})
+}
+
+func listInputDevices() []inputController {
+	devices := make([]inputController, 0)
+	content, err := os.ReadFile(PROC_INPUT_DEVICES)
+
+	if err != nil {
+		log.Printf("Could not list the input devices: %s\n", err)
+		return devices
+	}
+
+	for _, block := range strings.Split(string(content), "\n\n") {
+		device := inputController{}
+
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "I: "):
+				for _, field := range strings.Fields(line[3:]) {
+					key, value, _ := strings.Cut(field, "=")
+
+					if key == "Vendor" {
+						device.vendor = strings.ToLower(value)
+					} else if key == "Product" {
+						device.product = strings.ToLower(value)
+					}
+				}
+			case strings.HasPrefix(line, "N: Name="):
+				device.name = strings.Trim(strings.TrimPrefix(line, "N: Name="), `"`)
+			case strings.HasPrefix(line, "S: Sysfs="):
+				// The input devices of one piece of hardware are the children of its USB or Bluetooth HID device
+				device.parent = filepath.Dir(filepath.Dir(strings.TrimPrefix(line, "S: Sysfs=")))
+			case strings.HasPrefix(line, "H: Handlers="):
+				device.handlers = strings.Fields(strings.TrimPrefix(line, "H: Handlers="))
+			}
+		}
+
+		if len(device.handlers) > 0 {
+			devices = append(devices, device)
+		}
+	}
+
+	return devices
+}
@@ -0,0 +1,356 @@
+// Package launcher ties the other plauncher packages into the launch pipeline:
+// resolve the configuration for a game, prepare its prefix, assemble the
+// wrapped command and run it between the user hooks.
+package launcher
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/hooks"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/session"
+	"github.com/fpetros1/linux-game-launcher/pkg/steam"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// Resolve reads the system and user configuration, applies the flags in args (argv, program name included),
+// identifies the Steam game and merges its overrides. It returns the configuration and the game command.
+func Resolve(paths config.Paths, args []string, timings *PhaseTimings) (config.Configuration, []string, error) {
+	doneConfig := timings.Track(PHASE_CONFIG)
+	userConfiguration, _ := config.LoadConfiguration(paths)
+	indexFirstNonFlagArg, enrichErr := config.EnrichConfigurationWithArgvFlags(&userConfiguration, args)
+	system.Strict = userConfiguration.Strict
+	doneConfig()
+
+	if enrichErr != nil {
+		return userConfiguration, nil, enrichErr
+	}
+
+	if err := applyTimeouts(userConfiguration); err != nil {
+		return userConfiguration, nil, err
+	}
+
+	nonFlagArgs := args[indexFirstNonFlagArg:]
+	nonFlagsArgsString := strings.Join(nonFlagArgs, " ")
+
+	oldSteamCompatData, isSteamLaunch := os.LookupEnv("STEAM_COMPAT_DATA_PATH")
+
+	if isSteamLaunch {
+		log.Println("Detected steam compat data variables")
+		log.Printf("Original Command: %s", nonFlagsArgsString)
+
+		doneMetadata := timings.Track(PHASE_METADATA)
+		steam.EnrichSteamAppIdByExe(&userConfiguration, nonFlagsArgsString)
+		steam.EnrichSteamAppIdByArgs(&userConfiguration, nonFlagsArgsString)
+		steam.EnrichGameName(&userConfiguration, paths.AppNamesCacheFolder)
+		handleRename(&userConfiguration, paths)
+		doneMetadata()
+	}
+
+	// Excluded games must not be touched at all, not even their compat data
+	if reason, excluded := config.ExcludedGame(userConfiguration); excluded {
+		log.Printf("Game %s, running it in pass-through mode\n", reason)
+		userConfiguration.SpecialFlags[config.PASSTHROUGH_FLAG] = true
+		return userConfiguration, nonFlagArgs, nil
+	}
+
+	doneConfig = timings.Track(PHASE_CONFIG)
+
+	if profile := userConfiguration.Props[config.PROFILE_FLAG]; profile != "" {
+		if err := config.ApplyProfile(&userConfiguration, paths, profile); err != nil {
+			return userConfiguration, nil, err
+		}
+	}
+
+	config.ApplyGameOverrides(&userConfiguration, config.OverrideFolders(paths)...)
+
+	if overlayFile := userConfiguration.Props[config.OVERLAY_PROP]; overlayFile != "" {
+		config.ApplyLaunchOverlay(&userConfiguration, overlayFile)
+	}
+
+	if err := config.ApplyEnvironmentOverrides(&userConfiguration, os.Environ()); err != nil {
+		return userConfiguration, nil, err
+	}
+
+	if settings := userConfiguration.FlagValues[config.SET_FLAG]; len(settings) > 0 {
+		if err := config.ApplyLaunchSettings(&userConfiguration, settings); err != nil {
+			return userConfiguration, nil, err
+		}
+	}
+
+	// What --save-name and --save-id write: without the one-shot --env variables, and before recipes and
+	// placeholders change it for this launch
+	savedConfiguration := config.CloneConfiguration(userConfiguration)
+
+	if variables := userConfiguration.FlagValues[config.ENV_FLAG]; len(variables) > 0 {
+		log.Printf("Applying environment from --%s: %s\n", config.ENV_FLAG, strings.Join(variables, " "))
+		config.ApplyLaunchEnvironment(&userConfiguration)
+	}
+
+	system.Strict = userConfiguration.Strict
+	doneConfig()
+
+	if err := applyTimeouts(userConfiguration); err != nil {
+		return userConfiguration, nil, err
+	}
+
+	if problems := config.ValidateConfiguration(userConfiguration, paths.ConfigurationFile); len(problems) > 0 {
+		return userConfiguration, nil, config.ConfigurationErrors(problems)
+	}
+
+	checkAnticheat(&userConfiguration, paths)
+	suggestHandheldPreset(userConfiguration)
+
+	if config.IsPassthrough(userConfiguration) {
+		log.Println("Pass-through mode requested, running the game command untouched")
+		return userConfiguration, nonFlagArgs, nil
+	}
+
+	config.ProcessSpecialFlags(savedConfiguration.SpecialFlags, savedConfiguration, paths.OverridesFolder)
+
+	nonFlagArgs = applyRecipe(&userConfiguration, paths, nonFlagArgs)
+
+	if isSteamLaunch && userConfiguration.Props["name"] == "" {
+		log.Println("Game name unknown, leaving the compat data where Steam put it")
+	} else if isSteamLaunch && userConfiguration.CompatData.Mode != config.COMPATDATA_MODE_MIRROR {
+		doneCompatData := timings.Track(PHASE_COMPATDATA)
+		prefix.ConfigureNewSteamCompatData(&userConfiguration, oldSteamCompatData, config.CompatDataBase(paths, userConfiguration))
+		doneCompatData()
+	}
+
+	wrappers.EnrichUmuStore(&userConfiguration, nonFlagArgs)
+
+	return userConfiguration, nonFlagArgs, nil
+}
+
+// Hands the timeouts section to the bounded command runners
+func applyTimeouts(configuration config.Configuration) error {
+	durations := map[string]string{
+		system.TIMEOUT_WINETRICKS: configuration.Timeouts.Winetricks,
+		system.TIMEOUT_LEGENDARY:  configuration.Timeouts.Legendary,
+		system.TIMEOUT_PROBE:      configuration.Timeouts.Probe,
+	}
+
+	for kind, text := range durations {
+		if text == "" {
+			continue
+		}
+
+		timeout, err := time.ParseDuration(text)
+
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("Invalid timeouts.%s %s, expected a duration like 10m", kind, text)
+		}
+
+		system.Timeouts[kind] = timeout
+	}
+
+	return nil
+}
+
+// Warns about, or switches to pass-through mode, games whose anticheat rejects the enabled wrappers
+func checkAnticheat(configuration *config.Configuration, paths config.Paths) {
+	if configuration.Anticheat.Action == config.ANTICHEAT_ACTION_IGNORE || config.IsPassthrough(*configuration) {
+		return
+	}
+
+	if !configuration.Gamescope.Enabled && !configuration.Mangohud.Enabled {
+		return
+	}
+
+	game, exists := steam.FindAnticheatGame(configuration.Props["id"], paths.SystemAnticheatFile, paths.AnticheatFile)
+
+	if !exists {
+		return
+	}
+
+	if configuration.Anticheat.Action == config.ANTICHEAT_ACTION_PASSTHROUGH {
+		log.Printf("%s uses %s, running it in pass-through mode\n", game.Name, game.Anticheat)
+		configuration.SpecialFlags[config.PASSTHROUGH_FLAG] = true
+		return
+	}
+
+	log.Printf("WARNING: %s uses %s, which is known to break with gamescope and mangohud\n", game.Name, game.Anticheat)
+	fmt.Fprintf(os.Stderr, "plauncher: %s uses %s, which is known to break with gamescope and mangohud\n", game.Name, game.Anticheat)
+}
+
+// PreparePrefix applies the prefix level settings of the configuration, and the dependencies of the game in gameArgs.
+func PreparePrefix(configuration config.Configuration, paths config.Paths, gameArgs []string, timings *PhaseTimings) {
+	defer timings.Track(PHASE_EOS)()
+
+	prefix.SetupEosInPrefix(configuration, paths.AppDataFolder)
+	applyRecipeVerbs(configuration, paths)
+	applyFonts(configuration, paths)
+	applyWindowsVersion(configuration, paths)
+	checkGameUpdate(configuration, paths, gameArgs)
+	applyDependencies(configuration, paths, gameArgs)
+	//prefix.SetupWineConfigInPrefix(configuration, paths.CompatDataBase)
+}
+
+// RunPrepareSteps runs the prepare steps of the configuration, with the game environment.
+func RunPrepareSteps(configuration config.Configuration, paths config.Paths, timings *PhaseTimings) error {
+	defer timings.Track(PHASE_PREPARE)()
+
+	_, err := hooks.RunPrepareSteps(configuration.Prepare, paths.ScriptsFolder, BuildEnvironment(configuration))
+
+	return err
+}
+
+// BuildEnvironment returns the current process environment extended with the configured variables.
+func BuildEnvironment(configuration config.Configuration) []string {
+	newEnviron := os.Environ()
+
+	for key, value := range configuration.Environment {
+		newEnviron = append(newEnviron, fmt.Sprintf("%s=%s", key, os.ExpandEnv(value)))
+	}
+
+	return newEnviron
+}
+
+// MAX_WATCHDOG_RESTARTS bounds how many times a session is restarted for the watchdog.
+const MAX_WATCHDOG_RESTARTS = 3
+
+// Execute runs command between the pre and post scripts, reporting its lifecycle to events.
+func Execute(configuration config.Configuration, paths config.Paths, command []string, environment []string, events *EventStream) error {
+	hooks.ExecuteScripts(configuration.PreScripts, paths.ScriptsFolder)
+
+	restart, err := runSession(configuration, paths, command, environment, events)
+
+	for restarts := 0; restart && restarts < MAX_WATCHDOG_RESTARTS; restarts++ {
+		log.Println("Restarting the game, requested by the watchdog")
+		restart, err = runSession(configuration, paths, command, environment, events)
+	}
+
+	hooks.ExecuteScripts(configuration.PostScripts, paths.ScriptsFolder)
+
+	return err
+}
+
+// Runs command along with the session services, reporting whether one of them asked for a restart
+func runSession(configuration config.Configuration, paths config.Paths, command []string, environment []string, events *EventStream) (bool, error) {
+	cmdHandle := exec.Command(command[0], command[1:]...)
+	cmdHandle.Env = environment
+
+	log.Printf("Executing: %s\n", command)
+
+	var out bytes.Buffer
+	cmdHandle.Stdout = &out
+
+	// Wine debug output goes to stderr, keep it with the game output instead of losing it
+	if configuration.Wine.Debug != "" {
+		if sessionLog, err := openSessionLog(configuration, paths); err == nil {
+			log.Printf("Writing game output to: %s\n", sessionLog.Name())
+			cmdHandle.Stdout = sessionLog
+			cmdHandle.Stderr = sessionLog
+			defer sessionLog.Close()
+		}
+	}
+
+	services := session.Start(&configuration, paths)
+	pid, err := system.Exec.Start(cmdHandle)
+
+	if err != nil {
+		log.Printf("Command failed to start: %s", err)
+		session.Stop(services)
+		events.Emit(EVENT_GAME_EXITED, map[string]any{"code": -1, "error": err.Error()})
+		return false, err
+	}
+
+	events.Emit(EVENT_GAME_STARTED, map[string]any{"pid": pid})
+	session.GameStarted(services, pid)
+
+	exitCode, err := system.Exec.Wait(cmdHandle)
+	session.Stop(services)
+	restart := session.RestartRequested(services)
+
+	if err != nil {
+		log.Printf("Command stopped: %s. Error: %s", out.Bytes(), err)
+		events.Emit(EVENT_GAME_EXITED, map[string]any{"code": exitCode, "error": err.Error()})
+		return restart, err
+	}
+
+	events.Emit(EVENT_GAME_EXITED, map[string]any{"code": exitCode})
+
+	return restart, nil
+}
+
+// Passthrough runs command with the environment plauncher was started with, without wrappers or scripts.
+func Passthrough(command []string) error {
+	cmdHandle := exec.Command(command[0], command[1:]...)
+	cmdHandle.Stdout = os.Stdout
+	cmdHandle.Stderr = os.Stderr
+
+	log.Printf("Executing untouched: %s\n", command)
+
+	if _, err := system.Exec.Start(cmdHandle); err != nil {
+		log.Printf("Command failed to start: %s", err)
+		return err
+	}
+
+	if _, err := system.Exec.Wait(cmdHandle); err != nil {
+		log.Printf("Command stopped. Error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePrefixManifest refreshes the manifest of the prefix used by this launch when plauncher manages it.
+func UpdatePrefixManifest(configuration config.Configuration, paths config.Paths) {
+	if prefixRoot, steamCompatData := managedPrefixRoot(configuration, paths); prefixRoot != "" {
+		prefix.UpdateManifest(prefixRoot, configuration, steamCompatData)
+	}
+}
+
+// LinkPrefix adds the prefix used by this launch to the home-shortcuts.prefix-links folder, when configured.
+func LinkPrefix(configuration config.Configuration, paths config.Paths) {
+	if configuration.HomeShortcuts.PrefixLinks == "" || configuration.Props["name"] == "" {
+		return
+	}
+
+	if prefixRoot, _ := managedPrefixRoot(configuration, paths); prefixRoot != "" {
+		prefix.LinkPrefix(config.ExpandUserPath(configuration.HomeShortcuts.PrefixLinks, paths.HomeDir), config.GameSlug(configuration.Props["name"]), prefixRoot)
+	}
+}
+
+// Returns the managed prefix of this launch, along with the Steam compat data path linking to it, if any
+func managedPrefixRoot(configuration config.Configuration, paths config.Paths) (string, string) {
+	prefixRoot := configuration.Environment["STEAM_COMPAT_DATA_PATH"]
+	steamCompatData := os.Getenv("STEAM_COMPAT_DATA_PATH")
+
+	if prefixRoot == "" {
+		prefixRoot = configuration.Environment["WINEPREFIX"]
+		steamCompatData = ""
+	}
+
+	if prefixRoot == "" || !strings.HasPrefix(prefixRoot, config.CompatDataBase(paths, configuration)) {
+		return "", ""
+	}
+
+	if _, err := os.Stat(prefixRoot); err != nil {
+		return "", ""
+	}
+
+	return prefixRoot, steamCompatData
+}
+
+// MirrorPrefix syncs the Steam compat data to its mirror when compatdata.mode is mirror.
+func MirrorPrefix(configuration config.Configuration, paths config.Paths) {
+	compatData, exists := os.LookupEnv("STEAM_COMPAT_DATA_PATH")
+
+	if !exists || configuration.CompatData.Mode != config.COMPATDATA_MODE_MIRROR || configuration.Props["name"] == "" {
+		return
+	}
+
+	if err := prefix.MirrorSteamCompatData(configuration, compatData, paths.MirrorsFolder); err != nil {
+		log.Printf("Failed to mirror compat data: %s\n", err)
+	}
+}
@@ -0,0 +1,69 @@
+package launcher
+
+import (
+	"log"
+	"os"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// ExpandPlaceholders replaces the config.PLACEHOLDERS, e.g. ${GAME_NAME} or ${PREFIX}, in the environment values,
+// --env variables included, and the gamescope and umu arguments, once the game and its prefix are known. Other variables are left to the process
+// environment, as before.
+func ExpandPlaceholders(configuration *config.Configuration, paths config.Paths) {
+	values := placeholderValues(*configuration, paths)
+
+	for key, value := range configuration.Environment {
+		configuration.Environment[key] = replacePlaceholders(value, values)
+	}
+
+	// Kept expanded for the --env variables applied again before the launch
+	for index, variable := range configuration.FlagValues[config.ENV_FLAG] {
+		configuration.FlagValues[config.ENV_FLAG][index] = replacePlaceholders(variable, values)
+	}
+
+	// Arguments are split into words later, the values are escaped to stay within theirs
+	for index, arg := range configuration.Gamescope.Args {
+		configuration.Gamescope.Args[index] = wrappers.ReplaceShellVariables(arg, values)
+	}
+
+	for index, arg := range configuration.Umu.Args {
+		configuration.Umu.Args[index] = wrappers.ReplaceShellVariables(arg, values)
+	}
+}
+
+func placeholderValues(configuration config.Configuration, paths config.Paths) map[string]string {
+	prefixRoot := configuration.Environment["STEAM_COMPAT_DATA_PATH"]
+
+	if prefixRoot == "" {
+		prefixRoot = os.Getenv("STEAM_COMPAT_DATA_PATH")
+	}
+
+	if prefixRoot == "" {
+		prefixRoot = configuration.Environment["WINEPREFIX"]
+	}
+
+	values := map[string]string{
+		"GAME_NAME":  configuration.Props["name"],
+		"GAME_ID":    configuration.Props["id"],
+		"GAME_SLUG":  config.GameSlug(configuration.Props["name"]),
+		"PREFIX":     prefixRoot,
+		"CONFIG_DIR": paths.AppConfigFolder,
+	}
+
+	log.Printf("Placeholders: %v\n", values)
+
+	return values
+}
+
+// Replaces the $VAR and ${VAR} of value found in values, keeping the others for the process environment
+func replacePlaceholders(value string, values map[string]string) string {
+	return os.Expand(value, func(name string) string {
+		if replacement, found := values[name]; found {
+			return replacement
+		}
+
+		return "${" + name + "}"
+	})
+}
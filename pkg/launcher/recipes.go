@@ -0,0 +1,83 @@
+package launcher
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/recipes"
+)
+
+// Adds the environment variables and launch args of the game recipe, the configured environment wins
+func applyRecipe(configuration *config.Configuration, paths config.Paths, gameArgs []string) []string {
+	recipe, exists := findRecipe(*configuration, paths)
+
+	if !exists {
+		return gameArgs
+	}
+
+	log.Printf("Applying recipe of %s: %s\n", recipe.Name, recipe.Notes)
+
+	for key, value := range recipe.Environment {
+		if _, configured := configuration.Environment[key]; !configured {
+			configuration.Environment[key] = value
+		}
+	}
+
+	for _, arg := range recipe.LaunchArgs {
+		if !slices.Contains(gameArgs, arg) {
+			gameArgs = append(gameArgs, arg)
+		}
+	}
+
+	return gameArgs
+}
+
+// Installs the winetricks verbs of the game recipe, prefixes Proton did not create yet get them next launch
+func applyRecipeVerbs(configuration config.Configuration, paths config.Paths) {
+	recipe, exists := findRecipe(configuration, paths)
+
+	if !exists || len(recipe.Winetricks) == 0 {
+		return
+	}
+
+	prefixRoot := gamePrefixRoot(configuration, paths)
+	pfx := prefix.PfxFolder(prefixRoot)
+
+	if _, err := os.Stat(filepath.Join(pfx, "system.reg")); prefixRoot == "" || err != nil {
+		log.Printf("Prefix of %s is not created yet, its recipe verbs are installed next launch\n", recipe.Name)
+		return
+	}
+
+	if err := prefix.InstallWinetricksVerbs(pfx, recipe.Winetricks); err != nil {
+		log.Printf("Failed to install the recipe verbs of %s: %s\n", recipe.Name, err)
+	}
+}
+
+func findRecipe(configuration config.Configuration, paths config.Paths) (recipes.Recipe, bool) {
+	if !configuration.Recipes.Enabled {
+		return recipes.Recipe{}, false
+	}
+
+	return recipes.FindRecipe(configuration.Props["id"], recipes.RecipeFiles(paths)...)
+}
+
+// Steam prefixes are known once relocated, umu ones are named after the game
+func gamePrefixRoot(configuration config.Configuration, paths config.Paths) string {
+	if compatData := configuration.Environment["STEAM_COMPAT_DATA_PATH"]; compatData != "" {
+		return compatData
+	}
+
+	if compatData := os.Getenv("STEAM_COMPAT_DATA_PATH"); compatData != "" {
+		return compatData
+	}
+
+	if configuration.Umu.Enabled && configuration.Props["name"] != "" {
+		return filepath.Join(config.CompatDataBase(paths, configuration), config.GameSlug(configuration.Props["name"]))
+	}
+
+	return ""
+}
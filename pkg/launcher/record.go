@@ -0,0 +1,116 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// LaunchRecord is the fully resolved command and environment of a launch.
+type LaunchRecord struct {
+	Name        string    `json:"name"`
+	Id          string    `json:"id"`
+	WorkingDir  string    `json:"working-dir"`
+	Command     []string  `json:"command"`
+	Environment []string  `json:"environment"`
+	Version     string    `json:"version"`
+	CreatedAt   time.Time `json:"created-at"`
+}
+
+// SaveLaunchRecord stores the launch under the game name and id so it can be exported later.
+func SaveLaunchRecord(configuration config.Configuration, command []string, environment []string, version string, launchesFolder string) {
+	workingDir, _ := os.Getwd()
+
+	record := LaunchRecord{
+		configuration.Props["name"],
+		configuration.Props["id"],
+		workingDir,
+		command,
+		environment,
+		version,
+		time.Now(),
+	}
+
+	recordJson, err := json.MarshalIndent(record, "", "  ")
+
+	if err != nil {
+		log.Printf("Failed to serialize launch record: %s\n", err)
+		return
+	}
+
+	// Records written before they were private keep their mode on rewrite, hence the chmods
+	system.FS.Chmod(launchesFolder, config.PRIVATE_PERMISSION)
+
+	for _, key := range []string{record.Name, record.Id} {
+		if key == "" {
+			continue
+		}
+
+		recordFile := filepath.Join(launchesFolder, config.GameSlug(key)+".json")
+
+		if err := system.FS.WriteFile(recordFile, recordJson, config.PRIVATE_FILE_PERMISSION); err != nil {
+			log.Printf("Failed to write launch record %s: %s\n", recordFile, err)
+			continue
+		}
+
+		system.FS.Chmod(recordFile, config.PRIVATE_FILE_PERMISSION)
+	}
+}
+
+// ReadLaunchRecord reads the last launch recorded for game, by name or id.
+func ReadLaunchRecord(game string, launchesFolder string) (LaunchRecord, error) {
+	record := LaunchRecord{}
+	recordFile := filepath.Join(launchesFolder, config.GameSlug(game)+".json")
+	recordJson, err := os.ReadFile(recordFile)
+
+	if err != nil {
+		return record, fmt.Errorf("No launch recorded for %s, launch it through %s at least once: %s", game, config.APP_NAME, err)
+	}
+
+	if err := json.Unmarshal(recordJson, &record); err != nil {
+		return record, fmt.Errorf("Launch record %s is not valid JSON: %s", recordFile, err)
+	}
+
+	return record, nil
+}
+
+// BuildLaunchScript renders record as a standalone POSIX shell script.
+func BuildLaunchScript(record LaunchRecord) string {
+	var script strings.Builder
+
+	script.WriteString("#!/bin/sh\n")
+	script.WriteString(fmt.Sprintf("# Generated by %s %s from the launch recorded at %s\n", config.APP_NAME, record.Version, record.CreatedAt.Format(time.RFC3339)))
+	script.WriteString(fmt.Sprintf("# Game: %s (id: %s)\n\n", record.Name, record.Id))
+
+	if record.WorkingDir != "" {
+		script.WriteString(fmt.Sprintf("cd %s || exit 1\n\n", ShellQuote(record.WorkingDir)))
+	}
+
+	script.WriteString("exec env -i \\\n")
+
+	for _, variable := range record.Environment {
+		script.WriteString(fmt.Sprintf("\t%s \\\n", ShellQuote(variable)))
+	}
+
+	quotedCommand := make([]string, 0, len(record.Command))
+
+	for _, arg := range record.Command {
+		quotedCommand = append(quotedCommand, ShellQuote(arg))
+	}
+
+	script.WriteString(fmt.Sprintf("\t%s \"$@\"\n", strings.Join(quotedCommand, " ")))
+
+	return script.String()
+}
+
+// ShellQuote single-quotes value for POSIX shells.
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
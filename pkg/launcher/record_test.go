@@ -0,0 +1,35 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+)
+
+func TestSaveLaunchRecordIsPrivate(t *testing.T) {
+	launchesFolder := filepath.Join(t.TempDir(), "launches")
+	recordFile := filepath.Join(launchesFolder, config.GameSlug("Hades")+".json")
+
+	// As written before records were private
+	os.MkdirAll(launchesFolder, config.DEFAULT_PERMISSION)
+	os.WriteFile(recordFile, []byte("{}"), config.DEFAULT_PERMISSION)
+
+	configuration := config.DefaultConfiguration()
+	configuration.Props["name"] = "Hades"
+
+	SaveLaunchRecord(configuration, []string{"Hades.exe"}, []string{"TOKEN=hunter2"}, "dev", launchesFolder)
+
+	for file, expected := range map[string]os.FileMode{launchesFolder: config.PRIVATE_PERMISSION, recordFile: config.PRIVATE_FILE_PERMISSION} {
+		info, err := os.Stat(file)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Mode().Perm() != expected {
+			t.Errorf("%s has mode %o, expected %o", file, info.Mode().Perm(), expected)
+		}
+	}
+}
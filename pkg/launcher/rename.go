@@ -0,0 +1,88 @@
+package launcher
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/steam"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// MigrateRename moves the user override and the prefixes named after the old name of a renamed game to its new
+// name, then caches the new name. Returns what was moved, as "from -> to".
+func MigrateRename(paths config.Paths, configuration config.Configuration, rename steam.Rename) ([]string, error) {
+	moved := make([]string, 0)
+	oldOverride := config.OverrideFile(paths, rename.OldName)
+	newOverride := config.OverrideFile(paths, rename.NewName)
+
+	if _, err := os.Stat(oldOverride); err == nil && oldOverride != newOverride {
+		if _, err := os.Stat(newOverride); err == nil {
+			return moved, fmt.Errorf("Both %s and %s exist, merge them by hand", oldOverride, newOverride)
+		}
+
+		if err := system.FS.Rename(oldOverride, newOverride); err != nil {
+			return moved, err
+		}
+
+		moved = append(moved, oldOverride+" -> "+newOverride)
+	}
+
+	for _, base := range config.CompatDataBases(paths) {
+		managedPrefixes, _ := prefix.ListManagedPrefixes(base)
+
+		for _, managedPrefix := range managedPrefixes {
+			if filepath.Base(managedPrefix.Folder) != config.GameSlug(rename.OldName) && (managedPrefix.Manifest.Id != rename.Appid || managedPrefix.Manifest.Name != rename.OldName) {
+				continue
+			}
+
+			newFolder, err := prefix.RenamePrefix(managedPrefix, rename.NewName)
+
+			if err != nil {
+				return moved, err
+			}
+
+			if newFolder != managedPrefix.Folder {
+				moved = append(moved, managedPrefix.Folder+" -> "+newFolder)
+			}
+		}
+	}
+
+	return moved, steam.CacheRename(rename, paths.AppNamesCacheFolder, configuration.Metadata)
+}
+
+// Steam renames games now and then, their files keep the cached name until migrated, by metadata.renames or
+// plauncher overrides renames
+func handleRename(configuration *config.Configuration, paths config.Paths) {
+	if configuration.Metadata.Renames == config.METADATA_RENAMES_IGNORE {
+		return
+	}
+
+	rename, renamed := steam.FindRename(configuration.Props["steam-appid"], paths.HomeDir, paths.AppNamesCacheFolder, configuration.Metadata)
+
+	if !renamed {
+		return
+	}
+
+	if configuration.Metadata.Renames != config.METADATA_RENAMES_MIGRATE {
+		system.Warnf(system.EXIT_CONFIG_ERROR, "%s is now called %s on Steam, its override and prefix keep the old name until plauncher overrides renames --apply\n", rename.OldName, rename.NewName)
+		return
+	}
+
+	moved, err := MigrateRename(paths, *configuration, rename)
+
+	for _, move := range moved {
+		log.Printf("Renamed %s\n", move)
+	}
+
+	if err != nil {
+		system.Warnf(system.EXIT_CONFIG_ERROR, "Failed to migrate %s to its new name %s: %s\n", rename.OldName, rename.NewName, err)
+		return
+	}
+
+	log.Printf("%s is now called %s\n", rename.OldName, rename.NewName)
+	configuration.Props["name"] = rename.NewName
+}
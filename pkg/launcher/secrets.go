@@ -0,0 +1,154 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+	"gopkg.in/yaml.v3"
+)
+
+const PASS_BIN_NAME = "pass"
+const SECRET_TOOL_BIN_NAME = "secret-tool"
+
+// Attribute the Secret Service items of plauncher are stored under, secret-tool store --label=NAME plauncher NAME
+const SECRET_SERVICE_ATTRIBUTE = "plauncher"
+
+// ResolveSecrets returns environment with its !secret values looked up, for the game process alone: the
+// configuration, the debug log, dry runs and launch records keep the references.
+func ResolveSecrets(configuration config.Configuration, paths config.Paths, environment []string) ([]string, error) {
+	resolved := slices.Clone(environment)
+	var secretsFile map[string]string
+
+	for index, variable := range resolved {
+		key, value, _ := strings.Cut(variable, "=")
+		reference, isSecret := config.ParseSecretReference(value, configuration.Secrets.Provider)
+
+		if !isSecret {
+			continue
+		}
+
+		var secret string
+		var err error
+
+		switch reference.Provider {
+		case config.SECRET_PROVIDER_FILE:
+			if secretsFile == nil {
+				secretsFile, err = readSecretsFile(secretsFilePath(configuration, paths))
+			}
+
+			if err == nil {
+				secret, err = lookupSecretsFile(secretsFile, reference.Name)
+			}
+		case config.SECRET_PROVIDER_PASS:
+			secret, err = lookupPass(reference.Name)
+		case config.SECRET_PROVIDER_SECRET_SERVICE:
+			secret, err = lookupSecretService(reference.Name)
+		default:
+			err = fmt.Errorf("unknown provider %s", reference.Provider)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to look up %s for %s: %w", reference, key, err)
+		}
+
+		log.Printf("Using %s for %s\n", reference, key)
+		resolved[index] = key + "=" + secret
+	}
+
+	return resolved, nil
+}
+
+func secretsFilePath(configuration config.Configuration, paths config.Paths) string {
+	file := configuration.Secrets.File
+
+	if file == "" {
+		file = config.DEFAULT_SECRETS_FILE
+	}
+
+	file = config.ExpandUserPath(file, paths.HomeDir)
+
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(paths.AppConfigFolder, file)
+	}
+
+	return file
+}
+
+func readSecretsFile(file string) (map[string]string, error) {
+	content, err := os.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens are not something the rest of the machine should read
+	if info, err := os.Stat(file); err == nil && info.Mode().Perm()&0o077 != 0 {
+		log.Printf("Secrets file %s can be read by other users, chmod 600 it\n", file)
+	}
+
+	secrets := make(map[string]string)
+
+	if err := yaml.Unmarshal(content, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", file, err)
+	}
+
+	return secrets, nil
+}
+
+func lookupSecretsFile(secrets map[string]string, name string) (string, error) {
+	secret, found := secrets[name]
+
+	if !found {
+		return "", errors.New("not in the secrets file")
+	}
+
+	return secret, nil
+}
+
+// The first line of the pass entry name, the password by its convention
+func lookupPass(name string) (string, error) {
+	output, err := runSecretTool(PASS_BIN_NAME, "show", name)
+
+	if err != nil {
+		return "", err
+	}
+
+	secret, _, _ := strings.Cut(output, "\n")
+
+	return secret, nil
+}
+
+func lookupSecretService(name string) (string, error) {
+	output, err := runSecretTool(SECRET_TOOL_BIN_NAME, "lookup", SECRET_SERVICE_ATTRIBUTE, name)
+
+	if err == nil && output == "" {
+		err = fmt.Errorf("no item with the attribute %s %s", SECRET_SERVICE_ATTRIBUTE, name)
+	}
+
+	return strings.TrimSuffix(output, "\n"), err
+}
+
+func runSecretTool(binName string, args ...string) (string, error) {
+	bin, exists := wrappers.CheckIfBinExists(binName)
+
+	if !exists {
+		return "", fmt.Errorf("%s is not installed", binName)
+	}
+
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(bin, args...))
+
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", binName, err)
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,69 @@
+package launcher
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+func TestSimulatedLaunch(t *testing.T) {
+	executor, fileSystem := system.Exec, system.FS
+	defer func() { system.Exec, system.FS = executor, fileSystem }()
+
+	var out bytes.Buffer
+	system.Simulate(&out)
+
+	base := t.TempDir()
+	paths := config.Paths{
+		HomeDir:           base,
+		UserConfigDir:     filepath.Join(base, "config"),
+		CompatDataBase:    filepath.Join(base, "compatdata"),
+		UmuIdsCacheFolder: filepath.Join(base, "umu-ids"),
+		ScriptsFolder:     filepath.Join(base, "scripts"),
+		ControlFolder:     filepath.Join(base, "control"),
+	}
+
+	configuration := config.DefaultConfiguration()
+	configuration.Props["name"] = "Hades"
+	configuration.Control.Enabled = false
+	configuration.Umu.Enabled = true
+	configuration.Umu.Proton = t.TempDir()
+	configuration.Umu.GameId = "umu-1145360"
+
+	if compatData, found := os.LookupEnv("STEAM_COMPAT_DATA_PATH"); found {
+		os.Unsetenv("STEAM_COMPAT_DATA_PATH")
+		defer os.Setenv("STEAM_COMPAT_DATA_PATH", compatData)
+	}
+
+	command := wrappers.BuildCommand(&configuration, paths, []string{"/games/hades/Hades.exe"})
+
+	if err := Execute(configuration, paths, command, BuildEnvironment(configuration), nil); err != nil {
+		t.Fatalf("Execute: %s", err)
+	}
+
+	prefixFolder := config.GameFolder(paths.CompatDataBase, "Hades")
+	recorded := out.String()
+
+	for _, expected := range []string{
+		system.SIMULATE_PREFIX + " mkdir -p " + prefixFolder + "\n",
+		system.SIMULATE_PREFIX + " exec: " + strings.Join(command, " ") + "\n",
+	} {
+		if !strings.Contains(recorded, expected) {
+			t.Errorf("%q not recorded in:\n%s", expected, recorded)
+		}
+	}
+
+	if len(command) < 3 || filepath.Base(command[0]) != wrappers.GAMEMODE_BIN_NAME || filepath.Base(command[1]) != wrappers.UMU_RUN_BIN_NAME {
+		t.Errorf("unexpected command %q, expected gamemoderun then umu-run before the game", command)
+	}
+
+	if _, err := os.Stat(prefixFolder); !os.IsNotExist(err) {
+		t.Errorf("the simulation created %s: %v", prefixFolder, err)
+	}
+}
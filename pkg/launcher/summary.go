@@ -0,0 +1,169 @@
+package launcher
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/session"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// Warning channels listed in the summary, the most frequent first
+const SUMMARY_TOP_WARNINGS = 3
+
+// "0024:err:module:import_dll Library MSVCP140.dll not found"
+var wineWarningRegex = regexp.MustCompile(`\b(err|warn):([A-Za-z0-9_]+):`)
+
+// SessionSummary gathers the key facts of a finished session, for the end of the debug log.
+type SessionSummary struct {
+	Game          string
+	Duration      time.Duration
+	ExitCode      int
+	Error         string
+	Wrappers      []string
+	ProtonVersion string
+	Warnings      []LogWarning
+}
+
+// LogWarning counts the lines a wine channel, e.g. err:module, logged during the session.
+type LogWarning struct {
+	Channel string
+	Count   int
+}
+
+// BuildSessionSummary summarizes the session started at startedAt and ended with err.
+func BuildSessionSummary(configuration config.Configuration, paths config.Paths, startedAt time.Time, err error) SessionSummary {
+	summary := SessionSummary{
+		Game:          configuration.Props["name"],
+		Duration:      time.Since(startedAt).Round(time.Second),
+		ExitCode:      system.GameExitCode(err),
+		Wrappers:      make([]string, 0),
+		ProtonVersion: sessionProtonVersion(configuration, paths),
+		Warnings:      sessionWarnings(config.GameLogsFolder(paths, configuration), startedAt),
+	}
+
+	if summary.Game == "" {
+		summary.Game = configuration.Props["id"]
+	}
+
+	if err != nil {
+		summary.Error = err.Error()
+	}
+
+	for _, wrapper := range wrappers.Registered() {
+		if _, enabled := wrapper.Detect(&configuration); enabled {
+			summary.Wrappers = append(summary.Wrappers, wrapper.Name())
+		}
+	}
+
+	return summary
+}
+
+// ReportSessionSummary writes summary to the debug log, and as a desktop notification when summary.notify is set.
+func ReportSessionSummary(configuration config.Configuration, summary SessionSummary) {
+	log.Printf("Session summary:\n%s", summary)
+
+	if configuration.Summary.Notify {
+		session.Notify(fmt.Sprintf("%s: played %s, exit code %d", summary.Game, summary.Duration, summary.ExitCode))
+	}
+}
+
+func (summary SessionSummary) String() string {
+	lines := []string{
+		fmt.Sprintf("  game: %s", summary.Game),
+		fmt.Sprintf("  duration: %s", summary.Duration),
+		fmt.Sprintf("  exit code: %d", summary.ExitCode),
+	}
+
+	if summary.Error != "" {
+		lines = append(lines, fmt.Sprintf("  error: %s", summary.Error))
+	}
+
+	if len(summary.Wrappers) == 0 {
+		lines = append(lines, "  wrappers: none")
+	} else {
+		lines = append(lines, fmt.Sprintf("  wrappers: %s", strings.Join(summary.Wrappers, ", ")))
+	}
+
+	if summary.ProtonVersion != "" {
+		lines = append(lines, fmt.Sprintf("  proton: %s", summary.ProtonVersion))
+	}
+
+	for _, warning := range summary.Warnings {
+		lines = append(lines, fmt.Sprintf("  %s: %d lines", warning.Channel, warning.Count))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Proton records the version that last ran the prefix in its root, umu runs the configured one
+func sessionProtonVersion(configuration config.Configuration, paths config.Paths) string {
+	if prefixRoot := gamePrefixRoot(configuration, paths); prefixRoot != "" {
+		if version, err := os.ReadFile(filepath.Join(prefixRoot, "version")); err == nil {
+			return strings.TrimSpace(string(version))
+		}
+	}
+
+	if configuration.Umu.Proton != "" {
+		return filepath.Base(configuration.Umu.Proton)
+	}
+
+	return ""
+}
+
+// Counts the wine err and warn lines of the game logs written during the session
+func sessionWarnings(logsFolder string, startedAt time.Time) []LogWarning {
+	counts := make(map[string]int)
+	entries, _ := os.ReadDir(logsFolder)
+	// File times are coarser than the clock, a log opened right away may look older than the session
+	startedAt = startedAt.Add(-time.Second)
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+
+		if err != nil || entry.IsDir() || info.ModTime().Before(startedAt) {
+			continue
+		}
+
+		logFile, err := os.Open(filepath.Join(logsFolder, entry.Name()))
+
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(logFile)
+
+		for scanner.Scan() {
+			if match := wineWarningRegex.FindStringSubmatch(scanner.Text()); match != nil {
+				counts[match[1]+":"+match[2]]++
+			}
+		}
+
+		logFile.Close()
+	}
+
+	warnings := make([]LogWarning, 0, len(counts))
+
+	for channel, count := range counts {
+		warnings = append(warnings, LogWarning{channel, count})
+	}
+
+	slices.SortFunc(warnings, func(a LogWarning, b LogWarning) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+
+		return strings.Compare(a.Channel, b.Channel)
+	})
+
+	return warnings[:min(len(warnings), SUMMARY_TOP_WARNINGS)]
+}
@@ -0,0 +1,60 @@
+package launcher
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const PHASE_CONFIG = "config"
+const PHASE_METADATA = "metadata"
+const PHASE_COMPATDATA = "compatdata"
+const PHASE_EOS = "eos"
+const PHASE_PREPARE = "prepare"
+const PHASE_WRAPPERS = "wrappers"
+
+// PhaseTimings accumulates how long each launch phase took, its methods are no-ops on nil.
+type PhaseTimings struct {
+	phases    []string
+	durations map[string]time.Duration
+}
+
+func NewPhaseTimings() *PhaseTimings {
+	return &PhaseTimings{make([]string, 0), make(map[string]time.Duration)}
+}
+
+// Track starts timing phase, the returned function stops it. A phase tracked more than once adds up.
+func (timings *PhaseTimings) Track(phase string) func() {
+	if timings == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+
+	return func() {
+		if _, exists := timings.durations[phase]; !exists {
+			timings.phases = append(timings.phases, phase)
+		}
+
+		timings.durations[phase] += time.Since(start)
+	}
+}
+
+// Summary formats the phases in the order they first ran, e.g. "config=3ms metadata=412ms total=415ms".
+func (timings *PhaseTimings) Summary() string {
+	if timings == nil {
+		return ""
+	}
+
+	parts := make([]string, 0, len(timings.phases)+1)
+	total := time.Duration(0)
+
+	for _, phase := range timings.phases {
+		parts = append(parts, fmt.Sprintf("%s=%s", phase, timings.durations[phase].Round(time.Millisecond)))
+		total += timings.durations[phase]
+	}
+
+	parts = append(parts, fmt.Sprintf("total=%s", total.Round(time.Millisecond)))
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,33 @@
+package launcher
+
+import (
+	"log"
+	"os/exec"
+	"strconv"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const ZENITY_BIN_NAME = "zenity"
+const KDIALOG_BIN_NAME = "kdialog"
+
+// Wide enough to read from the couch
+const TV_DIALOG_WIDTH = 1280
+
+// ShowTvError shows message in a dialog with a single button, dismissed with the controller button
+// Steam Input maps to Enter.
+func ShowTvError(message string) {
+	if bin, exists := wrappers.CheckIfBinExists(ZENITY_BIN_NAME); exists {
+		system.Exec.Run(exec.Command(bin, "--error", "--title", config.APP_NAME, "--width", strconv.Itoa(TV_DIALOG_WIDTH), "--text", message))
+		return
+	}
+
+	if bin, exists := wrappers.CheckIfBinExists(KDIALOG_BIN_NAME); exists {
+		system.Exec.Run(exec.Command(bin, "--title", config.APP_NAME, "--error", message))
+		return
+	}
+
+	log.Printf("Neither %s nor %s is installed, the error is only logged\n", ZENITY_BIN_NAME, KDIALOG_BIN_NAME)
+}
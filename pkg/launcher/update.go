@@ -0,0 +1,34 @@
+package launcher
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/hooks"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+)
+
+// Records the game build in the prefix manifest. After an update the prepare steps marked on-update run again
+// and see PLAUNCHER_GAME_UPDATED=1, the configuration environment is shared with the rest of the launch
+func checkGameUpdate(configuration config.Configuration, paths config.Paths, gameArgs []string) {
+	gameExe := gameExecutable(gameArgs)
+	prefixRoot := gamePrefixRoot(configuration, paths)
+
+	if _, err := os.Stat(filepath.Join(prefix.PfxFolder(prefixRoot), "system.reg")); gameExe == "" || prefixRoot == "" || err != nil {
+		return
+	}
+
+	updated, err := prefix.RecordGameBuild(prefixRoot, gameExe)
+
+	if err != nil {
+		log.Printf("Failed to record the game build of %s: %s\n", gameExe, err)
+		return
+	}
+
+	if updated {
+		log.Printf("%s changed since the last launch, re-provisioning the prefix\n", gameExe)
+		configuration.Environment[hooks.GAME_UPDATED_ENV] = "1"
+	}
+}
@@ -0,0 +1,265 @@
+package launcher
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EV_KEY is the type of the button events of /dev/input/event*, see linux/input-event-codes.h
+const EV_KEY = 0x01
+
+// WAKE_RESCAN_INTERVAL is how often plauncher wake looks for controllers connected since the last look
+const WAKE_RESCAN_INTERVAL = 5 * time.Second
+
+// Button codes of the kernel gamepad API, by the names wake.combo takes, with or without their btn_ prefix
+var gamepadButtons = map[string]uint16{
+	"a":      0x130,
+	"south":  0x130,
+	"b":      0x131,
+	"east":   0x131,
+	"x":      0x133,
+	"north":  0x133,
+	"y":      0x134,
+	"west":   0x134,
+	"tl":     0x136,
+	"lb":     0x136,
+	"tr":     0x137,
+	"rb":     0x137,
+	"tl2":    0x138,
+	"lt":     0x138,
+	"tr2":    0x139,
+	"rt":     0x139,
+	"select": 0x13a,
+	"back":   0x13a,
+	"start":  0x13b,
+	"mode":   0x13c,
+	"guide":  0x13c,
+	"home":   0x13c,
+	"thumbl": 0x13d,
+	"l3":     0x13d,
+	"thumbr": 0x13e,
+	"r3":     0x13e,
+}
+
+// ParseButtonCombo turns button names (e.g. guide, start, BTN_SOUTH) into the key codes controllers report.
+func ParseButtonCombo(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("No button in the combo")
+	}
+
+	combo := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		code, found := gamepadButtons[strings.TrimPrefix(strings.ToLower(name), "btn_")]
+
+		if !found {
+			return nil, fmt.Errorf("Unknown controller button %s, expected one of a, b, x, y, lb, rb, lt, rt, select, start, guide, l3, r3", name)
+		}
+
+		combo = append(combo, code)
+	}
+
+	return combo, nil
+}
+
+// WatchControllerCombo calls triggered, with the name of the controller, every time the buttons of combo end up held
+// together. Controllers are the one selector names, a part of its name or its vendor:product, or all of them, and are
+// picked up as they get connected. It returns once stop is closed, closing the controllers it watches.
+func WatchControllerCombo(combo []uint16, selector string, triggered func(controller string), stop <-chan struct{}) {
+	watched := make(map[string]bool)
+	var lock sync.Mutex
+
+	for {
+		controllers := listControllers(listInputDevices())
+
+		if selector != "" {
+			controller, found := pickController(controllers, selector, 1)
+			controllers = controllers[:0]
+
+			if found {
+				controllers = append(controllers, controller)
+			}
+		}
+
+		for _, controller := range controllers {
+			eventHandler := slices.IndexFunc(controller.handlers, func(handler string) bool { return strings.HasPrefix(handler, "event") })
+
+			if eventHandler < 0 {
+				continue
+			}
+
+			device := filepath.Join(INPUT_DEVICES_FOLDER, controller.handlers[eventHandler])
+
+			lock.Lock()
+
+			if !watched[device] {
+				watched[device] = true
+
+				// Devices that can not be opened stay watched, retrying would only repeat the error
+				go func() {
+					if err := watchDeviceCombo(device, combo, func() { triggered(controller.name) }, stop); err != nil {
+						log.Printf("Could not watch %s (%s): %s\n", controller.name, device, err)
+						return
+					}
+
+					lock.Lock()
+					delete(watched, device)
+					lock.Unlock()
+				}()
+			}
+
+			lock.Unlock()
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(WAKE_RESCAN_INTERVAL):
+		}
+	}
+}
+
+// Reads the button events of device until it goes away or stop is closed, calling triggered when the last button of
+// combo is pressed
+func watchDeviceCombo(device string, combo []uint16, triggered func(), stop <-chan struct{}) error {
+	handle, err := os.Open(device)
+
+	if err != nil {
+		return err
+	}
+
+	defer closeOnStop(handle, stop)()
+
+	// struct input_event: a timeval of two longs, then the type, code and value
+	timeSize := strconv.IntSize / 8 * 2
+	event := make([]byte, timeSize+8)
+	pressed := make(map[uint16]bool)
+
+	for {
+		// A disconnected controller fails the read, it is watched again once it is back
+		if _, err := io.ReadFull(handle, event); err != nil {
+			return nil
+		}
+
+		eventType := binary.NativeEndian.Uint16(event[timeSize:])
+		code := binary.NativeEndian.Uint16(event[timeSize+2:])
+		value := int32(binary.NativeEndian.Uint32(event[timeSize+4:]))
+
+		// Held buttons repeat with a value of 2, only presses and releases change the combo
+		if eventType != EV_KEY || value > 1 || !slices.Contains(combo, code) {
+			continue
+		}
+
+		pressed[code] = value == 1
+
+		if value == 1 && !slices.ContainsFunc(combo, func(button uint16) bool { return !pressed[button] }) {
+			triggered()
+		}
+	}
+}
+
+// ListenMagicPacket binds the UDP port then calls triggered, with the address of the sender, for every Wake-on-LAN
+// magic packet naming one of the network interfaces of this machine received on it, until stop is closed. It returns
+// once the port is bound, the returned func waiting until it is released.
+func ListenMagicPacket(port int, triggered func(sender string), stop <-chan struct{}) (func(), error) {
+	connection, err := net.ListenUDP("udp", &net.UDPAddr{Port: port})
+
+	if err != nil {
+		return nil, err
+	}
+
+	released := make(chan struct{})
+
+	go func() {
+		defer close(released)
+		defer closeOnStop(connection, stop)()
+
+		packet := make([]byte, 1024)
+
+		for {
+			size, sender, err := connection.ReadFromUDP(packet)
+
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			if err != nil {
+				log.Printf("Stopped listening for magic packets on UDP port %d: %s\n", port, err)
+				return
+			}
+
+			if isMagicPacket(packet[:size], localHardwareAddresses()) {
+				triggered(sender.IP.String())
+			}
+		}
+	}()
+
+	return func() { <-released }, nil
+}
+
+// Closes handle as soon as stop is closed, unblocking its reads, the returned func closes it otherwise and waits
+// until it is closed
+func closeOnStop(handle io.Closer, stop <-chan struct{}) func() {
+	done := make(chan struct{})
+	closed := make(chan struct{})
+
+	go func() {
+		select {
+		case <-stop:
+		case <-done:
+		}
+
+		handle.Close()
+		close(closed)
+	}()
+
+	return func() {
+		close(done)
+		<-closed
+	}
+}
+
+// A magic packet holds six 0xFF bytes followed by sixteen copies of the MAC address to wake
+func isMagicPacket(packet []byte, addresses []net.HardwareAddr) bool {
+	start := bytes.Index(packet, bytes.Repeat([]byte{0xFF}, 6))
+
+	if start < 0 || len(packet) < start+6+16*6 {
+		return false
+	}
+
+	payload := packet[start+6 : start+6+16*6]
+
+	for _, address := range addresses {
+		if bytes.Equal(payload, bytes.Repeat(address, 16)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func localHardwareAddresses() []net.HardwareAddr {
+	addresses := make([]net.HardwareAddr, 0)
+	interfaces, _ := net.Interfaces()
+
+	for _, networkInterface := range interfaces {
+		if len(networkInterface.HardwareAddr) == 6 {
+			addresses = append(addresses, networkInterface.HardwareAddr)
+		}
+	}
+
+	return addresses
+}
@@ -0,0 +1,35 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/prefix"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// Sets wine.windows-version in the game prefix, prefixes Proton did not create yet get it next launch
+func applyWindowsVersion(configuration config.Configuration, paths config.Paths) {
+	version := configuration.Wine.WindowsVersion
+
+	if version == "" {
+		return
+	}
+
+	if !slices.Contains(prefix.WINDOWS_VERSIONS, version) {
+		system.Fatalf(system.EXIT_CONFIG_ERROR, "Unknown wine.windows-version %s, expected one of: %s\n", version, strings.Join(prefix.WINDOWS_VERSIONS, ", "))
+	}
+
+	prefixRoot := gamePrefixRoot(configuration, paths)
+
+	if _, err := os.Stat(filepath.Join(prefix.PfxFolder(prefixRoot), "system.reg")); prefixRoot == "" || err != nil {
+		return
+	}
+
+	if err := prefix.SetWindowsVersion(prefixRoot, version, runnerWineBinary(configuration)); err != nil {
+		system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to set Windows version %s: %s\n", version, err)
+	}
+}
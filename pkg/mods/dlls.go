@@ -0,0 +1,103 @@
+package mods
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// GameInstallFolder returns mods.game-folder, or the install folder Steam passes to Proton.
+func GameInstallFolder(configuration config.Configuration, homeDir string) (string, error) {
+	gameFolder := config.ExpandUserPath(configuration.Mods.GameFolder, homeDir)
+
+	if gameFolder == "" {
+		gameFolder = os.Getenv("STEAM_COMPAT_INSTALL_PATH")
+	}
+
+	if gameFolder == "" {
+		return "", errors.New("mods.game-folder is needed for games not launched by Steam")
+	}
+
+	return gameFolder, nil
+}
+
+// SwapDlls links every swap source over its target in gameFolder, recording the changes in deploymentFile
+// for Undeploy. Swaps without a target replace every file of the game folder named like their source.
+func SwapDlls(swaps []config.DllSwap, gameFolder string, homeDir string, deploymentFile string) error {
+	if err := Undeploy(deploymentFile); err != nil {
+		return fmt.Errorf("Failed to restore the previously swapped DLLs: %s", err)
+	}
+
+	deployment := Deployment{config.MODS_STRATEGY_SYMLINK, "", gameFolder, make([]string, 0), make([]string, 0), make([]string, 0), false}
+	var err error
+
+	for _, swap := range swaps {
+		source := config.ExpandUserPath(swap.Source, homeDir)
+
+		if _, statErr := os.Stat(source); statErr != nil {
+			err = fmt.Errorf("DLL to swap in %s is missing: %s", source, statErr)
+			break
+		}
+
+		targets, findErr := swapTargets(source, swap.Target, gameFolder)
+
+		if findErr != nil {
+			err = findErr
+			break
+		}
+
+		for _, target := range targets {
+			if err = placeFile(&deployment, source, target, system.FS.Symlink); err != nil {
+				break
+			}
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	if writeErr := writeDeployment(deploymentFile, deployment); writeErr != nil && err == nil {
+		err = writeErr
+	}
+
+	if err != nil {
+		Undeploy(deploymentFile)
+	}
+
+	return err
+}
+
+// DLL names are matched regardless of case, as Windows does
+func swapTargets(source string, target string, gameFolder string) ([]string, error) {
+	if target != "" {
+		return []string{filepath.Join(gameFolder, target)}, nil
+	}
+
+	name := filepath.Base(source)
+	targets := make([]string, 0)
+
+	walkErr := filepath.WalkDir(gameFolder, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && strings.EqualFold(entry.Name(), name) {
+			targets = append(targets, path)
+		}
+
+		return err
+	})
+
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("No %s in %s, set the target of its swap", name, gameFolder)
+	}
+
+	return targets, nil
+}
@@ -0,0 +1,220 @@
+// Package mods deploys a mod staging folder into a game folder for the length of a session, with symlinks,
+// hardlinks or a fuse-overlayfs mount, and removes it afterwards so the game files stay as installed.
+package mods
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// Suffix of the game files moved aside while a mod file replaces them
+const BACKUP_SUFFIX = ".plauncher-backup"
+
+// Deployment records what Deploy changed in the game folder, for Undeploy to revert it even after a crash.
+type Deployment struct {
+	Strategy string   `json:"strategy"`
+	Staging  string   `json:"staging"`
+	Target   string   `json:"target"`
+	Links    []string `json:"links"`
+	Backups  []string `json:"backups"`
+	Folders  []string `json:"folders"`
+	Mounted  bool     `json:"mounted"`
+}
+
+// Deploy makes the files of staging appear in target with strategy, recording the changes in deploymentFile.
+// A deployment left by a previous session is removed first. overlayFolder keeps the files the game writes
+// while an overlay is mounted.
+func Deploy(strategy string, staging string, target string, deploymentFile string, overlayFolder string) error {
+	if err := Undeploy(deploymentFile); err != nil {
+		return fmt.Errorf("Failed to remove the previous deployment: %s", err)
+	}
+
+	if info, err := os.Stat(staging); err != nil || !info.IsDir() {
+		return fmt.Errorf("Mod staging folder %s is not a folder", staging)
+	}
+
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return fmt.Errorf("Mod target folder %s is not a folder", target)
+	}
+
+	deployment := Deployment{strategy, staging, target, make([]string, 0), make([]string, 0), make([]string, 0), false}
+	var err error
+
+	switch strategy {
+	case config.MODS_STRATEGY_SYMLINK:
+		err = deployLinks(&deployment, system.FS.Symlink)
+	case config.MODS_STRATEGY_HARDLINK:
+		err = deployLinks(&deployment, system.FS.Link)
+	case config.MODS_STRATEGY_OVERLAY:
+		err = mountOverlay(&deployment, overlayFolder)
+	default:
+		return fmt.Errorf("Unknown mods.strategy %s", strategy)
+	}
+
+	if writeErr := writeDeployment(deploymentFile, deployment); writeErr != nil && err == nil {
+		err = writeErr
+	}
+
+	if err != nil {
+		Undeploy(deploymentFile)
+	}
+
+	return err
+}
+
+// Undeploy reverts the deployment recorded in deploymentFile, if any.
+func Undeploy(deploymentFile string) error {
+	content, err := os.ReadFile(deploymentFile)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	deployment := Deployment{}
+
+	if err := json.Unmarshal(content, &deployment); err != nil {
+		return fmt.Errorf("Invalid mod deployment %s: %s", deploymentFile, err)
+	}
+
+	if deployment.Mounted {
+		if err := unmountOverlay(deployment.Target); err != nil {
+			return err
+		}
+	}
+
+	for i := len(deployment.Links) - 1; i >= 0; i-- {
+		if err := system.FS.Remove(deployment.Links[i]); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove mod file %s: %s\n", deployment.Links[i], err)
+		}
+	}
+
+	for _, backup := range deployment.Backups {
+		if err := system.FS.Rename(backup+BACKUP_SUFFIX, backup); err != nil {
+			log.Printf("Failed to restore game file %s: %s\n", backup, err)
+		}
+	}
+
+	// Created folders are recorded parents first, only empty ones are removed
+	for i := len(deployment.Folders) - 1; i >= 0; i-- {
+		os.Remove(deployment.Folders[i])
+	}
+
+	log.Printf("Removed mods from %s\n", deployment.Target)
+
+	return system.FS.Remove(deploymentFile)
+}
+
+func deployLinks(deployment *Deployment, link func(oldname string, newname string) error) error {
+	return filepath.WalkDir(deployment.Staging, func(source string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		relative, _ := filepath.Rel(deployment.Staging, source)
+
+		return placeFile(deployment, source, filepath.Join(deployment.Target, relative), link)
+	})
+}
+
+// Links source at destination, moving the file already there aside
+func placeFile(deployment *Deployment, source string, destination string, link func(oldname string, newname string) error) error {
+	if err := createFolders(deployment, filepath.Dir(destination)); err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(destination); err == nil {
+		if _, err := os.Lstat(destination + BACKUP_SUFFIX); err == nil {
+			return fmt.Errorf("%s and its backup both exist, move one of them away", destination)
+		}
+
+		if err := system.FS.Rename(destination, destination+BACKUP_SUFFIX); err != nil {
+			return err
+		}
+
+		deployment.Backups = append(deployment.Backups, destination)
+	}
+
+	if err := link(source, destination); err != nil {
+		return err
+	}
+
+	deployment.Links = append(deployment.Links, destination)
+
+	return nil
+}
+
+func createFolders(deployment *Deployment, folder string) error {
+	if _, err := os.Stat(folder); err == nil {
+		return nil
+	}
+
+	if err := createFolders(deployment, filepath.Dir(folder)); err != nil {
+		return err
+	}
+
+	if err := system.FS.MkdirAll(folder, config.DEFAULT_PERMISSION); err != nil {
+		return err
+	}
+
+	deployment.Folders = append(deployment.Folders, folder)
+
+	return nil
+}
+
+// Mounts staging over target, the writes of the game land in overlayFolder instead of the game folder
+func mountOverlay(deployment *Deployment, overlayFolder string) error {
+	fuseOverlayfs, exists := wrappers.CheckIfBinExists(wrappers.FUSE_OVERLAYFS_BIN_NAME)
+
+	if !exists {
+		return fmt.Errorf("%s is needed for the overlay mods strategy", wrappers.FUSE_OVERLAYFS_BIN_NAME)
+	}
+
+	upper := filepath.Join(overlayFolder, "upper")
+	work := filepath.Join(overlayFolder, "work")
+	config.MakeSureFoldersExist(upper, work)
+
+	options := fmt.Sprintf("lowerdir=%s:%s,upperdir=%s,workdir=%s", deployment.Staging, deployment.Target, upper, work)
+
+	if err := system.Exec.Run(exec.Command(fuseOverlayfs, "-o", options, deployment.Target)); err != nil {
+		return fmt.Errorf("Failed to mount mods over %s: %s", deployment.Target, err)
+	}
+
+	deployment.Mounted = true
+
+	return nil
+}
+
+func unmountOverlay(target string) error {
+	for _, fusermount := range []string{wrappers.FUSERMOUNT3_BIN_NAME, wrappers.FUSERMOUNT_BIN_NAME} {
+		if bin, exists := wrappers.CheckIfBinExists(fusermount); exists {
+			return system.Exec.Run(exec.Command(bin, "-u", target))
+		}
+	}
+
+	return fmt.Errorf("%s is needed to unmount mods from %s", wrappers.FUSERMOUNT3_BIN_NAME, target)
+}
+
+func writeDeployment(deploymentFile string, deployment Deployment) error {
+	content, err := json.MarshalIndent(deployment, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	system.FS.MkdirAll(filepath.Dir(deploymentFile), config.DEFAULT_PERMISSION)
+
+	return system.FS.WriteFile(deploymentFile, content, config.DEFAULT_PERMISSION)
+}
@@ -0,0 +1,128 @@
+// Package prefix manages the Wine prefixes (Steam compat data) of launched games.
+package prefix
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// ConfigureNewSteamCompatData moves the compat data Steam created into plauncher data folder, leaving a symlink behind.
+func ConfigureNewSteamCompatData(configuration *config.Configuration, oldCompatData string, newCompatDataBase string) {
+	newCompatData := config.GameFolder(newCompatDataBase, configuration.Props["name"])
+
+	if isDanglingSymlink(oldCompatData) {
+		repairDanglingCompatData(configuration, oldCompatData, newCompatData, newCompatDataBase)
+	}
+
+	oldSteamCompatDataStats, oldCompatErr := os.Lstat(oldCompatData)
+	_, newCompatErr := os.Stat(newCompatData)
+
+	if stage, err := readCopyJournal(newCompatData); err == nil {
+		// Past the copy the game may have written to the new compat data, syncing the old one again would prune them
+		if stage == COPY_STAGE_COPIED {
+			log.Printf("Finishing interrupted compat data move: %s\n", newCompatData)
+			finishCompatDataMove(configuration, oldCompatData, newCompatData)
+			return
+		}
+
+		if !os.IsNotExist(oldCompatErr) && oldSteamCompatDataStats.IsDir() {
+			log.Printf("Resuming interrupted compat data copy: %s\n", newCompatData)
+			copyOldCompatDataToNew(configuration, oldCompatData, newCompatData)
+			return
+		}
+
+		// The copy went through, only the journal cleanup was missed
+		system.FS.Remove(copyJournalFile(newCompatData))
+	}
+
+	if os.IsNotExist(newCompatErr) && !os.IsNotExist(oldCompatErr) && oldSteamCompatDataStats.IsDir() {
+		copyOldCompatDataToNew(configuration, oldCompatData, newCompatData)
+		return
+	}
+
+	if !os.IsNotExist(newCompatErr) && !os.IsNotExist(oldCompatErr) && oldSteamCompatDataStats.IsDir() {
+		system.FS.RemoveAll(oldCompatData)
+	} else {
+		system.FS.Remove(oldCompatData)
+	}
+
+	linkCompatData(configuration, oldCompatData, newCompatData)
+}
+
+// Proton follows STEAM_COMPAT_DATA_PATH, the link only tells Steam where the prefix went
+func linkCompatData(configuration *config.Configuration, oldCompatData string, newCompatData string) {
+	if err := system.FS.Symlink(newCompatData, oldCompatData); err != nil {
+		system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to link %s to %s: %s\n", oldCompatData, newCompatData, err)
+	}
+
+	configuration.Environment["STEAM_COMPAT_DATA_PATH"] = newCompatData
+
+	log.Printf("Old compat data folder: %s\n", oldCompatData)
+	log.Printf("New compat data folder: %s\n", newCompatData)
+}
+
+// COPY_STAGE_COPIED is written to the copy journal once the compat data is copied, before anything is deleted
+const COPY_STAGE_COPIED = "copied"
+
+// The journal next to the new compat data marks a copy in progress until the old folder is replaced by the symlink.
+// It holds the old folder, then COPY_STAGE_COPIED on a second line once the copy went through.
+func copyJournalFile(newCompatData string) string {
+	return newCompatData + ".copying"
+}
+
+// The stage of the copy journal of newCompatData, empty while copying
+func readCopyJournal(newCompatData string) (string, error) {
+	content, err := os.ReadFile(copyJournalFile(newCompatData))
+
+	if err != nil {
+		return "", err
+	}
+
+	_, stage, _ := strings.Cut(strings.TrimSpace(string(content)), "\n")
+
+	return stage, nil
+}
+
+func copyOldCompatDataToNew(configuration *config.Configuration, oldCompatData string, newCompatData string) {
+	journalFile := copyJournalFile(newCompatData)
+
+	// Until the copy went through the game keeps running from the compat data Steam created
+	if err := system.FS.WriteFile(journalFile, []byte(oldCompatData), config.DEFAULT_PERMISSION); err != nil {
+		system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to write compat data copy journal, leaving the compat data in %s: %s\n", oldCompatData, err)
+		return
+	}
+
+	if err := system.FS.SyncDir(oldCompatData, newCompatData); err != nil {
+		system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to copy compat data, leaving it in %s until the next launch: %s\n", oldCompatData, err)
+		return
+	}
+
+	// Without the stage recorded the next launch would copy again, so nothing is deleted yet
+	if err := system.FS.WriteFile(journalFile, []byte(oldCompatData+"\n"+COPY_STAGE_COPIED+"\n"), config.DEFAULT_PERMISSION); err != nil {
+		system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to record the compat data copy, leaving the compat data in %s until the next launch: %s\n", oldCompatData, err)
+		return
+	}
+
+	finishCompatDataMove(configuration, oldCompatData, newCompatData)
+}
+
+// Deletes the old compat data of a finished copy and links it to the new one. The journal stays when the deletion
+// fails, the next launch finishes it.
+func finishCompatDataMove(configuration *config.Configuration, oldCompatData string, newCompatData string) {
+	if info, err := os.Lstat(oldCompatData); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		if err := system.FS.RemoveAll(oldCompatData); err != nil {
+			system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to delete old compat data: %s\n", err)
+			configuration.Environment["STEAM_COMPAT_DATA_PATH"] = newCompatData
+			return
+		}
+	} else if err == nil {
+		system.FS.Remove(oldCompatData)
+	}
+
+	linkCompatData(configuration, oldCompatData, newCompatData)
+	system.FS.Remove(copyJournalFile(newCompatData))
+}
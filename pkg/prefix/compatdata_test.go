@@ -0,0 +1,89 @@
+package prefix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+)
+
+func TestConfigureNewSteamCompatDataResumes(t *testing.T) {
+	tests := []struct {
+		name     string
+		stage    string
+		old      map[string]string
+		new      map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "interrupted copy",
+			old:      map[string]string{"pfx/save.dat": "1", "pfx/user.reg": "reg"},
+			new:      map[string]string{"pfx/save.dat": "1"},
+			expected: map[string]string{"pfx/save.dat": "1", "pfx/user.reg": "reg"},
+		},
+		{
+			name:     "interrupted deletion after saves went to the new folder",
+			stage:    COPY_STAGE_COPIED,
+			old:      map[string]string{"pfx/save.dat": "1"},
+			new:      map[string]string{"pfx/save.dat": "2", "pfx/slot2.dat": "new", "pfx/user.reg": "reg"},
+			expected: map[string]string{"pfx/save.dat": "2", "pfx/slot2.dat": "new", "pfx/user.reg": "reg"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base := t.TempDir()
+			oldCompatData := filepath.Join(base, "steamapps", "compatdata", "1145360")
+			newCompatDataBase := filepath.Join(base, "compatdata")
+
+			configuration := config.DefaultConfiguration()
+			configuration.Props["name"] = "Hades"
+			newCompatData := config.GameFolder(newCompatDataBase, "Hades")
+
+			writeFiles(t, oldCompatData, test.old)
+			writeFiles(t, newCompatData, test.new)
+
+			journal := oldCompatData + "\n"
+
+			if test.stage != "" {
+				journal += test.stage + "\n"
+			}
+
+			os.WriteFile(copyJournalFile(newCompatData), []byte(journal), 0o644)
+
+			ConfigureNewSteamCompatData(&configuration, oldCompatData, newCompatDataBase)
+
+			for file, content := range test.expected {
+				if got, err := os.ReadFile(filepath.Join(newCompatData, file)); err != nil || string(got) != content {
+					t.Errorf("%s is %q (%v), expected %q", file, got, err, content)
+				}
+			}
+
+			if target, err := os.Readlink(oldCompatData); err != nil || target != newCompatData {
+				t.Errorf("old compat data links to %q (%v), expected %s", target, err, newCompatData)
+			}
+
+			if _, err := os.Stat(copyJournalFile(newCompatData)); !os.IsNotExist(err) {
+				t.Errorf("journal left behind: %v", err)
+			}
+
+			if configuration.Environment["STEAM_COMPAT_DATA_PATH"] != newCompatData {
+				t.Errorf("STEAM_COMPAT_DATA_PATH is %q", configuration.Environment["STEAM_COMPAT_DATA_PATH"])
+			}
+		})
+	}
+}
+
+func writeFiles(t *testing.T, folder string, files map[string]string) {
+	t.Helper()
+
+	for file, content := range files {
+		path := filepath.Join(folder, file)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package prefix
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Folders games ship their redistributable installers in, at their root
+var redistFolders = []string{"_CommonRedist", "Redist", "redist", "Redistributables", "Support"}
+
+// "vcredist_x64_2013.exe", "VC_redist.x64.exe" in a 2019 folder, "vcredist/2015"
+var vcredistYearRegex = regexp.MustCompile(`(?i)vc_?redist.*?(20[0-2][0-9])`)
+
+// <supportedRuntime version="v4.0" sku=".NETFramework,Version=v4.7.2"/>
+var dotnetSkuRegex = regexp.MustCompile(`\.NETFramework,Version=v([0-9.]+)`)
+var dotnetRuntimeRegex = regexp.MustCompile(`<supportedRuntime[^>]*version="v([0-9]+\.[0-9]+)`)
+
+// .NET Framework versions winetricks has a verb for, oldest first
+var dotnetVerbs = []string{"2.0", "3.0", "3.5", "4.0", "4.5", "4.5.2", "4.6", "4.6.1", "4.6.2", "4.7.1", "4.7.2", "4.8"}
+
+// DetectDependencies returns the winetricks verbs the game installed around gameExe seems to need, from the
+// redistributable installers shipped with it and the .NET runtime its executables ask for.
+func DetectDependencies(gameExe string) []string {
+	verbs := make([]string, 0)
+	addVerb := func(verb string) {
+		if verb != "" && !slices.Contains(verbs, verb) {
+			verbs = append(verbs, verb)
+		}
+	}
+
+	configs, _ := filepath.Glob(filepath.Join(filepath.Dir(gameExe), "*.exe.config"))
+
+	for _, configFile := range configs {
+		addVerb(dotnetConfigVerb(configFile))
+	}
+
+	// The executable may sit a few folders below the game root, e.g. Binaries/Win64
+	for folder, depth := filepath.Dir(gameExe), 0; depth < 4 && folder != "/" && folder != "."; folder, depth = filepath.Dir(folder), depth+1 {
+		for _, redistFolder := range redistFolders {
+			filepath.WalkDir(filepath.Join(folder, redistFolder), func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return nil
+				}
+
+				addVerb(redistVerb(path))
+
+				return nil
+			})
+		}
+
+		if filepath.Base(filepath.Dir(folder)) == "common" {
+			break
+		}
+	}
+
+	slices.Sort(verbs)
+
+	return verbs
+}
+
+// MarkDependenciesChecked records in the manifest of prefixRoot that its game was scanned for dependencies.
+func MarkDependenciesChecked(prefixRoot string) error {
+	manifest, err := ReadManifest(prefixRoot)
+
+	if err != nil {
+		manifest.CreatedAt = time.Now()
+	}
+
+	manifest.DependenciesChecked = true
+	manifest.UpdatedAt = time.Now()
+
+	return writeManifest(prefixRoot, manifest)
+}
+
+// The verb of a file or folder under a redistributables folder, named after what it installs
+func redistVerb(path string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	name := strings.ToLower(filepath.Base(path))
+
+	for index, part := range parts {
+		switch strings.ToLower(part) {
+		case "dotnet", "dotnetframework", ".net":
+			if index+1 < len(parts) {
+				return dotnetVerb(strings.TrimPrefix(parts[index+1], "v"))
+			}
+		case "xna":
+			if index+1 < len(parts) && (parts[index+1] == "3.1" || parts[index+1] == "4.0") {
+				return "xna" + strings.ReplaceAll(parts[index+1], ".", "")
+			}
+		case "vcredist", "msvc":
+			if index+1 < len(parts) {
+				if year, err := strconv.Atoi(parts[index+1]); err == nil {
+					return vcrunVerb(year)
+				}
+			}
+		}
+	}
+
+	if match := vcredistYearRegex.FindStringSubmatch(name); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		return vcrunVerb(year)
+	}
+
+	return ""
+}
+
+// The 2015 to 2022 runtimes are one, its latest verb covers them all
+func vcrunVerb(year int) string {
+	switch {
+	case year >= 2015:
+		return "vcrun2022"
+	case year == 2005 || year == 2008 || year == 2010 || year == 2012 || year == 2013:
+		return "vcrun" + strconv.Itoa(year)
+	}
+
+	return ""
+}
+
+func dotnetConfigVerb(configFile string) string {
+	content, err := os.ReadFile(configFile)
+
+	if err != nil {
+		return ""
+	}
+
+	if match := dotnetSkuRegex.FindSubmatch(content); match != nil {
+		return dotnetVerb(string(match[1]))
+	}
+
+	// The 2.0 runtime runs everything up to 3.5
+	if match := dotnetRuntimeRegex.FindSubmatch(content); match != nil {
+		if string(match[1]) == "2.0" {
+			return dotnetVerb("3.5")
+		}
+
+		return dotnetVerb(string(match[1]))
+	}
+
+	return ""
+}
+
+// The verb of the oldest .NET Framework at least as recent as version
+func dotnetVerb(version string) string {
+	for _, known := range dotnetVerbs {
+		if compareVersions(known, version) >= 0 {
+			return "dotnet" + strings.ReplaceAll(known, ".", "")
+		}
+	}
+
+	return ""
+}
+
+func compareVersions(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for index := 0; index < max(len(aParts), len(bParts)); index++ {
+		aNumber, bNumber := 0, 0
+
+		if index < len(aParts) {
+			aNumber, _ = strconv.Atoi(aParts[index])
+		}
+
+		if index < len(bParts) {
+			bNumber, _ = strconv.Atoi(bParts[index])
+		}
+
+		if aNumber != bNumber {
+			return aNumber - bNumber
+		}
+	}
+
+	return 0
+}
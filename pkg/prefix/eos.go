@@ -0,0 +1,102 @@
+package prefix
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// "Installed version: 1.3.1", "Latest available version: 1.3.2"
+var eosVersionRegex = regexp.MustCompile(`(?i)(installed|latest)[a-z ]*version:\s*(\S+)`)
+
+// SetupEosInPrefix installs and enables the Epic Online Services overlay in the game prefix.
+func SetupEosInPrefix(configuration config.Configuration, appDataFolder string) {
+	if configuration.EosOverlay.Enabled {
+		if steamCompatData, exists := configuration.Environment["STEAM_COMPAT_DATA_PATH"]; exists {
+			if cmd, exists := wrappers.CheckIfBinExists(wrappers.LEGENDARY_BIN_NAME); exists {
+				overlayFolder := filepath.Join(appDataFolder, "eos-overlay")
+
+				if err := installEosOverlay(cmd, overlayFolder); err != nil {
+					system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to install eos-overlay in %s: %s\n", overlayFolder, err)
+					return
+				}
+
+				prefixFolder := filepath.Join(steamCompatData, "pfx")
+				log.Printf("Enabling eos-overlay in: %s, for prefix: %s\n", overlayFolder, prefixFolder)
+				err := system.Retry(wrappers.LEGENDARY_BIN_NAME, func() error {
+					return system.RunBounded(system.TIMEOUT_LEGENDARY, legendaryCommand(cmd, "eos-overlay", "enable", "--prefix", prefixFolder))
+				})
+
+				if err != nil {
+					system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to enable eos-overlay: %s\n", err)
+				}
+			}
+		}
+	}
+}
+
+// Installs the overlay when overlayFolder is empty, updates it when legendary knows of a newer version
+func installEosOverlay(cmd string, overlayFolder string) error {
+	if entries, _ := os.ReadDir(overlayFolder); len(entries) == 0 {
+		log.Printf("Installing eos-overlay in: %s\n", overlayFolder)
+
+		return system.Retry(wrappers.LEGENDARY_BIN_NAME, func() error {
+			return system.RunBounded(system.TIMEOUT_LEGENDARY, legendaryCommand(cmd, "eos-overlay", "install", "--path", overlayFolder))
+		})
+	}
+
+	if !eosOverlayOutdated(cmd) {
+		log.Printf("eos-overlay is up to date in: %s\n", overlayFolder)
+		return nil
+	}
+
+	log.Printf("Updating eos-overlay in: %s\n", overlayFolder)
+
+	return system.Retry(wrappers.LEGENDARY_BIN_NAME, func() error {
+		return system.RunBounded(system.TIMEOUT_LEGENDARY, legendaryCommand(cmd, "eos-overlay", "update"))
+	})
+}
+
+// An overlay legendary cannot check is kept as it is, the game runs fine with an older one
+func eosOverlayOutdated(cmd string) bool {
+	var stderr bytes.Buffer
+	cmdHandle := exec.Command(cmd, "eos-overlay", "info")
+	cmdHandle.Stderr = &stderr
+	stdout, err := system.OutputBounded(system.TIMEOUT_LEGENDARY, cmdHandle)
+
+	if err != nil {
+		log.Printf("Could not check the eos-overlay version: %s\n", err)
+		return false
+	}
+
+	output := string(stdout) + stderr.String()
+
+	if strings.Contains(strings.ToLower(output), "update available") {
+		return true
+	}
+
+	versions := make(map[string]string)
+
+	for _, match := range eosVersionRegex.FindAllStringSubmatch(output, -1) {
+		versions[strings.ToLower(match[1])] = match[2]
+	}
+
+	return versions["installed"] != "" && versions["latest"] != "" && versions["installed"] != versions["latest"]
+}
+
+// legendary asks before installing or enabling, -y answers its prompts and the output goes to the debug log
+func legendaryCommand(cmd string, args ...string) *exec.Cmd {
+	cmdHandle := exec.Command(cmd, append([]string{"-y"}, args...)...)
+	cmdHandle.Stdout = log.Writer()
+	cmdHandle.Stderr = log.Writer()
+
+	return cmdHandle
+}
@@ -0,0 +1,109 @@
+package prefix
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// Short names accepted in wine.fonts for winetricks font verbs
+var fontVerbAliases = map[string]string{
+	"cjk": "cjkfonts",
+}
+
+var fontFileExtensions = []string{".ttf", ".ttc", ".otf"}
+
+const FONTS_REGISTRY_KEY = `HKLM\Software\Microsoft\Windows NT\CurrentVersion\Fonts`
+
+// InstallFonts makes fonts available in the prefix at prefixFolder (the pfx folder): font verbs through
+// winetricks, font files by copying them into the Windows fonts folder and registering them with wine,
+// when a wine binary is known. Fonts already installed are left alone.
+func InstallFonts(prefixFolder string, fonts []string, fontsBase string, wine string) error {
+	verbs := make([]string, 0)
+	files := make([]string, 0)
+
+	for _, font := range fonts {
+		if !slices.Contains(fontFileExtensions, strings.ToLower(filepath.Ext(font))) {
+			if alias, exists := fontVerbAliases[font]; exists {
+				font = alias
+			}
+
+			verbs = append(verbs, font)
+			continue
+		}
+
+		if !filepath.IsAbs(font) {
+			font = filepath.Join(fontsBase, font)
+		}
+
+		files = append(files, font)
+	}
+
+	if len(verbs) > 0 {
+		if err := InstallWinetricksVerbs(prefixFolder, verbs); err != nil {
+			return err
+		}
+	}
+
+	windowsFonts := filepath.Join(prefixFolder, "drive_c", "windows", "Fonts")
+
+	for _, file := range files {
+		installed := filepath.Join(windowsFonts, filepath.Base(file))
+
+		if sameFileSize(file, installed) {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+
+		if err != nil {
+			return fmt.Errorf("Failed to read font %s: %s", file, err)
+		}
+
+		log.Printf("Installing font %s in %s\n", file, windowsFonts)
+		system.FS.MkdirAll(windowsFonts, config.DEFAULT_PERMISSION)
+
+		if err := system.FS.WriteFile(installed, content, config.DEFAULT_PERMISSION); err != nil {
+			return fmt.Errorf("Failed to copy font %s: %s", file, err)
+		}
+
+		registerFont(prefixFolder, installed, wine)
+	}
+
+	return nil
+}
+
+func sameFileSize(file string, other string) bool {
+	fileInfo, err := os.Stat(file)
+
+	if err != nil {
+		return false
+	}
+
+	otherInfo, err := os.Stat(other)
+
+	return err == nil && fileInfo.Size() == otherInfo.Size()
+}
+
+// Wine also finds fonts in the fonts folder without a registry entry, failing to add it is not an error
+func registerFont(prefixFolder string, installed string, wine string) {
+	if wine == "" {
+		log.Printf("No wine binary known, %s is not registered\n", installed)
+		return
+	}
+
+	name := strings.TrimSuffix(filepath.Base(installed), filepath.Ext(installed)) + " (TrueType)"
+	cmdHandle := exec.Command(wine, "reg", "add", FONTS_REGISTRY_KEY, "/v", name, "/t", "REG_SZ", "/d", filepath.Base(installed), "/f")
+	cmdHandle.Env = append(os.Environ(), fmt.Sprintf("%s=%s", "WINEPREFIX", prefixFolder))
+
+	if err := system.RunBounded(system.TIMEOUT_WINETRICKS, cmdHandle); err != nil {
+		log.Printf("Failed to register font %s: %s\n", installed, err)
+	}
+}
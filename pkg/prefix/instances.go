@@ -0,0 +1,76 @@
+package prefix
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// Hidden, so the clones are not listed as prefixes of their own; slugs never start with a dot
+const INSTANCES_FOLDER_NAME = ".instances"
+
+// InstancesFolder returns the folder holding the instance clones of the prefix at prefixRoot, next to it so
+// they can share its blocks.
+func InstancesFolder(prefixRoot string) string {
+	return filepath.Join(filepath.Dir(prefixRoot), INSTANCES_FOLDER_NAME, filepath.Base(prefixRoot))
+}
+
+// ClonePrefix returns the prefix instance of the game at prefixRoot runs in, cloning prefixRoot on its first
+// launch. Clones are copy-on-write where the filesystem supports it and keep their own saves and settings after.
+func ClonePrefix(prefixRoot string, instance int) (string, error) {
+	// Steam compat data left in place may be a link to the prefix, the prefix is what gets cloned
+	if resolved, err := filepath.EvalSymlinks(prefixRoot); err == nil {
+		prefixRoot = resolved
+	}
+
+	clone := filepath.Join(InstancesFolder(prefixRoot), strconv.Itoa(instance))
+
+	if _, err := os.Stat(clone); err == nil {
+		return clone, nil
+	}
+
+	if _, err := os.Stat(prefixRoot); err != nil {
+		return "", fmt.Errorf("Cannot clone %s for instance %d: %w", prefixRoot, instance, err)
+	}
+
+	log.Printf("Cloning prefix %s to %s for instance %d\n", prefixRoot, clone, instance)
+
+	system.FS.MkdirAll(filepath.Dir(clone), config.DEFAULT_PERMISSION)
+
+	if err := system.FS.CloneDir(prefixRoot, clone); err != nil {
+		system.FS.RemoveAll(clone)
+		return "", fmt.Errorf("Failed to clone %s for instance %d: %w", prefixRoot, instance, err)
+	}
+
+	return clone, nil
+}
+
+// Keeps the clones of a moved or renamed prefix with it, a failure only leaves them behind
+func moveInstances(oldFolder string, newFolder string) {
+	oldInstances := InstancesFolder(oldFolder)
+	newInstances := InstancesFolder(newFolder)
+
+	if _, err := os.Stat(oldInstances); err != nil {
+		return
+	}
+
+	system.FS.MkdirAll(filepath.Dir(newInstances), config.DEFAULT_PERMISSION)
+	err := system.FS.Rename(oldInstances, newInstances)
+
+	if errors.Is(err, syscall.EXDEV) {
+		if err = copyPrefix(oldInstances, newInstances); err == nil {
+			err = system.FS.RemoveAll(oldInstances)
+		}
+	}
+
+	if err != nil {
+		log.Printf("Failed to move the instance clones %s: %s\n", oldInstances, err)
+	}
+}
@@ -0,0 +1,87 @@
+package prefix
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// LinkPrefix points linksFolder/name at prefixRoot, creating linksFolder when needed.
+func LinkPrefix(linksFolder string, name string, prefixRoot string) {
+	// Older versions made the links folder itself a symlink to the whole compat data folder
+	if info, err := os.Lstat(linksFolder); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		system.FS.Remove(linksFolder)
+	}
+
+	config.MakeSureFoldersExist(linksFolder)
+
+	link := filepath.Join(linksFolder, name)
+
+	if target, err := os.Readlink(link); err == nil {
+		if target == prefixRoot {
+			return
+		}
+
+		system.FS.Remove(link)
+	}
+
+	if err := system.FS.Symlink(prefixRoot, link); err != nil {
+		log.Printf("Failed to link prefix %s in %s: %s\n", prefixRoot, linksFolder, err)
+	}
+}
+
+// RemovePrefixLinks removes the links of linksFolder pointing inside ownedFolder, then linksFolder if left empty.
+func RemovePrefixLinks(linksFolder string, ownedFolder string) []string {
+	removed := make([]string, 0)
+	entries, err := os.ReadDir(linksFolder)
+
+	if err != nil {
+		return removed
+	}
+
+	for _, entry := range entries {
+		link := filepath.Join(linksFolder, entry.Name())
+		target, err := os.Readlink(link)
+
+		if err != nil || !strings.HasPrefix(filepath.Clean(target), ownedFolder) {
+			continue
+		}
+
+		if err := system.FS.Remove(link); err == nil {
+			removed = append(removed, link)
+		}
+	}
+
+	if remaining, err := os.ReadDir(linksFolder); err == nil && len(remaining) == 0 {
+		system.FS.Remove(linksFolder)
+	}
+
+	return removed
+}
+
+// FindPrefix returns the managed prefix of game, matched by folder name or by the id or name recorded in its manifest.
+func FindPrefix(compatDataBase string, game string) (string, bool) {
+	byName := filepath.Join(compatDataBase, config.GameSlug(game))
+
+	if info, err := os.Stat(byName); err == nil && info.IsDir() {
+		return byName, true
+	}
+
+	managedPrefixes, err := ListManagedPrefixes(compatDataBase)
+
+	if err != nil {
+		return "", false
+	}
+
+	for _, managedPrefix := range managedPrefixes {
+		if managedPrefix.Manifest.Id == game || config.GameSlug(managedPrefix.Manifest.Name) == config.GameSlug(game) {
+			return managedPrefix.Folder, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,188 @@
+package prefix
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const MANIFEST_FILENAME = "plauncher.json"
+const PROTON_BUNDLED_DXVK = "proton-bundled"
+
+var winetricksDxvkVerbRegex = regexp.MustCompile("^dxvk([0-9]+)$")
+
+// Manifest describes a prefix managed by plauncher, it is kept at the root of the prefix as plauncher.json.
+type Manifest struct {
+	Id                  string     `json:"id"`
+	Name                string     `json:"name"`
+	Slug                string     `json:"slug"`
+	SteamCompatData     string     `json:"steam-compat-data,omitempty"`
+	ProtonVersion       string     `json:"proton-version"`
+	DxvkVersion         string     `json:"dxvk-version"`
+	WinetricksVerbs     []string   `json:"winetricks-verbs"`
+	WindowsVersion      string     `json:"windows-version,omitempty"`
+	DependenciesChecked bool       `json:"dependencies-checked,omitempty"`
+	GameBuild           *GameBuild `json:"game-build,omitempty"`
+	CreatedAt           time.Time  `json:"created-at"`
+	UpdatedAt           time.Time  `json:"updated-at"`
+}
+
+// ManagedPrefix is a prefix folder along with its manifest.
+type ManagedPrefix struct {
+	Folder   string   `json:"folder"`
+	Manifest Manifest `json:"manifest"`
+}
+
+// ReadManifest reads the manifest of the prefix rooted at prefixRoot.
+func ReadManifest(prefixRoot string) (Manifest, error) {
+	manifest := Manifest{}
+	content, err := os.ReadFile(filepath.Join(prefixRoot, MANIFEST_FILENAME))
+
+	if err != nil {
+		return manifest, err
+	}
+
+	err = json.Unmarshal(content, &manifest)
+
+	return manifest, err
+}
+
+// UpdateManifest refreshes the manifest of prefixRoot from the prefix content and the launch configuration.
+func UpdateManifest(prefixRoot string, configuration config.Configuration, steamCompatData string) {
+	manifest, err := ReadManifest(prefixRoot)
+
+	if err != nil {
+		manifest.CreatedAt = time.Now()
+	}
+
+	if id := configuration.Props["id"]; id != "" {
+		manifest.Id = id
+	}
+
+	if name := configuration.Props["name"]; name != "" {
+		manifest.Name = name
+	}
+
+	// Folders are named after the slug, the manifest maps them back to the display name
+	manifest.Slug = filepath.Base(prefixRoot)
+
+	if steamCompatData != "" {
+		manifest.SteamCompatData = steamCompatData
+	}
+
+	manifest.ProtonVersion = detectProtonVersion(prefixRoot, configuration)
+
+	if winetricksState, err := ReadWinetricksState(PfxFolder(prefixRoot)); err == nil {
+		manifest.WinetricksVerbs = make([]string, 0, len(winetricksState.Verbs))
+
+		for verb := range winetricksState.Verbs {
+			manifest.WinetricksVerbs = append(manifest.WinetricksVerbs, verb)
+		}
+
+		slices.Sort(manifest.WinetricksVerbs)
+	}
+
+	manifest.DxvkVersion = detectDxvkVersion(manifest.WinetricksVerbs)
+	manifest.UpdatedAt = time.Now()
+
+	if err := writeManifest(prefixRoot, manifest); err != nil {
+		log.Printf("Failed to write prefix manifest in %s: %s\n", prefixRoot, err)
+	}
+}
+
+func writeManifest(prefixRoot string, manifest Manifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return system.FS.WriteFile(filepath.Join(prefixRoot, MANIFEST_FILENAME), content, config.DEFAULT_PERMISSION)
+}
+
+// ListManagedPrefixes returns the prefixes under compatDataBase, with an empty manifest for the ones created before manifests existed.
+func ListManagedPrefixes(compatDataBase string) ([]ManagedPrefix, error) {
+	entries, err := os.ReadDir(compatDataBase)
+
+	// Nothing was launched yet
+	if os.IsNotExist(err) {
+		return make([]ManagedPrefix, 0), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := make([]ManagedPrefix, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == INSTANCES_FOLDER_NAME {
+			continue
+		}
+
+		folder := filepath.Join(compatDataBase, entry.Name())
+		manifest, _ := ReadManifest(folder)
+
+		if manifest.Name == "" {
+			manifest.Name = entry.Name()
+		}
+
+		prefixes = append(prefixes, ManagedPrefix{folder, manifest})
+	}
+
+	return prefixes, nil
+}
+
+// IsOrphaned reports whether Steam no longer links to the prefix, which happens once the game is uninstalled.
+func (managedPrefix ManagedPrefix) IsOrphaned() bool {
+	if managedPrefix.Manifest.SteamCompatData == "" {
+		return false
+	}
+
+	target, err := os.Readlink(managedPrefix.Manifest.SteamCompatData)
+
+	return err != nil || filepath.Clean(target) != filepath.Clean(managedPrefix.Folder)
+}
+
+// PfxFolder returns the wine prefix inside a Steam compat data folder, or prefixRoot itself for umu prefixes.
+func PfxFolder(prefixRoot string) string {
+	pfx := filepath.Join(prefixRoot, "pfx")
+
+	if info, err := os.Stat(pfx); err == nil && info.IsDir() {
+		return pfx
+	}
+
+	return prefixRoot
+}
+
+func detectProtonVersion(prefixRoot string, configuration config.Configuration) string {
+	// Proton records the version that last ran the prefix in its root
+	if version, err := os.ReadFile(filepath.Join(prefixRoot, "version")); err == nil {
+		return strings.TrimSpace(string(version))
+	}
+
+	if configuration.Umu.Proton != "" {
+		return filepath.Base(configuration.Umu.Proton)
+	}
+
+	return ""
+}
+
+// DXVK installed through winetricks shows up as a dxvkNNNN verb, otherwise Proton provides its own
+func detectDxvkVersion(winetricksVerbs []string) string {
+	for i := len(winetricksVerbs) - 1; i >= 0; i-- {
+		if match := winetricksDxvkVerbRegex.FindStringSubmatch(winetricksVerbs[i]); match != nil {
+			return match[0]
+		}
+	}
+
+	return PROTON_BUNDLED_DXVK
+}
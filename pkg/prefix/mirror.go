@@ -0,0 +1,24 @@
+package prefix
+
+import (
+	"log"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// MirrorSteamCompatData brings the mirror of compatData under mirrorsFolder up to date, copying only what changed
+// and deleting what compatData no longer has.
+func MirrorSteamCompatData(configuration config.Configuration, compatData string, mirrorsFolder string) error {
+	mirrorFolder := config.GameFolder(mirrorsFolder, configuration.Props["name"])
+
+	log.Printf("Mirroring compat data %s to %s\n", compatData, mirrorFolder)
+
+	if err := system.FS.SyncDir(compatData, mirrorFolder); err != nil {
+		return err
+	}
+
+	UpdateManifest(mirrorFolder, configuration, compatData)
+
+	return nil
+}
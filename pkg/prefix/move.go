@@ -0,0 +1,135 @@
+package prefix
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// MovePrefix moves managedPrefix under newBase, keeping its folder name, and points its Steam compat data link
+// at the new folder. Returns the new folder.
+func MovePrefix(managedPrefix ManagedPrefix, newBase string) (string, error) {
+	newFolder := filepath.Join(newBase, filepath.Base(managedPrefix.Folder))
+
+	if _, err := os.Stat(newFolder); err == nil {
+		return "", fmt.Errorf("%s already exists", newFolder)
+	}
+
+	if err := system.FS.MkdirAll(newBase, config.DEFAULT_PERMISSION); err != nil {
+		return "", err
+	}
+
+	copied := false
+	err := system.FS.Rename(managedPrefix.Folder, newFolder)
+
+	// Another drive, the prefix is copied next to its destination first so newFolder only appears complete
+	if errors.Is(err, syscall.EXDEV) {
+		copied = true
+		err = copyPrefix(managedPrefix.Folder, newFolder)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to move %s to %s: %s", managedPrefix.Folder, newFolder, err)
+	}
+
+	if err := retargetSteamLink(managedPrefix, newFolder); err != nil {
+		if copied {
+			system.FS.RemoveAll(newFolder)
+		} else {
+			system.FS.Rename(newFolder, managedPrefix.Folder)
+		}
+
+		return "", fmt.Errorf("Failed to point %s at %s: %s", managedPrefix.Manifest.SteamCompatData, newFolder, err)
+	}
+
+	moveInstances(managedPrefix.Folder, newFolder)
+
+	if copied {
+		if err := system.FS.RemoveAll(managedPrefix.Folder); err != nil {
+			return newFolder, fmt.Errorf("Moved to %s but failed to delete %s: %s", newFolder, managedPrefix.Folder, err)
+		}
+	}
+
+	return newFolder, nil
+}
+
+// The copy keeps the symlinks of the prefix, dosdevices among them, and is checked against it before the prefix is
+// deleted
+func copyPrefix(folder string, newFolder string) error {
+	partialFolder := newFolder + ".moving"
+	system.FS.RemoveAll(partialFolder)
+
+	if err := system.FS.CloneDir(folder, partialFolder); err != nil {
+		system.FS.RemoveAll(partialFolder)
+		return err
+	}
+
+	if err := system.CompareDirs(folder, partialFolder); err != nil && !system.Simulating() {
+		system.FS.RemoveAll(partialFolder)
+		return fmt.Errorf("the copy differs from the prefix: %w", err)
+	}
+
+	return system.FS.Rename(partialFolder, newFolder)
+}
+
+// The link is replaced through a rename, Steam never sees it missing
+func retargetSteamLink(managedPrefix ManagedPrefix, newFolder string) error {
+	link := managedPrefix.Manifest.SteamCompatData
+
+	if link == "" {
+		return nil
+	}
+
+	if target, err := os.Readlink(link); err != nil || filepath.Clean(target) != filepath.Clean(managedPrefix.Folder) {
+		return nil
+	}
+
+	newLink := link + ".plauncher-new"
+	system.FS.Remove(newLink)
+
+	if err := system.FS.Symlink(newFolder, newLink); err != nil {
+		return err
+	}
+
+	return system.FS.Rename(newLink, link)
+}
+
+// RenamePrefix renames the folder of managedPrefix after newName, for a game whose name changed, points its Steam
+// compat data link at it and records newName in its manifest. Returns the new folder.
+func RenamePrefix(managedPrefix ManagedPrefix, newName string) (string, error) {
+	newFolder := filepath.Join(filepath.Dir(managedPrefix.Folder), config.GameSlug(newName))
+
+	if newFolder == managedPrefix.Folder {
+		return newFolder, nil
+	}
+
+	if _, err := os.Stat(newFolder); err == nil {
+		return "", fmt.Errorf("%s already exists", newFolder)
+	}
+
+	if err := system.FS.Rename(managedPrefix.Folder, newFolder); err != nil {
+		return "", fmt.Errorf("Failed to rename %s to %s: %s", managedPrefix.Folder, newFolder, err)
+	}
+
+	if err := retargetSteamLink(managedPrefix, newFolder); err != nil {
+		system.FS.Rename(newFolder, managedPrefix.Folder)
+		return "", fmt.Errorf("Failed to point %s at %s: %s", managedPrefix.Manifest.SteamCompatData, newFolder, err)
+	}
+
+	moveInstances(managedPrefix.Folder, newFolder)
+
+	manifest := managedPrefix.Manifest
+	manifest.Name = newName
+	manifest.Slug = filepath.Base(newFolder)
+
+	if err := writeManifest(newFolder, manifest); err != nil {
+		return newFolder, fmt.Errorf("Renamed to %s but failed to update its manifest: %s", newFolder, err)
+	}
+
+	return newFolder, nil
+}
@@ -0,0 +1,51 @@
+package prefix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyPrefixKeepsSymlinks(t *testing.T) {
+	base := t.TempDir()
+	folder := filepath.Join(base, "Game")
+	newFolder := filepath.Join(base, "other", "Game")
+
+	for _, dir := range []string{"pfx/dosdevices", "pfx/drive_c/windows"} {
+		if err := os.MkdirAll(filepath.Join(folder, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(folder, "pfx/drive_c/windows/win.ini"), []byte("[fonts]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	links := map[string]string{"pfx/dosdevices/c:": "../drive_c", "pfx/dosdevices/z:": "/"}
+
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(folder, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.MkdirAll(filepath.Dir(newFolder), 0o755)
+
+	if err := copyPrefix(folder, newFolder); err != nil {
+		t.Fatalf("copyPrefix: %s", err)
+	}
+
+	for link, target := range links {
+		if copied, err := os.Readlink(filepath.Join(newFolder, link)); err != nil || copied != target {
+			t.Errorf("%s points at %q (%v), expected %q", link, copied, err, target)
+		}
+	}
+
+	if content, err := os.ReadFile(filepath.Join(newFolder, "pfx/drive_c/windows/win.ini")); err != nil || string(content) != "[fonts]\n" {
+		t.Errorf("win.ini not copied: %q %v", content, err)
+	}
+
+	if _, err := os.Stat(newFolder + ".moving"); !os.IsNotExist(err) {
+		t.Errorf("partial copy left behind: %v", err)
+	}
+}
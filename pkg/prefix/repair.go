@@ -0,0 +1,129 @@
+package prefix
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// RepairCompatDataLinks points the Steam compat data links recorded in the manifests under compatDataBase
+// back at their prefix when they dangle, e.g. after the data folder moved. Returns the repaired links.
+func RepairCompatDataLinks(compatDataBase string) ([]string, error) {
+	repaired := make([]string, 0)
+	managedPrefixes, err := ListManagedPrefixes(compatDataBase)
+
+	if err != nil {
+		return repaired, err
+	}
+
+	for _, managedPrefix := range managedPrefixes {
+		link := managedPrefix.Manifest.SteamCompatData
+
+		if link == "" || !isDanglingSymlink(link) {
+			continue
+		}
+
+		if err := relink(managedPrefix.Folder, link); err != nil {
+			log.Printf("Failed to repair compat data link %s: %s\n", link, err)
+			continue
+		}
+
+		repaired = append(repaired, link)
+	}
+
+	return repaired, nil
+}
+
+// A dangling Steam compat data link makes Proton create an empty prefix where it points, the prefix it
+// belonged to is moved to newCompatData instead when it can be found
+func repairDanglingCompatData(configuration *config.Configuration, oldCompatData string, newCompatData string, newCompatDataBase string) {
+	if _, err := os.Stat(newCompatData); err == nil {
+		return
+	}
+
+	target, _ := os.Readlink(oldCompatData)
+	found, exists := findRelocatedPrefix(newCompatDataBase, oldCompatData, target, configuration.Props["id"])
+
+	if !exists {
+		log.Printf("WARNING: compat data link %s points to %s, which no longer exists\n", oldCompatData, target)
+		fmt.Fprintf(os.Stderr, "plauncher: the prefix of %s at %s is gone, a new one is created in %s\n", configuration.Props["name"], target, newCompatData)
+		return
+	}
+
+	if err := system.FS.Rename(found, newCompatData); err != nil {
+		system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to move prefix %s to %s: %s\n", found, newCompatData, err)
+		return
+	}
+
+	log.Printf("Repaired compat data link %s, its prefix moved from %s to %s\n", oldCompatData, found, newCompatData)
+}
+
+// Looks for the prefix by the folder name the link pointed to, then by the Steam path or game id of the manifests
+func findRelocatedPrefix(compatDataBase string, steamCompatData string, target string, id string) (string, bool) {
+	if target != "" {
+		byTarget := filepath.Join(compatDataBase, filepath.Base(target))
+
+		if info, err := os.Stat(byTarget); err == nil && info.IsDir() {
+			return byTarget, true
+		}
+	}
+
+	managedPrefixes, err := ListManagedPrefixes(compatDataBase)
+
+	if err != nil {
+		return "", false
+	}
+
+	for _, managedPrefix := range managedPrefixes {
+		manifest := managedPrefix.Manifest
+
+		if manifest.SteamCompatData == steamCompatData || (id != "" && manifest.Id == id) {
+			return managedPrefix.Folder, true
+		}
+	}
+
+	return "", false
+}
+
+// DanglingCompatDataLinks returns the Steam compat data links of the prefixes under compatDataBase that point
+// nowhere, the ones RepairCompatDataLinks fixes.
+func DanglingCompatDataLinks(compatDataBase string) ([]string, error) {
+	dangling := make([]string, 0)
+	managedPrefixes, err := ListManagedPrefixes(compatDataBase)
+
+	if err != nil {
+		return dangling, err
+	}
+
+	for _, managedPrefix := range managedPrefixes {
+		if link := managedPrefix.Manifest.SteamCompatData; link != "" && isDanglingSymlink(link) {
+			dangling = append(dangling, link)
+		}
+	}
+
+	return dangling, nil
+}
+
+func isDanglingSymlink(path string) bool {
+	info, err := os.Lstat(path)
+
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+
+	_, err = os.Stat(path)
+
+	return os.IsNotExist(err)
+}
+
+func relink(target string, link string) error {
+	if err := system.FS.Remove(link); err != nil {
+		return err
+	}
+
+	return system.FS.Symlink(target, link)
+}
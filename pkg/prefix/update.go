@@ -0,0 +1,74 @@
+package prefix
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"time"
+)
+
+// GameBuild identifies the game executable a prefix was last launched with.
+type GameBuild struct {
+	Exe     string    `json:"exe"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod-time"`
+	Sha256  string    `json:"sha256"`
+}
+
+// RecordGameBuild compares gameExe with the build recorded in the manifest of prefixRoot, records it and returns
+// whether the game was updated since. The executable is only hashed when its size or modification time changed,
+// a first record or another executable of the game is not an update. An update also schedules a new dependency scan.
+func RecordGameBuild(prefixRoot string, gameExe string) (bool, error) {
+	info, err := os.Stat(gameExe)
+
+	if err != nil {
+		return false, err
+	}
+
+	manifest, manifestErr := ReadManifest(prefixRoot)
+	previous := manifest.GameBuild
+
+	if previous != nil && previous.Exe == gameExe && previous.Size == info.Size() && previous.ModTime.Equal(info.ModTime()) {
+		return false, nil
+	}
+
+	hash, err := hashFile(gameExe)
+
+	if err != nil {
+		return false, err
+	}
+
+	updated := previous != nil && previous.Exe == gameExe && previous.Sha256 != hash
+
+	if manifestErr != nil {
+		manifest.CreatedAt = time.Now()
+	}
+
+	if updated {
+		manifest.DependenciesChecked = false
+	}
+
+	manifest.GameBuild = &GameBuild{gameExe, info.Size(), info.ModTime(), hash}
+	manifest.UpdatedAt = time.Now()
+
+	return updated, writeManifest(prefixRoot, manifest)
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,54 @@
+package prefix
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// Windows versions accepted in wine.windows-version, as winecfg names them
+var WINDOWS_VERSIONS = []string{config.WINDOWS_VERSION_WIN7, config.WINDOWS_VERSION_WIN10, config.WINDOWS_VERSION_WIN11}
+
+// SetWindowsVersion makes the prefix rooted at prefixRoot report version to games, through winecfg of wine.
+// The version set is kept in the prefix manifest, a prefix already reporting it is left alone.
+func SetWindowsVersion(prefixRoot string, version string, wine string) error {
+	manifest, manifestErr := ReadManifest(prefixRoot)
+
+	if manifestErr == nil && manifest.WindowsVersion == version {
+		return nil
+	}
+
+	if wine == "" {
+		return errors.New("No wine binary known")
+	}
+
+	log.Printf("Setting Windows version %s in %s\n", version, prefixRoot)
+
+	// A prefix still held by a wineserver makes winecfg fail until it exits
+	err := system.Retry(wrappers.WINE_BIN_NAME, func() error {
+		cmdHandle := exec.Command(wine, "winecfg", "/v", version)
+		cmdHandle.Env = append(os.Environ(), fmt.Sprintf("%s=%s", "WINEPREFIX", PfxFolder(prefixRoot)))
+
+		return system.RunBounded(system.TIMEOUT_WINETRICKS, cmdHandle)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if manifestErr != nil {
+		manifest.CreatedAt = time.Now()
+	}
+
+	manifest.WindowsVersion = version
+	manifest.UpdatedAt = time.Now()
+
+	return writeManifest(prefixRoot, manifest)
+}
@@ -0,0 +1,60 @@
+package prefix
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// SetupWineConfigInPrefix switches the prefix audio driver according to wine.alsa.
+func SetupWineConfigInPrefix(configuration config.Configuration, compatDataBase string) {
+	if name, exists := configuration.Props["name"]; exists {
+		currentAudioDriver := "pulse"
+
+		prefixFolder := filepath.Join(compatDataBase, name, "pfx")
+		winetricksState, err := ReadWinetricksState(prefixFolder)
+
+		if err != nil {
+			system.Warnf(system.EXIT_PREFIX_ERROR, "Failed to read winetricks log file: %s\n", err)
+			return
+		}
+
+		if soundDriver, exists := winetricksState.Setting("sound"); exists {
+			log.Printf("Winetricks audio driver: %s", soundDriver)
+			currentAudioDriver = soundDriver
+		}
+
+		if configuration.Wine.Alsa && currentAudioDriver == "pulse" {
+			setupAudioDriverInWine(prefixFolder, "alsa")
+			return
+		}
+
+		if !configuration.Wine.Alsa && currentAudioDriver == "alsa" {
+			setupAudioDriverInWine(prefixFolder, "pulse")
+			return
+		}
+	}
+}
+
+func setupAudioDriverInWine(prefixFolder string, driver string) {
+	if cmd, exists := wrappers.CheckIfBinExists(wrappers.WINETRICKS_BIN_NAME); exists {
+		log.Printf("Updating %s with audio driver %s\n", prefixFolder, driver)
+
+		err := system.Retry(wrappers.WINETRICKS_BIN_NAME, func() error {
+			cmdHandle := exec.Command(cmd, "settings", fmt.Sprintf("sound=%s", driver))
+			cmdHandle.Env = append(os.Environ(), fmt.Sprintf("%s=%s", "WINEPREFIX", prefixFolder))
+
+			return system.RunBounded(system.TIMEOUT_WINETRICKS, cmdHandle)
+		})
+
+		if err != nil {
+			system.Warnf(system.EXIT_PREFIX_ERROR, "Could not enable %s in prefix\n", driver)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package prefix
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const WINETRICKS_LOG_FILENAME = "winetricks.log"
+
+// WinetricksState is what winetricks.log says was applied to a prefix.
+type WinetricksState struct {
+	// Verbs holds every plain verb applied, e.g. vcrun2019 or corefonts.
+	Verbs map[string]bool
+	// Settings holds the last value of every key=value setting, e.g. sound=alsa.
+	Settings map[string]string
+}
+
+// HasVerb reports whether verb was applied to the prefix.
+func (state WinetricksState) HasVerb(verb string) bool {
+	return state.Verbs[verb]
+}
+
+// Setting returns the last value winetricks applied for key.
+func (state WinetricksState) Setting(key string) (string, bool) {
+	value, exists := state.Settings[key]
+	return value, exists
+}
+
+// ReadWinetricksState parses the winetricks.log of prefixFolder (the pfx folder), a missing log is an empty state.
+func ReadWinetricksState(prefixFolder string) (WinetricksState, error) {
+	state := WinetricksState{make(map[string]bool), make(map[string]string)}
+
+	logHandle, err := os.Open(filepath.Join(prefixFolder, WINETRICKS_LOG_FILENAME))
+
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+
+	if err != nil {
+		return state, err
+	}
+
+	defer logHandle.Close()
+
+	scanner := bufio.NewScanner(logHandle)
+
+	for scanner.Scan() {
+		entry := strings.TrimSpace(scanner.Text())
+
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		if key, value, isSetting := strings.Cut(entry, "="); isSetting {
+			state.Settings[key] = value
+			continue
+		}
+
+		state.Verbs[entry] = true
+	}
+
+	return state, scanner.Err()
+}
+
+// InstallWinetricksVerbs applies the verbs missing from the winetricks.log of prefixFolder (the pfx folder).
+func InstallWinetricksVerbs(prefixFolder string, verbs []string) error {
+	state, err := ReadWinetricksState(prefixFolder)
+
+	if err != nil {
+		return err
+	}
+
+	missing := make([]string, 0, len(verbs))
+
+	for _, verb := range verbs {
+		if !state.HasVerb(verb) {
+			missing = append(missing, verb)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	cmd, exists := wrappers.CheckIfBinExists(wrappers.WINETRICKS_BIN_NAME)
+
+	if !exists {
+		return fmt.Errorf("%s is not installed, missing verbs: %s", wrappers.WINETRICKS_BIN_NAME, strings.Join(missing, " "))
+	}
+
+	log.Printf("Installing winetricks verbs in %s: %s\n", prefixFolder, strings.Join(missing, " "))
+
+	// A prefix still held by a wineserver makes winetricks fail until it exits
+	return system.Retry(wrappers.WINETRICKS_BIN_NAME, func() error {
+		cmdHandle := exec.Command(cmd, append([]string{"-q"}, missing...)...)
+		cmdHandle.Env = append(os.Environ(), fmt.Sprintf("%s=%s", "WINEPREFIX", prefixFolder))
+
+		return system.RunBounded(system.TIMEOUT_WINETRICKS, cmdHandle)
+	})
+}
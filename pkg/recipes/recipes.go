@@ -0,0 +1,99 @@
+// Package recipes holds tweaks known to be required by specific games (environment variables, winetricks
+// verbs and launch args), bundled with plauncher and updatable from a URL.
+package recipes
+
+import (
+	_ "embed"
+	"fmt"
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+//go:embed recipes.yaml
+var bundledRecipes []byte
+
+// Recipe is the set of tweaks a game needs.
+type Recipe struct {
+	Id          string            `yaml:"id" json:"id"`
+	Name        string            `yaml:"name" json:"name"`
+	Environment map[string]string `yaml:"environment,omitempty" json:"environment,omitempty"`
+	Winetricks  []string          `yaml:"winetricks,omitempty" json:"winetricks,omitempty"`
+	LaunchArgs  []string          `yaml:"launch-args,omitempty" json:"launch-args,omitempty"`
+	Notes       string            `yaml:"notes,omitempty" json:"notes,omitempty"`
+}
+
+// FindRecipe looks appid up in the bundled recipes, then in recipeFiles, later files taking precedence.
+func FindRecipe(appid string, recipeFiles ...string) (Recipe, bool) {
+	found := Recipe{}
+	exists := false
+
+	if appid == "" {
+		return found, exists
+	}
+
+	recipeLists := [][]Recipe{parseRecipes("bundled recipes", bundledRecipes)}
+
+	for _, recipeFile := range recipeFiles {
+		recipeLists = append(recipeLists, readRecipes(recipeFile))
+	}
+
+	for _, recipeList := range recipeLists {
+		for _, recipe := range recipeList {
+			if recipe.Id == appid {
+				found, exists = recipe, true
+			}
+		}
+	}
+
+	return found, exists
+}
+
+// RecipeFiles returns the recipe files of paths, lowest precedence first: fetched, system-wide then user.
+func RecipeFiles(paths config.Paths) []string {
+	return []string{paths.FetchedRecipesFile, paths.SystemRecipesFile, paths.RecipesFile}
+}
+
+// Fetch downloads the recipes at url into recipesFile, after checking they parse.
+func Fetch(url string, recipesFile string) ([]Recipe, error) {
+	content, err := system.HttpGet(url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fetched := make([]Recipe, 0)
+
+	if err := yaml.Unmarshal(content, &fetched); err != nil {
+		return nil, fmt.Errorf("%s does not hold valid recipes: %s", url, err)
+	}
+
+	log.Printf("Saving %d recipes from %s in %s\n", len(fetched), url, recipesFile)
+
+	return fetched, system.FS.WriteFile(recipesFile, content, config.DEFAULT_PERMISSION)
+}
+
+func readRecipes(recipeFile string) []Recipe {
+	content, err := os.ReadFile(recipeFile)
+
+	if err != nil {
+		return nil
+	}
+
+	return parseRecipes(recipeFile, content)
+}
+
+func parseRecipes(source string, content []byte) []Recipe {
+	recipes := make([]Recipe, 0)
+
+	if err := yaml.Unmarshal(content, &recipes); err != nil {
+		log.Printf("Ignoring invalid recipes %s: %s\n", source, err)
+		return nil
+	}
+
+	return recipes
+}
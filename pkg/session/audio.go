@@ -0,0 +1,201 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+// pactl talks to PulseAudio and to pipewire-pulse alike
+const PACTL_BIN_NAME = "pactl"
+
+// Games open their streams well after starting, sometimes again when changing settings
+const AUDIO_ROUTING_INTERVAL = 2 * time.Second
+
+type pactlSink struct {
+	Index       int    `json:"index"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type pactlSinkInput struct {
+	Index      int               `json:"index"`
+	Sink       int               `json:"sink"`
+	Properties map[string]string `json:"properties"`
+}
+
+// AudioService moves the audio streams of the game processes to the configured sink once they appear.
+type AudioService struct {
+	pactl       string
+	sink        pactlSink
+	defaultSink string
+	moved       map[int]int
+	stop        chan struct{}
+	done        sync.WaitGroup
+}
+
+func (service *AudioService) Name() string {
+	return "audio"
+}
+
+func (service *AudioService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Audio.Sink != ""
+}
+
+func (service *AudioService) Start(configuration *config.Configuration, paths config.Paths) error {
+	bin, exists := wrappers.CheckIfBinExists(PACTL_BIN_NAME)
+
+	if !exists {
+		return fmt.Errorf("%s is not installed", PACTL_BIN_NAME)
+	}
+
+	service.pactl = bin
+	sinks := make([]pactlSink, 0)
+
+	if err := listPactl(service.pactl, "sinks", &sinks); err != nil {
+		return fmt.Errorf("Failed to list audio sinks: %s", err)
+	}
+
+	sink, found := findSink(sinks, configuration.Audio.Sink)
+
+	if !found {
+		return fmt.Errorf("No audio sink matches %s", configuration.Audio.Sink)
+	}
+
+	// Moving streams may be remembered as the preferred device, the default is put back on exit
+	defaultSink, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(bin, "get-default-sink"))
+
+	if err != nil {
+		return fmt.Errorf("Failed to read the default audio sink: %s", err)
+	}
+
+	log.Printf("Routing game audio to %s (%s)\n", sink.Description, sink.Name)
+
+	service.sink = sink
+	service.defaultSink = strings.TrimSpace(string(defaultSink))
+	service.moved = make(map[int]int)
+	service.stop = make(chan struct{})
+
+	return nil
+}
+
+func (service *AudioService) GameStarted(pid int) {
+	if pid <= 0 {
+		return
+	}
+
+	service.done.Add(1)
+
+	go func() {
+		defer service.done.Done()
+		service.route(pid)
+	}()
+}
+
+func (service *AudioService) Stop() error {
+	close(service.stop)
+	service.done.Wait()
+
+	errs := make([]error, 0)
+	sinkInputs := make([]pactlSinkInput, 0)
+	listPactl(service.pactl, "sink-inputs", &sinkInputs)
+
+	// Streams outliving the game, e.g. of a launcher left running, go back where they were
+	for _, sinkInput := range sinkInputs {
+		if previousSink, moved := service.moved[sinkInput.Index]; moved {
+			errs = append(errs, system.Exec.Run(exec.Command(service.pactl, "move-sink-input", strconv.Itoa(sinkInput.Index), strconv.Itoa(previousSink))))
+		}
+	}
+
+	defaultSink, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(service.pactl, "get-default-sink"))
+
+	if err == nil && strings.TrimSpace(string(defaultSink)) != service.defaultSink {
+		log.Printf("Restoring default audio sink %s\n", service.defaultSink)
+		errs = append(errs, system.Exec.Run(exec.Command(service.pactl, "set-default-sink", service.defaultSink)))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (service *AudioService) RestartRequested() bool {
+	return false
+}
+
+func (service *AudioService) route(pid int) {
+	ticker := time.NewTicker(AUDIO_ROUTING_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-service.stop:
+			return
+		case <-ticker.C:
+		}
+
+		sinkInputs := make([]pactlSinkInput, 0)
+
+		if err := listPactl(service.pactl, "sink-inputs", &sinkInputs); err != nil {
+			log.Printf("Failed to list audio streams: %s\n", err)
+			continue
+		}
+
+		processes := ProcessTree(pid)
+
+		for _, sinkInput := range sinkInputs {
+			streamPid, _ := strconv.Atoi(sinkInput.Properties["application.process.id"])
+			_, moved := service.moved[sinkInput.Index]
+
+			// Streams moved once are left where the user puts them afterwards
+			if moved || sinkInput.Sink == service.sink.Index || !slices.Contains(processes, streamPid) {
+				continue
+			}
+
+			if err := system.Exec.Run(exec.Command(service.pactl, "move-sink-input", strconv.Itoa(sinkInput.Index), service.sink.Name)); err != nil {
+				log.Printf("Failed to move audio stream %d: %s\n", sinkInput.Index, err)
+				continue
+			}
+
+			log.Printf("Moved audio stream %d of %s to %s\n", sinkInput.Index, sinkInput.Properties["application.name"], service.sink.Name)
+
+			service.moved[sinkInput.Index] = sinkInput.Sink
+		}
+	}
+}
+
+func listPactl(pactl string, kind string, entries any) error {
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(pactl, "--format=json", "list", kind))
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(output, entries)
+}
+
+// Sink names are exact, descriptions are what desktops show and are matched loosely
+func findSink(sinks []pactlSink, wanted string) (pactlSink, bool) {
+	for _, sink := range sinks {
+		if sink.Name == wanted {
+			return sink, true
+		}
+	}
+
+	for _, sink := range sinks {
+		if strings.Contains(strings.ToLower(sink.Description), strings.ToLower(wanted)) {
+			return sink, true
+		}
+	}
+
+	return pactlSink{}, false
+}
@@ -0,0 +1,180 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const PIPEWIRE_BIN_NAME = "pipewire"
+
+// The sinks of a filter chain show up shortly after it starts, audio.sink may name one of them
+const FILTER_CHAIN_STARTUP_TIMEOUT = 5 * time.Second
+const FILTER_CHAIN_STARTUP_INTERVAL = 250 * time.Millisecond
+
+type pactlCard struct {
+	Name          string                      `json:"name"`
+	Properties    map[string]string           `json:"properties"`
+	Profiles      map[string]pactlCardProfile `json:"profiles"`
+	ActiveProfile string                      `json:"active_profile"`
+}
+
+type pactlCardProfile struct {
+	Description string `json:"description"`
+	Available   bool   `json:"available"`
+}
+
+// AudioProfileService switches a sound card profile, or runs a PipeWire filter chain, for the session.
+type AudioProfileService struct {
+	pactl           string
+	card            string
+	previousProfile string
+	filterChain     *exec.Cmd
+}
+
+func (service *AudioProfileService) Name() string {
+	return "audio-profile"
+}
+
+func (service *AudioProfileService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Audio.Profile != "" || configuration.Audio.FilterChain != ""
+}
+
+func (service *AudioProfileService) Start(configuration *config.Configuration, paths config.Paths) error {
+	service.card = ""
+	service.filterChain = nil
+	bin, exists := wrappers.CheckIfBinExists(PACTL_BIN_NAME)
+
+	if !exists {
+		return fmt.Errorf("%s is not installed", PACTL_BIN_NAME)
+	}
+
+	service.pactl = bin
+
+	if configuration.Audio.Profile != "" {
+		if err := service.switchProfile(configuration.Audio.Card, configuration.Audio.Profile); err != nil {
+			return err
+		}
+	}
+
+	if configuration.Audio.FilterChain != "" {
+		filterChain := config.ExpandUserPath(configuration.Audio.FilterChain, paths.HomeDir)
+
+		if !filepath.IsAbs(filterChain) {
+			filterChain = filepath.Join(paths.AppConfigFolder, filterChain)
+		}
+
+		if err := service.startFilterChain(filterChain); err != nil {
+			// The profile is put back even though the service did not start
+			return errors.Join(err, service.restoreProfile())
+		}
+	}
+
+	return nil
+}
+
+func (service *AudioProfileService) Stop() error {
+	errs := make([]error, 0)
+
+	if service.filterChain != nil && service.filterChain.Process != nil {
+		errs = append(errs, service.filterChain.Process.Signal(syscall.SIGTERM))
+		system.Exec.Wait(service.filterChain)
+	}
+
+	return errors.Join(append(errs, service.restoreProfile())...)
+}
+
+func (service *AudioProfileService) switchProfile(wantedCard string, profile string) error {
+	cards := make([]pactlCard, 0)
+
+	if err := listPactl(service.pactl, "cards", &cards); err != nil {
+		return fmt.Errorf("Failed to list sound cards: %s", err)
+	}
+
+	card, found := findCard(cards, wantedCard, profile)
+
+	if !found {
+		return fmt.Errorf("No sound card offers the audio profile %s", profile)
+	}
+
+	if card.ActiveProfile == profile {
+		return nil
+	}
+
+	if err := system.Exec.Run(exec.Command(service.pactl, "set-card-profile", card.Name, profile)); err != nil {
+		return fmt.Errorf("Failed to switch %s to %s: %s", card.Name, profile, err)
+	}
+
+	log.Printf("Switched sound card %s to %s, was %s\n", card.Name, profile, card.ActiveProfile)
+	service.card = card.Name
+	service.previousProfile = card.ActiveProfile
+
+	return nil
+}
+
+func (service *AudioProfileService) restoreProfile() error {
+	if service.card == "" {
+		return nil
+	}
+
+	return system.Exec.Run(exec.Command(service.pactl, "set-card-profile", service.card, service.previousProfile))
+}
+
+func (service *AudioProfileService) startFilterChain(filterChain string) error {
+	bin, exists := wrappers.CheckIfBinExists(PIPEWIRE_BIN_NAME)
+
+	if !exists {
+		return fmt.Errorf("%s is not installed, filter chains need it", PIPEWIRE_BIN_NAME)
+	}
+
+	sinks := make([]pactlSink, 0)
+	listPactl(service.pactl, "sinks", &sinks)
+	sinksBefore := len(sinks)
+
+	cmdHandle := exec.Command(bin, "-c", filterChain)
+
+	if _, err := system.Exec.Start(cmdHandle); err != nil {
+		return fmt.Errorf("Failed to start the filter chain %s: %s", filterChain, err)
+	}
+
+	service.filterChain = cmdHandle
+	log.Printf("Started audio filter chain %s\n", filterChain)
+
+	for waited := time.Duration(0); waited < FILTER_CHAIN_STARTUP_TIMEOUT; waited += FILTER_CHAIN_STARTUP_INTERVAL {
+		if err := listPactl(service.pactl, "sinks", &sinks); err == nil && len(sinks) > sinksBefore {
+			return nil
+		}
+
+		time.Sleep(FILTER_CHAIN_STARTUP_INTERVAL)
+	}
+
+	log.Printf("No new audio sink showed up for the filter chain %s\n", filterChain)
+
+	return nil
+}
+
+// Cards are matched by name or description, the first card offering profile is used when none is configured
+func findCard(cards []pactlCard, wanted string, profile string) (pactlCard, bool) {
+	for _, card := range cards {
+		if offered, exists := card.Profiles[profile]; !exists || !offered.Available {
+			continue
+		}
+
+		description := strings.ToLower(card.Properties["device.description"])
+
+		if wanted == "" || card.Name == wanted || strings.Contains(description, strings.ToLower(wanted)) {
+			return card, true
+		}
+	}
+
+	return pactlCard{}, false
+}
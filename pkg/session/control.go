@@ -0,0 +1,464 @@
+package session
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const XPROP_BIN_NAME = "xprop"
+
+// CONTROL_TIMEOUT bounds how long a control connection may take to send its command or read the reply
+const CONTROL_TIMEOUT = 5 * time.Second
+
+const CONTROL_REPLY_OK = "ok"
+const CONTROL_REPLY_ERROR = "error"
+
+// controlCommand answers a command received on the control socket, args being the words after its name
+type controlCommand func(service *ControlService, args []string) (string, error)
+
+var controlCommands = map[string]controlCommand{
+	"status":    controlStatus,
+	"mangohud":  controlMangohud,
+	"fps-limit": controlFpsLimit,
+	"gamescope": controlGamescope,
+}
+
+// gamescopeProperty is a setting gamescope reads from a property of its root window while it runs, values being
+// named (e.g. fsr) or numbers between min and max
+type gamescopeProperty struct {
+	atom   string
+	values map[string]int
+	min    int
+	max    int
+}
+
+var gamescopeProperties = map[string]gamescopeProperty{
+	"fps-limit": {"GAMESCOPE_FPS_LIMIT", nil, 0, 1000},
+	"filter":    {"GAMESCOPE_SCALING_FILTER", map[string]int{"linear": 0, "nearest": 1, "fsr": 2, "nis": 3, "pixel": 4}, 0, 0},
+	"sharpness": {"GAMESCOPE_SHARPNESS", nil, 0, 20},
+}
+
+// ControlService listens on a socket of the control folder for the length of the session, adjusting the running
+// game on commands like "mangohud toggle" or "gamescope set fps-limit 60", one per connection, each answered by a line starting
+// with ok or error.
+type ControlService struct {
+	listener *net.UnixListener
+	game     string
+	gamePid  int
+	done     sync.WaitGroup
+	lock     sync.Mutex
+}
+
+func (service *ControlService) Name() string {
+	return "control"
+}
+
+func (service *ControlService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Control.Enabled
+}
+
+func (service *ControlService) Start(configuration *config.Configuration, paths config.Paths) error {
+	if err := os.MkdirAll(paths.ControlFolder, 0700); err != nil {
+		return err
+	}
+
+	socket := ControlSocketFile(paths, os.Getpid())
+	os.Remove(socket)
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socket, Net: "unix"})
+
+	if err != nil {
+		return err
+	}
+
+	// Commands reach into the game, only its user may send them
+	os.Chmod(socket, 0600)
+
+	service.listener = listener
+	service.game = configuration.Props["name"]
+	service.gamePid = 0
+
+	service.done.Add(1)
+
+	go func() {
+		defer service.done.Done()
+		service.accept()
+	}()
+
+	log.Printf("Listening for control commands on %s\n", socket)
+
+	return nil
+}
+
+func (service *ControlService) GameStarted(pid int) {
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	service.gamePid = pid
+}
+
+func (service *ControlService) RestartRequested() bool {
+	return false
+}
+
+// Closing the listener removes the socket and ends accept
+func (service *ControlService) Stop() error {
+	err := service.listener.Close()
+	service.done.Wait()
+
+	return err
+}
+
+func (service *ControlService) accept() {
+	for {
+		connection, err := service.listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		service.serve(connection)
+	}
+}
+
+func (service *ControlService) serve(connection net.Conn) {
+	defer connection.Close()
+
+	connection.SetDeadline(time.Now().Add(CONTROL_TIMEOUT))
+	line, err := bufio.NewReader(connection).ReadString('\n')
+
+	if err != nil && line == "" {
+		return
+	}
+
+	words := strings.Fields(line)
+
+	if len(words) == 0 {
+		fmt.Fprintf(connection, "%s empty command\n", CONTROL_REPLY_ERROR)
+		return
+	}
+
+	command, found := controlCommands[words[0]]
+
+	if !found {
+		fmt.Fprintf(connection, "%s unknown command %s, expected one of %s\n", CONTROL_REPLY_ERROR, words[0], strings.Join(ControlCommandNames(), ", "))
+		return
+	}
+
+	reply, err := command(service, words[1:])
+
+	if err != nil {
+		log.Printf("Control command %s failed: %s\n", strings.Join(words, " "), err)
+		fmt.Fprintf(connection, "%s %s\n", CONTROL_REPLY_ERROR, err)
+		return
+	}
+
+	// plauncher control asks every launch for its status before sending a command
+	if words[0] != "status" {
+		log.Printf("Control command: %s\n", strings.Join(words, " "))
+	}
+
+	fmt.Fprintf(connection, "%s %s\n", CONTROL_REPLY_OK, reply)
+}
+
+// The processes of the game, nil until it started
+func (service *ControlService) gameProcesses() []int {
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	if service.gamePid <= 0 {
+		return nil
+	}
+
+	return ProcessTree(service.gamePid)
+}
+
+func controlStatus(service *ControlService, args []string) (string, error) {
+	return fmt.Sprintf("%s (pid %d)", service.game, os.Getpid()), nil
+}
+
+// MangoHud takes commands like :hud; or :logging=1; on its control socket
+func controlMangohud(service *ControlService, args []string) (string, error) {
+	message, done := "", ""
+
+	switch strings.Join(args, " ") {
+	case "toggle":
+		message, done = ":hud;", "MangoHud toggled"
+	case "logging start":
+		message, done = ":logging=1;", "MangoHud logging started"
+	case "logging stop":
+		message, done = ":logging=0;", "MangoHud logging stopped"
+	case "logging", "logging toggle":
+		message, done = ":logging;", "MangoHud logging toggled"
+	default:
+		return "", errors.New("expected mangohud toggle or mangohud logging start|stop|toggle")
+	}
+
+	sent, err := sendMangohudControl(message)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s in %d process(es)", done, sent), nil
+}
+
+func controlFpsLimit(service *ControlService, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("expected fps-limit <frames per second>, 0 lifting the limit")
+	}
+
+	return controlGamescope(service, []string{"set", "fps-limit", args[0]})
+}
+
+func controlGamescope(service *ControlService, args []string) (string, error) {
+	if len(args) != 3 || args[0] != "set" {
+		return "", fmt.Errorf("expected gamescope set <%s> <value>", strings.Join(GamescopePropertyNames(), "|"))
+	}
+
+	property, found := gamescopeProperties[args[1]]
+
+	if !found {
+		return "", fmt.Errorf("unknown gamescope property %s, expected one of %s", args[1], strings.Join(GamescopePropertyNames(), ", "))
+	}
+
+	value, err := property.parse(args[2])
+
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", args[1], err)
+	}
+
+	if err := service.setGamescopeProperty(property.atom, value); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("gamescope %s set to %s", args[1], args[2]), nil
+}
+
+func (property gamescopeProperty) parse(text string) (int, error) {
+	if property.values != nil {
+		value, found := property.values[strings.ToLower(text)]
+
+		if !found {
+			names := slices.Sorted(maps.Keys(property.values))
+			return 0, fmt.Errorf("%s is not one of %s", text, strings.Join(names, ", "))
+		}
+
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(text)
+
+	if err != nil || value < property.min || value > property.max {
+		return 0, fmt.Errorf("%s is not a number from %d to %d", text, property.min, property.max)
+	}
+
+	return value, nil
+}
+
+// GamescopePropertyNames lists the gamescope settings the control socket changes while the game runs.
+func GamescopePropertyNames() []string {
+	return slices.Sorted(maps.Keys(gamescopeProperties))
+}
+
+// Sets a property of the root window of the gamescope running the game, which gamescope applies right away
+func (service *ControlService) setGamescopeProperty(property string, value int) error {
+	display := gamescopeDisplay(service.gameProcesses())
+
+	if display == "" {
+		return errors.New("the game does not run in gamescope, which is needed to change it while it runs")
+	}
+
+	bin, exists := wrappers.CheckIfBinExists(XPROP_BIN_NAME)
+
+	if !exists {
+		return fmt.Errorf("%s is needed to reach gamescope", XPROP_BIN_NAME)
+	}
+
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(bin, "-display", display, "-root", "-f", property, "32c", "-set", property, strconv.Itoa(value)))
+
+	if err != nil {
+		return fmt.Errorf("%s failed: %s %s", XPROP_BIN_NAME, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// The X display gamescope opened for the game, read from the environment of the game processes
+func gamescopeDisplay(processes []int) string {
+	for _, pid := range processes {
+		environ, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+
+		if err != nil {
+			continue
+		}
+
+		variables := strings.Split(string(environ), "\x00")
+
+		if !slices.ContainsFunc(variables, func(variable string) bool { return strings.HasPrefix(variable, "GAMESCOPE_WAYLAND_DISPLAY=") }) {
+			continue
+		}
+
+		for _, variable := range variables {
+			if display, found := strings.CutPrefix(variable, "DISPLAY="); found && display != "" {
+				return display
+			}
+		}
+	}
+
+	return ""
+}
+
+// Sends message to every MangoHud of the launch, returning how many received it
+func sendMangohudControl(message string) (int, error) {
+	sockets := mangohudControlSockets(wrappers.MangohudControlName(os.Getpid()))
+
+	if len(sockets) == 0 {
+		return 0, errors.New("no MangoHud is listening, is mangohud.enabled set and the game using Vulkan or OpenGL?")
+	}
+
+	sent := 0
+
+	for _, socket := range sockets {
+		connection, err := net.DialTimeout("unix", "@"+socket, CONTROL_TIMEOUT)
+
+		if err != nil {
+			log.Printf("Failed to reach MangoHud on %s: %s\n", socket, err)
+			continue
+		}
+
+		connection.SetDeadline(time.Now().Add(CONTROL_TIMEOUT))
+
+		if _, err := connection.Write([]byte(message)); err == nil {
+			sent++
+		}
+
+		connection.Close()
+	}
+
+	if sent == 0 {
+		return 0, errors.New("no MangoHud could be reached")
+	}
+
+	return sent, nil
+}
+
+// The abstract sockets starting with prefix, from /proc/net/unix where their names start with @
+func mangohudControlSockets(prefix string) []string {
+	content, err := os.ReadFile("/proc/net/unix")
+
+	if err != nil {
+		return nil
+	}
+
+	sockets := make([]string, 0)
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) < 8 {
+			continue
+		}
+
+		if name, found := strings.CutPrefix(fields[len(fields)-1], "@"); found && strings.HasPrefix(name, prefix) && !slices.Contains(sockets, name) {
+			sockets = append(sockets, name)
+		}
+	}
+
+	return sockets
+}
+
+// ControlSocketFile returns the control socket of the launch running as pid.
+func ControlSocketFile(paths config.Paths, pid int) string {
+	return filepath.Join(paths.ControlFolder, fmt.Sprintf("%d.sock", pid))
+}
+
+// ControlSockets returns the control sockets of the running launches, the most recent first. Sockets left behind by
+// launches that did not stop cleanly are removed.
+func ControlSockets(paths config.Paths) []string {
+	sockets, _ := filepath.Glob(filepath.Join(paths.ControlFolder, "*.sock"))
+	running := make([]string, 0, len(sockets))
+	startedAt := make(map[string]time.Time)
+
+	for _, socket := range sockets {
+		pid, _ := strconv.Atoi(strings.TrimSuffix(filepath.Base(socket), ".sock"))
+		info, err := os.Stat(socket)
+
+		if err != nil {
+			continue
+		}
+
+		if pid <= 0 || syscall.Kill(pid, 0) == syscall.ESRCH {
+			os.Remove(socket)
+			continue
+		}
+
+		running = append(running, socket)
+		startedAt[socket] = info.ModTime()
+	}
+
+	slices.SortFunc(running, func(a string, b string) int { return startedAt[b].Compare(startedAt[a]) })
+
+	return running
+}
+
+// SendControl sends command to the launch listening on socket, returning its reply.
+func SendControl(socket string, command string) (string, error) {
+	connection, err := net.DialTimeout("unix", socket, CONTROL_TIMEOUT)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer connection.Close()
+
+	connection.SetDeadline(time.Now().Add(CONTROL_TIMEOUT))
+
+	if _, err := fmt.Fprintf(connection, "%s\n", command); err != nil {
+		return "", err
+	}
+
+	reply, err := bufio.NewReader(connection).ReadString('\n')
+
+	if err != nil && reply == "" {
+		return "", err
+	}
+
+	status, message, _ := strings.Cut(strings.TrimSpace(reply), " ")
+
+	if status != CONTROL_REPLY_OK {
+		return "", errors.New(message)
+	}
+
+	return message, nil
+}
+
+// ControlCommandNames lists the commands the control socket takes.
+func ControlCommandNames() []string {
+	names := make([]string, 0, len(controlCommands))
+
+	for name := range controlCommands {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,43 @@
+package session
+
+import (
+	"path/filepath"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/mods"
+)
+
+// DllSwapService swaps the configured DLLs into the game folder and restores the originals once the game exits.
+type DllSwapService struct {
+	deploymentFile string
+}
+
+func (service *DllSwapService) Name() string {
+	return "dll-swaps"
+}
+
+func (service *DllSwapService) Enabled(configuration *config.Configuration) bool {
+	return len(configuration.DllSwaps) > 0
+}
+
+func (service *DllSwapService) Start(configuration *config.Configuration, paths config.Paths) error {
+	gameFolder, err := mods.GameInstallFolder(*configuration, paths.HomeDir)
+
+	if err != nil {
+		return err
+	}
+
+	game := config.GameSlug(filepath.Base(gameFolder))
+
+	if name := configuration.Props["name"]; name != "" {
+		game = config.GameSlug(name)
+	}
+
+	service.deploymentFile = filepath.Join(paths.ModsStateFolder, game+".dlls.json")
+
+	return mods.SwapDlls(configuration.DllSwaps, gameFolder, paths.HomeDir, service.deploymentFile)
+}
+
+func (service *DllSwapService) Stop() error {
+	return mods.Undeploy(service.deploymentFile)
+}
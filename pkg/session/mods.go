@@ -0,0 +1,56 @@
+package session
+
+import (
+	"path/filepath"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/mods"
+)
+
+// ModsService deploys the mod staging folder into the game folder and removes it once the game exits.
+type ModsService struct {
+	deploymentFile string
+}
+
+func (service *ModsService) Name() string {
+	return "mods"
+}
+
+func (service *ModsService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Mods.Staging != ""
+}
+
+func (service *ModsService) Start(configuration *config.Configuration, paths config.Paths) error {
+	gameFolder, err := mods.GameInstallFolder(*configuration, paths.HomeDir)
+
+	if err != nil {
+		return err
+	}
+
+	strategy := configuration.Mods.Strategy
+
+	// Configurations written before mods existed leave it empty
+	if strategy == "" {
+		strategy = config.MODS_STRATEGY_SYMLINK
+	}
+
+	game := config.GameSlug(filepath.Base(gameFolder))
+
+	if name := configuration.Props["name"]; name != "" {
+		game = config.GameSlug(name)
+	}
+
+	service.deploymentFile = filepath.Join(paths.ModsStateFolder, game+".json")
+
+	return mods.Deploy(
+		strategy,
+		config.ExpandUserPath(configuration.Mods.Staging, paths.HomeDir),
+		filepath.Join(gameFolder, configuration.Mods.Target),
+		service.deploymentFile,
+		filepath.Join(paths.ModsStateFolder, game),
+	)
+}
+
+func (service *ModsService) Stop() error {
+	return mods.Undeploy(service.deploymentFile)
+}
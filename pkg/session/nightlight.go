@@ -0,0 +1,100 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const PGREP_BIN_NAME = "pgrep"
+const PKILL_BIN_NAME = "pkill"
+const BUSCTL_BIN_NAME = "busctl"
+
+const KDE_NIGHT_LIGHT_SHORTCUT = "Toggle Night Color"
+
+// Both toggle their color temperature on SIGUSR1
+var nightLightDaemons = []string{"gammastep", "redshift"}
+
+// NightLightService pauses gammastep, redshift or KDE Night Color while the game runs.
+type NightLightService struct {
+	toggledDaemons []string
+	toggledKde     bool
+}
+
+func (service *NightLightService) Name() string {
+	return "night-light"
+}
+
+func (service *NightLightService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Display.SuspendNightLight
+}
+
+func (service *NightLightService) Start(configuration *config.Configuration, paths config.Paths) error {
+	service.toggledDaemons = make([]string, 0)
+	service.toggledKde = false
+
+	for _, daemon := range nightLightDaemons {
+		if isProcessRunning(daemon) && toggleNightLightDaemon(daemon) == nil {
+			service.toggledDaemons = append(service.toggledDaemons, daemon)
+		}
+	}
+
+	if strings.Contains(os.Getenv("XDG_CURRENT_DESKTOP"), "KDE") && toggleKdeNightLight() == nil {
+		service.toggledKde = true
+	}
+
+	if len(service.toggledDaemons) == 0 && !service.toggledKde {
+		return errors.New("No running night light found")
+	}
+
+	return nil
+}
+
+func (service *NightLightService) Stop() error {
+	errs := make([]error, 0)
+
+	for _, daemon := range service.toggledDaemons {
+		errs = append(errs, toggleNightLightDaemon(daemon))
+	}
+
+	if service.toggledKde {
+		errs = append(errs, toggleKdeNightLight())
+	}
+
+	return errors.Join(errs...)
+}
+
+func isProcessRunning(name string) bool {
+	bin, exists := wrappers.CheckIfBinExists(PGREP_BIN_NAME)
+
+	return exists && system.RunBounded(system.TIMEOUT_PROBE, exec.Command(bin, "-x", "-u", strconv.Itoa(os.Getuid()), name)) == nil
+}
+
+func toggleNightLightDaemon(daemon string) error {
+	bin, exists := wrappers.CheckIfBinExists(PKILL_BIN_NAME)
+
+	if !exists {
+		return errors.New("pkill is not installed")
+	}
+
+	return system.Exec.Run(exec.Command(bin, "-USR1", "-x", "-u", strconv.Itoa(os.Getuid()), daemon))
+}
+
+// Inhibiting through the NightLight interface only lasts as long as the caller stays on the bus,
+// the global shortcut keeps it inhibited until it is toggled back
+func toggleKdeNightLight() error {
+	bin, exists := wrappers.CheckIfBinExists(BUSCTL_BIN_NAME)
+
+	if !exists {
+		return errors.New("busctl is not installed")
+	}
+
+	return system.Exec.Run(exec.Command(bin, "--user", "call", "org.kde.kglobalaccel", "/component/kwin",
+		"org.kde.kglobalaccel.Component", "invokeShortcut", "s", KDE_NIGHT_LIGHT_SHORTCUT))
+}
@@ -0,0 +1,140 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const GPU_SCREEN_RECORDER_BIN_NAME = "gpu-screen-recorder"
+const OBS_CMD_BIN_NAME = "obs-cmd"
+
+// Seconds kept by the gpu-screen-recorder replay buffer
+const REPLAY_BUFFER_SECONDS = "60"
+
+// RecordingService starts a recording or a replay buffer with gpu-screen-recorder, or with OBS through obs-cmd.
+type RecordingService struct {
+	recorder *exec.Cmd
+	obsStop  []string
+}
+
+func (service *RecordingService) Name() string {
+	return "recording"
+}
+
+func (service *RecordingService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Recording.Enabled
+}
+
+func (service *RecordingService) Start(configuration *config.Configuration, paths config.Paths) error {
+	service.recorder = nil
+	service.obsStop = nil
+	recording := configuration.Recording
+
+	// Configurations written before recording existed leave it empty
+	if recording.Backend == "" {
+		recording.Backend = config.RECORDING_BACKEND_GPU_SCREEN_RECORDER
+	}
+
+	if recording.Mode == "" {
+		recording.Mode = config.RECORDING_MODE_REPLAY
+	}
+
+	if recording.Folder == "" {
+		recording.Folder = config.DefaultConfiguration().Recording.Folder
+	}
+
+	if recording.Mode != config.RECORDING_MODE_REPLAY && recording.Mode != config.RECORDING_MODE_RECORD {
+		return fmt.Errorf("Unknown recording.mode %s", recording.Mode)
+	}
+
+	switch recording.Backend {
+	case config.RECORDING_BACKEND_GPU_SCREEN_RECORDER:
+		return service.startGpuScreenRecorder(recording, configuration.Props["name"], paths)
+	case config.RECORDING_BACKEND_OBS:
+		return service.startObs(recording)
+	}
+
+	return fmt.Errorf("Unknown recording.backend %s", recording.Backend)
+}
+
+func (service *RecordingService) startGpuScreenRecorder(recording config.RecordingConfiguration, name string, paths config.Paths) error {
+	bin, exists := wrappers.CheckIfBinExists(GPU_SCREEN_RECORDER_BIN_NAME)
+
+	if !exists {
+		return fmt.Errorf("%s is not installed", GPU_SCREEN_RECORDER_BIN_NAME)
+	}
+
+	folder := config.ExpandUserPath(recording.Folder, paths.HomeDir)
+	config.MakeSureFoldersExist(folder)
+
+	args := []string{"-w", "screen", "-f", "60", "-c", "mp4"}
+
+	if recording.Mode == config.RECORDING_MODE_REPLAY {
+		// Replays are saved by sending SIGUSR1 to gpu-screen-recorder, usually from a hotkey
+		args = append(args, "-r", REPLAY_BUFFER_SECONDS, "-o", folder)
+	} else {
+		if name == "" {
+			name = config.APP_NAME
+		}
+
+		args = append(args, "-o", filepath.Join(folder, fmt.Sprintf("%s-%s.mp4", config.GameSlug(name), time.Now().Format("2006-01-02_15-04-05"))))
+	}
+
+	cmdHandle := exec.Command(bin, append(args, recording.Args...)...)
+
+	if _, err := system.Exec.Start(cmdHandle); err != nil {
+		return err
+	}
+
+	service.recorder = cmdHandle
+
+	return nil
+}
+
+func (service *RecordingService) startObs(recording config.RecordingConfiguration) error {
+	bin, exists := wrappers.CheckIfBinExists(OBS_CMD_BIN_NAME)
+
+	if !exists {
+		return fmt.Errorf("%s is not installed", OBS_CMD_BIN_NAME)
+	}
+
+	target := "recording"
+
+	if recording.Mode == config.RECORDING_MODE_REPLAY {
+		target = "replay"
+	}
+
+	if err := system.Exec.Run(exec.Command(bin, append(recording.Args, target, "start")...)); err != nil {
+		return err
+	}
+
+	service.obsStop = append([]string{bin}, append(recording.Args, target, "stop")...)
+
+	return nil
+}
+
+func (service *RecordingService) Stop() error {
+	if service.obsStop != nil {
+		return system.Exec.Run(exec.Command(service.obsStop[0], service.obsStop[1:]...))
+	}
+
+	if service.recorder == nil || service.recorder.Process == nil {
+		return nil
+	}
+
+	// gpu-screen-recorder finishes writing the file on SIGINT
+	if err := service.recorder.Process.Signal(os.Interrupt); err != nil {
+		return err
+	}
+
+	_, err := system.Exec.Wait(service.recorder)
+
+	return err
+}
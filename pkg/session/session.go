@@ -0,0 +1,102 @@
+// Package session runs the services living alongside the game, started right before
+// it and stopped right after it exits, in reverse order.
+package session
+
+import (
+	"log"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+)
+
+// Service is something running for the length of a game session.
+type Service interface {
+	// Name identifies the service in logs.
+	Name() string
+	// Enabled reports whether the configuration asks for the service.
+	Enabled(configuration *config.Configuration) bool
+	// Start runs right before the game, a failing service is skipped without stopping the launch.
+	Start(configuration *config.Configuration, paths config.Paths) error
+	// Stop runs right after the game exits, only for services that started.
+	Stop() error
+}
+
+// GameWatcher is a Service that follows the game process once it started.
+type GameWatcher interface {
+	Service
+	// GameStarted receives the pid of the game command.
+	GameStarted(pid int)
+	// RestartRequested reports, once stopped, whether the game must be launched again.
+	RestartRequested() bool
+}
+
+var registry = []Service{
+	&ModsService{},
+	&DllSwapService{},
+	&NightLightService{},
+	&TvService{},
+	&TdpService{},
+	&VrrService{},
+	&AudioProfileService{},
+	&AudioService{},
+	&RecordingService{},
+	&WatchdogService{},
+	&ControlService{},
+}
+
+// Register appends service to the services started for every session.
+func Register(service Service) {
+	registry = append(registry, service)
+}
+
+// Start starts every enabled service and returns the ones running.
+func Start(configuration *config.Configuration, paths config.Paths) []Service {
+	started := make([]Service, 0)
+
+	for _, service := range registry {
+		if !service.Enabled(configuration) {
+			continue
+		}
+
+		if err := service.Start(configuration, paths); err != nil {
+			log.Printf("Failed to start session service %s: %s\n", service.Name(), err)
+			continue
+		}
+
+		log.Printf("Started session service: %s\n", service.Name())
+		started = append(started, service)
+	}
+
+	return started
+}
+
+// Stop stops services, as returned by Start, last started first.
+func Stop(services []Service) {
+	for i := len(services) - 1; i >= 0; i-- {
+		if err := services[i].Stop(); err != nil {
+			log.Printf("Failed to stop session service %s: %s\n", services[i].Name(), err)
+			continue
+		}
+
+		log.Printf("Stopped session service: %s\n", services[i].Name())
+	}
+}
+
+// GameStarted hands pid to the started services following the game process.
+func GameStarted(services []Service, pid int) {
+	for _, service := range services {
+		if watcher, isWatcher := service.(GameWatcher); isWatcher {
+			watcher.GameStarted(pid)
+		}
+	}
+}
+
+// RestartRequested reports whether one of the stopped services asked to launch the game again.
+func RestartRequested(services []Service) bool {
+	for _, service := range services {
+		if watcher, isWatcher := service.(GameWatcher); isWatcher && watcher.RestartRequested() {
+			return true
+		}
+	}
+
+	return false
+}
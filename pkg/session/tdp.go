@@ -0,0 +1,142 @@
+package session
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/handheld"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const RYZENADJ_BIN_NAME = "ryzenadj"
+
+// ryzenadj --info rows and the flags setting them, in milliwatts
+var ryzenadjLimits = map[string]string{
+	"STAPM LIMIT":    "--stapm-limit",
+	"PPT LIMIT FAST": "--fast-limit",
+	"PPT LIMIT SLOW": "--slow-limit",
+}
+
+// "| STAPM LIMIT         |    15.000 | stapm-limit        |"
+var ryzenadjInfoRegex = regexp.MustCompile(`^\|\s*([A-Z ]+?)\s*\|\s*([0-9.]+)\s*\|`)
+
+// TdpService sets the power limit of the handheld APU with ryzenadj for the session and restores it afterwards.
+type TdpService struct {
+	ryzenadj string
+	restored map[string]int
+}
+
+func (service *TdpService) Name() string {
+	return "tdp"
+}
+
+func (service *TdpService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Handheld.AllowTdp && configuration.Handheld.Preset != ""
+}
+
+func (service *TdpService) Start(configuration *config.Configuration, paths config.Paths) error {
+	service.restored = nil
+	preset, found := handheld.FindPreset(configuration.Handheld.Preset)
+
+	if !found {
+		return fmt.Errorf("No handheld preset for %s", configuration.Handheld.Preset)
+	}
+
+	watts := configuration.Handheld.TdpWatts
+
+	if watts <= 0 {
+		watts = preset.TdpWatts
+	}
+
+	if watts <= 0 {
+		return fmt.Errorf("The %s has no default power limit, set handheld.tdp-watts", preset.Device)
+	}
+
+	bin, exists := wrappers.CheckIfBinExists(RYZENADJ_BIN_NAME)
+
+	if !exists {
+		return fmt.Errorf("%s is not installed", RYZENADJ_BIN_NAME)
+	}
+
+	service.ryzenadj = bin
+	current, err := readRyzenadjLimits(bin)
+
+	if err != nil {
+		return fmt.Errorf("Failed to read the current power limits: %s", err)
+	}
+
+	wanted := make(map[string]int)
+
+	for flag := range current {
+		wanted[flag] = watts * 1000
+	}
+
+	if err := setRyzenadjLimits(bin, wanted); err != nil {
+		return fmt.Errorf("Failed to set the power limit to %dW: %s", watts, err)
+	}
+
+	log.Printf("Power limit of the %s set to %dW\n", preset.Device, watts)
+	service.restored = current
+
+	return nil
+}
+
+func (service *TdpService) Stop() error {
+	if service.restored == nil {
+		return nil
+	}
+
+	return setRyzenadjLimits(service.ryzenadj, service.restored)
+}
+
+// Returns the current limits in milliwatts, by ryzenadj flag
+func readRyzenadjLimits(bin string) (map[string]int, error) {
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(bin, "--info"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]int)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+
+	for scanner.Scan() {
+		match := ryzenadjInfoRegex.FindStringSubmatch(scanner.Text())
+
+		if match == nil {
+			continue
+		}
+
+		flag, known := ryzenadjLimits[match[1]]
+		watts, parseErr := strconv.ParseFloat(match[2], 64)
+
+		if known && parseErr == nil {
+			limits[flag] = int(math.Round(watts * 1000))
+		}
+	}
+
+	if len(limits) == 0 {
+		return nil, errors.New("No power limit in the ryzenadj --info output")
+	}
+
+	return limits, nil
+}
+
+func setRyzenadjLimits(bin string, limits map[string]int) error {
+	args := make([]string, 0, len(limits))
+
+	for flag, milliwatts := range limits {
+		args = append(args, fmt.Sprintf("%s=%d", flag, milliwatts))
+	}
+
+	return system.Exec.Run(exec.Command(bin, args...))
+}
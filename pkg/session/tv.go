@@ -0,0 +1,425 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const UNCLUTTER_BIN_NAME = "unclutter"
+
+// Seconds before unclutter hides the idle cursor of games running outside gamescope
+const TV_UNCLUTTER_IDLE = "3"
+
+// An output of the desktop layout, mode being in the format of the backend
+type displayOutput struct {
+	name    string
+	enabled bool
+	mode    string
+	x       int
+	y       int
+	primary bool
+}
+
+// A tool able to read and change the whole desktop layout
+type layoutBackend interface {
+	name() string
+	// Returns the connected outputs
+	outputs() ([]displayOutput, error)
+	apply(outputs []displayOutput) error
+	// Returns the mode name the backend understands for mode
+	modeName(mode wrappers.DisplayMode) string
+}
+
+// TvService makes the TV the only active output for the session and puts the desktop layout back afterwards.
+type TvService struct {
+	backend   layoutBackend
+	restored  []displayOutput
+	unclutter *exec.Cmd
+}
+
+func (service *TvService) Name() string {
+	return "tv"
+}
+
+func (service *TvService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Tv.Enabled
+}
+
+func (service *TvService) Start(configuration *config.Configuration, paths config.Paths) error {
+	service.restored = nil
+	service.unclutter = nil
+
+	mode, err := wrappers.ParseDisplayMode(configuration.Tv.Mode)
+
+	if err != nil {
+		return err
+	}
+
+	service.backend = detectLayoutBackend()
+
+	if service.backend == nil {
+		return errors.New("No supported tool to change the display layout found (kscreen-doctor, wlr-randr or xrandr)")
+	}
+
+	current, err := service.backend.outputs()
+
+	if err != nil {
+		return fmt.Errorf("Failed to read the display layout with %s: %s", service.backend.name(), err)
+	}
+
+	tvOutput := findTvOutput(current, configuration.Tv.Output)
+
+	if tvOutput == "" {
+		return fmt.Errorf("No connected TV output found, set tv.output to one of: %s", outputNames(current))
+	}
+
+	layout := make([]displayOutput, 0, len(current))
+
+	for _, output := range current {
+		if output.name == tvOutput {
+			layout = append(layout, displayOutput{output.name, true, service.backend.modeName(mode), 0, 0, true})
+			continue
+		}
+
+		layout = append(layout, displayOutput{output.name, false, "", 0, 0, false})
+	}
+
+	if err := service.backend.apply(layout); err != nil {
+		return fmt.Errorf("Failed to switch to %s: %s", tvOutput, err)
+	}
+
+	log.Printf("Switched the display to %s in %dx%d@%d\n", tvOutput, mode.Width, mode.Height, mode.Refresh)
+	service.restored = current
+
+	// gamescope hides the cursor itself
+	if !configuration.Gamescope.Enabled {
+		if bin, exists := wrappers.CheckIfBinExists(UNCLUTTER_BIN_NAME); exists {
+			cmdHandle := exec.Command(bin, "-idle", TV_UNCLUTTER_IDLE)
+
+			if _, err := system.Exec.Start(cmdHandle); err == nil {
+				service.unclutter = cmdHandle
+			}
+		}
+	}
+
+	return nil
+}
+
+func (service *TvService) Stop() error {
+	if service.unclutter != nil && service.unclutter.Process != nil {
+		service.unclutter.Process.Signal(syscall.SIGTERM)
+		system.Exec.Wait(service.unclutter)
+	}
+
+	if service.restored == nil {
+		return nil
+	}
+
+	return service.backend.apply(service.restored)
+}
+
+func detectLayoutBackend() layoutBackend {
+	if strings.Contains(os.Getenv("XDG_CURRENT_DESKTOP"), "KDE") {
+		if bin, exists := wrappers.CheckIfBinExists(KSCREEN_DOCTOR_BIN_NAME); exists {
+			return &kdeLayout{bin}
+		}
+	}
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if bin, exists := wrappers.CheckIfBinExists(wrappers.WLR_RANDR_BIN_NAME); exists {
+			return &wlrLayout{bin}
+		}
+	}
+
+	if os.Getenv("DISPLAY") != "" {
+		if bin, exists := wrappers.CheckIfBinExists(wrappers.XRANDR_BIN_NAME); exists {
+			return &xrandrLayout{bin}
+		}
+	}
+
+	return nil
+}
+
+// The configured output, or the first HDMI one since that is what TVs are plugged in
+func findTvOutput(outputs []displayOutput, wanted string) string {
+	for _, output := range outputs {
+		if (wanted != "" && output.name == wanted) || (wanted == "" && strings.HasPrefix(output.name, "HDMI")) {
+			return output.name
+		}
+	}
+
+	return ""
+}
+
+func outputNames(outputs []displayOutput) string {
+	names := make([]string, 0, len(outputs))
+
+	for _, output := range outputs {
+		names = append(names, output.name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+type kdeLayout struct {
+	bin string
+}
+
+func (backend *kdeLayout) name() string {
+	return KSCREEN_DOCTOR_BIN_NAME
+}
+
+func (backend *kdeLayout) outputs() ([]displayOutput, error) {
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(backend.bin, "-j"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	screen := struct {
+		Outputs []struct {
+			Name          string `json:"name"`
+			Enabled       bool   `json:"enabled"`
+			Connected     bool   `json:"connected"`
+			CurrentModeId string `json:"currentModeId"`
+			Priority      int    `json:"priority"`
+			Pos           struct {
+				X int `json:"x"`
+				Y int `json:"y"`
+			} `json:"pos"`
+			Modes []struct {
+				Id   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"modes"`
+		} `json:"outputs"`
+	}{}
+
+	if err := json.Unmarshal(output, &screen); err != nil {
+		return nil, err
+	}
+
+	outputs := make([]displayOutput, 0, len(screen.Outputs))
+
+	for _, screenOutput := range screen.Outputs {
+		if !screenOutput.Connected {
+			continue
+		}
+
+		mode := ""
+
+		for _, screenMode := range screenOutput.Modes {
+			if screenMode.Id == screenOutput.CurrentModeId {
+				mode = screenMode.Name
+			}
+		}
+
+		outputs = append(outputs, displayOutput{screenOutput.Name, screenOutput.Enabled, mode, screenOutput.Pos.X, screenOutput.Pos.Y, screenOutput.Priority == 1})
+	}
+
+	return outputs, nil
+}
+
+func (backend *kdeLayout) apply(outputs []displayOutput) error {
+	args := make([]string, 0)
+
+	// Outputs are turned on first, KDE refuses to disable the last enabled one
+	for _, output := range outputs {
+		if !output.enabled {
+			continue
+		}
+
+		args = append(args, fmt.Sprintf("output.%s.enable", output.name), fmt.Sprintf("output.%s.position.%d,%d", output.name, output.x, output.y))
+
+		if output.mode != "" {
+			args = append(args, fmt.Sprintf("output.%s.mode.%s", output.name, output.mode))
+		}
+
+		if output.primary {
+			args = append(args, fmt.Sprintf("output.%s.priority.1", output.name))
+		}
+	}
+
+	for _, output := range outputs {
+		if !output.enabled {
+			args = append(args, fmt.Sprintf("output.%s.disable", output.name))
+		}
+	}
+
+	return system.Exec.Run(exec.Command(backend.bin, args...))
+}
+
+func (backend *kdeLayout) modeName(mode wrappers.DisplayMode) string {
+	return displayModeName(mode, "")
+}
+
+type wlrLayout struct {
+	bin string
+}
+
+func (backend *wlrLayout) name() string {
+	return wrappers.WLR_RANDR_BIN_NAME
+}
+
+func (backend *wlrLayout) outputs() ([]displayOutput, error) {
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(backend.bin, "--json"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	wlrOutputs := []struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+		Modes   []struct {
+			Width   int     `json:"width"`
+			Height  int     `json:"height"`
+			Refresh float64 `json:"refresh"`
+			Current bool    `json:"current"`
+		} `json:"modes"`
+		Position struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+		} `json:"position"`
+	}{}
+
+	if err := json.Unmarshal(output, &wlrOutputs); err != nil {
+		return nil, err
+	}
+
+	outputs := make([]displayOutput, 0, len(wlrOutputs))
+
+	for _, wlrOutput := range wlrOutputs {
+		mode := ""
+
+		for _, wlrMode := range wlrOutput.Modes {
+			if wlrMode.Current {
+				mode = fmt.Sprintf("%dx%d@%sHz", wlrMode.Width, wlrMode.Height, strconv.FormatFloat(wlrMode.Refresh, 'f', -1, 64))
+			}
+		}
+
+		outputs = append(outputs, displayOutput{wlrOutput.Name, wlrOutput.Enabled, mode, wlrOutput.Position.X, wlrOutput.Position.Y, false})
+	}
+
+	return outputs, nil
+}
+
+func (backend *wlrLayout) apply(outputs []displayOutput) error {
+	args := make([]string, 0)
+
+	for _, output := range outputs {
+		args = append(args, "--output", output.name)
+
+		if !output.enabled {
+			args = append(args, "--off")
+			continue
+		}
+
+		args = append(args, "--on", "--pos", fmt.Sprintf("%d,%d", output.x, output.y))
+
+		if output.mode != "" {
+			args = append(args, "--mode", output.mode)
+		}
+	}
+
+	return system.Exec.Run(exec.Command(backend.bin, args...))
+}
+
+func (backend *wlrLayout) modeName(mode wrappers.DisplayMode) string {
+	return displayModeName(mode, "Hz")
+}
+
+// "HDMI-1 connected primary 3840x2160+0+0 (normal left inverted right x axis y axis) 600mm x 340mm"
+var xrandrOutputRegex = regexp.MustCompile(`^(\S+) connected( primary)?(?: (\d+)x(\d+)\+(\d+)\+(\d+))?`)
+
+type xrandrLayout struct {
+	bin string
+}
+
+func (backend *xrandrLayout) name() string {
+	return wrappers.XRANDR_BIN_NAME
+}
+
+func (backend *xrandrLayout) outputs() ([]displayOutput, error) {
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(backend.bin, "--query"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]displayOutput, 0)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := xrandrOutputRegex.FindStringSubmatch(line); match != nil {
+			x, _ := strconv.Atoi(match[5])
+			y, _ := strconv.Atoi(match[6])
+			outputs = append(outputs, displayOutput{match[1], match[3] != "", "", x, y, match[2] != ""})
+			continue
+		}
+
+		// Modes are listed under their output, the current one marked with *
+		if modeMatch := xrandrCurrentModeLineRegex.FindStringSubmatch(line); modeMatch != nil && len(outputs) > 0 {
+			outputs[len(outputs)-1].mode = modeMatch[1] + "@" + modeMatch[2]
+		}
+	}
+
+	return outputs, nil
+}
+
+// "   3840x2160     60.00*+  30.00"
+var xrandrCurrentModeLineRegex = regexp.MustCompile(`^\s+(\d+x\d+)\S*\s.*?([0-9.]+)\*`)
+
+func (backend *xrandrLayout) apply(outputs []displayOutput) error {
+	args := make([]string, 0)
+
+	for _, output := range outputs {
+		args = append(args, "--output", output.name)
+
+		if !output.enabled {
+			args = append(args, "--off")
+			continue
+		}
+
+		if size, rate, found := strings.Cut(output.mode, "@"); found {
+			args = append(args, "--mode", size, "--rate", rate)
+		} else {
+			args = append(args, "--auto")
+		}
+
+		args = append(args, "--pos", fmt.Sprintf("%dx%d", output.x, output.y))
+
+		if output.primary {
+			args = append(args, "--primary")
+		}
+	}
+
+	return system.Exec.Run(exec.Command(backend.bin, args...))
+}
+
+func (backend *xrandrLayout) modeName(mode wrappers.DisplayMode) string {
+	return displayModeName(mode, "")
+}
+
+// WIDTHxHEIGHT@REFRESH, without the refresh rate when the mode leaves it to the output
+func displayModeName(mode wrappers.DisplayMode, refreshUnit string) string {
+	if mode.Refresh <= 0 {
+		return fmt.Sprintf("%dx%d", mode.Width, mode.Height)
+	}
+
+	return fmt.Sprintf("%dx%d@%d%s", mode.Width, mode.Height, mode.Refresh, refreshUnit)
+}
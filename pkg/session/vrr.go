@@ -0,0 +1,287 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const KSCREEN_DOCTOR_BIN_NAME = "kscreen-doctor"
+const SWAYMSG_BIN_NAME = "swaymsg"
+const HYPRCTL_BIN_NAME = "hyprctl"
+
+// Hyprland sets adaptive sync for every output at once, under this output name
+const HYPRLAND_VRR_OPTION = "misc:vrr"
+const VRR_ALL_OUTPUTS = "all"
+
+// A compositor able to change adaptive sync, states are the display.vrr values
+type vrrBackend interface {
+	name() string
+	// Returns the adaptive sync state of every active output, by output name
+	states() (map[string]string, error)
+	set(output string, state string) error
+	// Returns the state the compositor ends up in when asked for state
+	effective(state string) string
+}
+
+// VrrService sets adaptive sync on the desktop outputs for the session and restores their state afterwards.
+type VrrService struct {
+	backend  vrrBackend
+	restored map[string]string
+}
+
+func (service *VrrService) Name() string {
+	return "vrr"
+}
+
+func (service *VrrService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Display.Vrr != ""
+}
+
+func (service *VrrService) Start(configuration *config.Configuration, paths config.Paths) error {
+	service.restored = make(map[string]string)
+	wanted := configuration.Display.Vrr
+
+	if !slices.Contains([]string{config.DISPLAY_VRR_ON, config.DISPLAY_VRR_OFF, config.DISPLAY_VRR_GAME_ONLY}, wanted) {
+		return fmt.Errorf("Unknown display.vrr %s", wanted)
+	}
+
+	service.backend = detectVrrBackend()
+
+	if service.backend == nil {
+		return errors.New("No supported compositor found (KDE Plasma, sway or Hyprland)")
+	}
+
+	states, err := service.backend.states()
+
+	if err != nil {
+		return fmt.Errorf("Failed to read adaptive sync state with %s: %s", service.backend.name(), err)
+	}
+
+	if _, global := states[VRR_ALL_OUTPUTS]; !global && configuration.Display.Output != "" {
+		output := configuration.Display.Output
+		state, exists := states[output]
+
+		if !exists {
+			return fmt.Errorf("Output %s is not active", output)
+		}
+
+		states = map[string]string{output: state}
+	}
+
+	errs := make([]error, 0)
+
+	for output, state := range states {
+		if state == service.backend.effective(wanted) {
+			continue
+		}
+
+		if err := service.backend.set(output, wanted); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		log.Printf("Adaptive sync of %s set to %s, was %s\n", output, wanted, state)
+		service.restored[output] = state
+	}
+
+	return errors.Join(errs...)
+}
+
+func (service *VrrService) Stop() error {
+	errs := make([]error, 0)
+
+	for output, state := range service.restored {
+		errs = append(errs, service.backend.set(output, state))
+	}
+
+	return errors.Join(errs...)
+}
+
+func detectVrrBackend() vrrBackend {
+	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "" {
+		if bin, exists := wrappers.CheckIfBinExists(HYPRCTL_BIN_NAME); exists {
+			return &hyprlandVrr{bin}
+		}
+	}
+
+	if os.Getenv("SWAYSOCK") != "" {
+		if bin, exists := wrappers.CheckIfBinExists(SWAYMSG_BIN_NAME); exists {
+			return &swayVrr{bin}
+		}
+	}
+
+	if strings.Contains(os.Getenv("XDG_CURRENT_DESKTOP"), "KDE") {
+		if bin, exists := wrappers.CheckIfBinExists(KSCREEN_DOCTOR_BIN_NAME); exists {
+			return &kdeVrr{bin}
+		}
+	}
+
+	return nil
+}
+
+// KDE Plasma VRR policies: never, always and automatic, for fullscreen windows only
+var kdeVrrPolicies = []string{config.DISPLAY_VRR_OFF, config.DISPLAY_VRR_ON, config.DISPLAY_VRR_GAME_ONLY}
+var kdeVrrPolicyNames = []string{"never", "always", "automatic"}
+
+type kdeVrr struct {
+	bin string
+}
+
+func (backend *kdeVrr) name() string {
+	return KSCREEN_DOCTOR_BIN_NAME
+}
+
+func (backend *kdeVrr) states() (map[string]string, error) {
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(backend.bin, "-j"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	screen := struct {
+		Outputs []struct {
+			Name      string `json:"name"`
+			Enabled   bool   `json:"enabled"`
+			VrrPolicy int    `json:"vrrPolicy"`
+		} `json:"outputs"`
+	}{}
+
+	if err := json.Unmarshal(output, &screen); err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]string)
+
+	for _, screenOutput := range screen.Outputs {
+		if screenOutput.Enabled && screenOutput.VrrPolicy >= 0 && screenOutput.VrrPolicy < len(kdeVrrPolicies) {
+			states[screenOutput.Name] = kdeVrrPolicies[screenOutput.VrrPolicy]
+		}
+	}
+
+	return states, nil
+}
+
+func (backend *kdeVrr) set(output string, state string) error {
+	policy := kdeVrrPolicyNames[slices.Index(kdeVrrPolicies, state)]
+
+	return system.Exec.Run(exec.Command(backend.bin, fmt.Sprintf("output.%s.vrrpolicy.%s", output, policy)))
+}
+
+func (backend *kdeVrr) effective(state string) string {
+	return state
+}
+
+// sway has no fullscreen only mode, game-only is on for the length of the session
+type swayVrr struct {
+	bin string
+}
+
+func (backend *swayVrr) name() string {
+	return SWAYMSG_BIN_NAME
+}
+
+func (backend *swayVrr) states() (map[string]string, error) {
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(backend.bin, "-t", "get_outputs", "-r"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	swayOutputs := []struct {
+		Name               string `json:"name"`
+		Active             bool   `json:"active"`
+		AdaptiveSyncStatus string `json:"adaptive_sync_status"`
+	}{}
+
+	if err := json.Unmarshal(output, &swayOutputs); err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]string)
+
+	for _, swayOutput := range swayOutputs {
+		if !swayOutput.Active {
+			continue
+		}
+
+		states[swayOutput.Name] = config.DISPLAY_VRR_OFF
+
+		if swayOutput.AdaptiveSyncStatus == "enabled" {
+			states[swayOutput.Name] = config.DISPLAY_VRR_ON
+		}
+	}
+
+	return states, nil
+}
+
+func (backend *swayVrr) set(output string, state string) error {
+	adaptiveSync := "on"
+
+	if state == config.DISPLAY_VRR_OFF {
+		adaptiveSync = "off"
+	}
+
+	return system.Exec.Run(exec.Command(backend.bin, "output", output, "adaptive_sync", adaptiveSync))
+}
+
+func (backend *swayVrr) effective(state string) string {
+	if state == config.DISPLAY_VRR_GAME_ONLY {
+		return config.DISPLAY_VRR_ON
+	}
+
+	return state
+}
+
+// Hyprland misc:vrr values: 0 off, 1 on and 2 fullscreen only
+var hyprlandVrrValues = []string{config.DISPLAY_VRR_OFF, config.DISPLAY_VRR_ON, config.DISPLAY_VRR_GAME_ONLY}
+
+type hyprlandVrr struct {
+	bin string
+}
+
+func (backend *hyprlandVrr) name() string {
+	return HYPRCTL_BIN_NAME
+}
+
+func (backend *hyprlandVrr) states() (map[string]string, error) {
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(backend.bin, "-j", "getoption", HYPRLAND_VRR_OPTION))
+
+	if err != nil {
+		return nil, err
+	}
+
+	option := struct {
+		Int int `json:"int"`
+	}{}
+
+	if err := json.Unmarshal(output, &option); err != nil {
+		return nil, err
+	}
+
+	if option.Int < 0 || option.Int >= len(hyprlandVrrValues) {
+		return nil, fmt.Errorf("Unknown %s value %d", HYPRLAND_VRR_OPTION, option.Int)
+	}
+
+	return map[string]string{VRR_ALL_OUTPUTS: hyprlandVrrValues[option.Int]}, nil
+}
+
+func (backend *hyprlandVrr) set(output string, state string) error {
+	value := strconv.Itoa(slices.Index(hyprlandVrrValues, state))
+
+	return system.Exec.Run(exec.Command(backend.bin, "keyword", HYPRLAND_VRR_OPTION, value))
+}
+
+func (backend *hyprlandVrr) effective(state string) string {
+	return state
+}
@@ -0,0 +1,202 @@
+package session
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+	"github.com/fpetros1/linux-game-launcher/pkg/wrappers"
+)
+
+const XDOTOOL_BIN_NAME = "xdotool"
+const NOTIFY_SEND_BIN_NAME = "notify-send"
+
+const WATCHDOG_MAX_CHECK_INTERVAL = 30 * time.Second
+
+// WatchdogService acts on sessions where the game is alive but none of its processes shows a window.
+type WatchdogService struct {
+	timeout time.Duration
+	action  string
+	game    string
+	stop    chan struct{}
+	done    sync.WaitGroup
+	restart bool
+	lock    sync.Mutex
+}
+
+func (service *WatchdogService) Name() string {
+	return "watchdog"
+}
+
+func (service *WatchdogService) Enabled(configuration *config.Configuration) bool {
+	return configuration.Watchdog.Timeout != ""
+}
+
+func (service *WatchdogService) Start(configuration *config.Configuration, paths config.Paths) error {
+	timeout, err := time.ParseDuration(configuration.Watchdog.Timeout)
+
+	if err != nil || timeout <= 0 {
+		return fmt.Errorf("Invalid watchdog.timeout %s, expected a duration like 5m", configuration.Watchdog.Timeout)
+	}
+
+	action := configuration.Watchdog.Action
+
+	if action == "" {
+		action = config.WATCHDOG_ACTION_NOTIFY
+	}
+
+	if action != config.WATCHDOG_ACTION_NOTIFY && action != config.WATCHDOG_ACTION_KILL && action != config.WATCHDOG_ACTION_RESTART {
+		return fmt.Errorf("Unknown watchdog.action %s", action)
+	}
+
+	if _, exists := wrappers.CheckIfBinExists(XDOTOOL_BIN_NAME); !exists {
+		return fmt.Errorf("%s is needed to look for game windows", XDOTOOL_BIN_NAME)
+	}
+
+	service.timeout = timeout
+	service.action = action
+	service.game = configuration.Props["name"]
+	service.stop = make(chan struct{})
+	service.restart = false
+
+	return nil
+}
+
+func (service *WatchdogService) GameStarted(pid int) {
+	if pid <= 0 {
+		return
+	}
+
+	service.done.Add(1)
+
+	go func() {
+		defer service.done.Done()
+		service.watch(pid)
+	}()
+}
+
+func (service *WatchdogService) Stop() error {
+	close(service.stop)
+	service.done.Wait()
+
+	return nil
+}
+
+func (service *WatchdogService) RestartRequested() bool {
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	return service.restart
+}
+
+func (service *WatchdogService) watch(pid int) {
+	ticker := time.NewTicker(min(service.timeout/4, WATCHDOG_MAX_CHECK_INTERVAL))
+	defer ticker.Stop()
+
+	lastWindowSeen := time.Now()
+
+	for {
+		select {
+		case <-service.stop:
+			return
+		case <-ticker.C:
+		}
+
+		processes := ProcessTree(pid)
+
+		if hasWindow(processes) {
+			lastWindowSeen = time.Now()
+			continue
+		}
+
+		if time.Since(lastWindowSeen) < service.timeout {
+			continue
+		}
+
+		log.Printf("Watchdog: no game window for %s, action: %s\n", service.timeout, service.action)
+
+		switch service.action {
+		case config.WATCHDOG_ACTION_NOTIFY:
+			Notify(fmt.Sprintf("%s has shown no window for %s", service.game, service.timeout))
+			lastWindowSeen = time.Now()
+		case config.WATCHDOG_ACTION_RESTART:
+			service.lock.Lock()
+			service.restart = true
+			service.lock.Unlock()
+			killProcesses(processes)
+			return
+		case config.WATCHDOG_ACTION_KILL:
+			killProcesses(processes)
+			return
+		}
+	}
+}
+
+// ProcessTree returns pid and all of its descendants, read from /proc.
+func ProcessTree(pid int) []int {
+	children := make(map[int][]int)
+	stats, _ := filepath.Glob("/proc/[0-9]*/stat")
+
+	for _, stat := range stats {
+		content, err := os.ReadFile(stat)
+
+		if err != nil {
+			continue
+		}
+
+		// The command name may hold spaces and parentheses, the fields that follow it do not
+		fields := strings.Fields(string(content[strings.LastIndexByte(string(content), ')')+1:]))
+
+		if len(fields) < 2 {
+			continue
+		}
+
+		childPid, _ := strconv.Atoi(filepath.Base(filepath.Dir(stat)))
+		parentPid, _ := strconv.Atoi(fields[1])
+		children[parentPid] = append(children[parentPid], childPid)
+	}
+
+	tree := []int{pid}
+
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i]]...)
+	}
+
+	return tree
+}
+
+func hasWindow(processes []int) bool {
+	bin, _ := wrappers.CheckIfBinExists(XDOTOOL_BIN_NAME)
+
+	for _, pid := range processes {
+		output, err := system.OutputBounded(system.TIMEOUT_PROBE, exec.Command(bin, "search", "--onlyvisible", "--pid", strconv.Itoa(pid)))
+
+		if err == nil && strings.TrimSpace(string(output)) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func killProcesses(processes []int) {
+	for _, pid := range processes {
+		syscall.Kill(pid, syscall.SIGKILL)
+	}
+}
+
+// Notify shows message as a desktop notification, when notify-send is installed.
+func Notify(message string) {
+	if bin, exists := wrappers.CheckIfBinExists(NOTIFY_SEND_BIN_NAME); exists {
+		system.Exec.Run(exec.Command(bin, config.APP_NAME, message))
+	}
+}
@@ -0,0 +1,68 @@
+package steam
+
+import (
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AnticheatGame is a game whose anticheat rejects gamescope or LD_PRELOAD overlays (mangohud).
+type AnticheatGame struct {
+	Id        string `yaml:"id"`
+	Name      string `yaml:"name"`
+	Anticheat string `yaml:"anticheat"`
+}
+
+// Known offenders, anticheat.yaml files can add entries or replace them by id
+var bundledAnticheatGames = []AnticheatGame{
+	{"1172470", "Apex Legends", "EasyAntiCheat"},
+	{"252490", "Rust", "EasyAntiCheat"},
+	{"1517290", "Battlefield 2042", "EasyAntiCheat"},
+	{"578080", "PUBG: BATTLEGROUNDS", "BattlEye"},
+	{"359550", "Tom Clancy's Rainbow Six Siege", "BattlEye"},
+	{"1085660", "Destiny 2", "BattlEye"},
+}
+
+// FindAnticheatGame looks appid up in the bundled list, then in listFiles, later files taking precedence.
+func FindAnticheatGame(appid string, listFiles ...string) (AnticheatGame, bool) {
+	found := AnticheatGame{}
+	exists := false
+
+	if appid == "" {
+		return found, exists
+	}
+
+	for _, game := range bundledAnticheatGames {
+		if game.Id == appid {
+			found, exists = game, true
+		}
+	}
+
+	for _, listFile := range listFiles {
+		for _, game := range readAnticheatGames(listFile) {
+			if game.Id == appid {
+				found, exists = game, true
+			}
+		}
+	}
+
+	return found, exists
+}
+
+func readAnticheatGames(listFile string) []AnticheatGame {
+	content, err := os.ReadFile(listFile)
+
+	if err != nil {
+		return nil
+	}
+
+	games := make([]AnticheatGame, 0)
+
+	if err := yaml.Unmarshal(content, &games); err != nil {
+		log.Printf("Ignoring invalid anticheat list %s: %s\n", listFile, err)
+		return nil
+	}
+
+	return games
+}
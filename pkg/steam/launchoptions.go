@@ -0,0 +1,252 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const COMMAND_PLACEHOLDER = "%command%"
+const LAUNCH_OPTIONS_KEY = "LaunchOptions"
+
+// plauncher, by name or path and with its flags, right before %command%
+var launcherOptionRegex = regexp.MustCompile(`(^|\s)(?:\S*/)?` + config.APP_NAME + `(?:\s+-\S*)*\s+` + COMMAND_PLACEHOLDER)
+
+// LaunchOptionsChange is the launch options of an app, for one Steam user, before and after an edit.
+type LaunchOptionsChange struct {
+	User   string `json:"user"`
+	Appid  string `json:"appid"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// SetLaunchOptions adds plauncher in front of %command% in the launch options of appids, or of every installed
+// app with all, for every Steam user of homeDir; remove takes it out instead. Unless dryRun, the localconfig.vdf
+// files are backed up then rewritten, which needs Steam closed as it overwrites them on exit.
+func SetLaunchOptions(homeDir string, appids []string, all bool, remove bool, dryRun bool) ([]LaunchOptionsChange, error) {
+	if !dryRun {
+		if running, err := steamRunning(); err != nil {
+			return nil, err
+		} else if running {
+			return nil, errors.New("Steam is running, close it first or it will overwrite the launch options on exit")
+		}
+	}
+
+	if all {
+		for _, app := range InstalledApps(homeDir) {
+			appids = append(appids, app.Id)
+		}
+	}
+
+	changes := make([]LaunchOptionsChange, 0)
+	localConfigs := localConfigFiles(homeDir)
+
+	if len(localConfigs) == 0 {
+		return changes, errors.New("No Steam user found, Steam has to be started once first")
+	}
+
+	for _, localConfig := range localConfigs {
+		user := filepath.Base(filepath.Dir(filepath.Dir(localConfig)))
+		content, err := os.ReadFile(localConfig)
+
+		if err != nil {
+			return changes, err
+		}
+
+		root, err := parseVdf(string(content))
+
+		if err != nil {
+			return changes, fmt.Errorf("%s: %w", localConfig, err)
+		}
+
+		apps := root.childBlock("UserLocalConfigStore").childBlock("Software").childBlock("Valve").childBlock("Steam").childBlock("apps")
+		fileChanged := false
+
+		for _, appid := range slices.Compact(slices.Sorted(slices.Values(appids))) {
+			change := editLaunchOptions(apps, appid, remove)
+
+			if change.Before == change.After {
+				continue
+			}
+
+			change.User = user
+			changes = append(changes, change)
+			fileChanged = true
+		}
+
+		if !fileChanged || dryRun {
+			continue
+		}
+
+		backupFile := fmt.Sprintf("%s.%s-%s.bak", localConfig, config.APP_NAME, time.Now().Format("20060102-150405"))
+
+		if err := system.FS.WriteFile(backupFile, content, config.DEFAULT_PERMISSION); err != nil {
+			return changes, fmt.Errorf("Failed to back up %s, left untouched: %w", localConfig, err)
+		}
+
+		if err := system.FS.WriteFile(localConfig, []byte(formatVdf(root)), config.DEFAULT_PERMISSION); err != nil {
+			return changes, fmt.Errorf("Failed to write %s, restore it from %s: %w", localConfig, backupFile, err)
+		}
+	}
+
+	return changes, nil
+}
+
+// ReadLaunchOptions returns the launch options set in Steam, by appid then by Steam user, of every user of homeDir.
+func ReadLaunchOptions(homeDir string) (map[string]map[string]string, error) {
+	launchOptions := make(map[string]map[string]string)
+
+	for _, localConfig := range localConfigFiles(homeDir) {
+		user := filepath.Base(filepath.Dir(filepath.Dir(localConfig)))
+		content, err := os.ReadFile(localConfig)
+
+		if err != nil {
+			return launchOptions, err
+		}
+
+		root, err := parseVdf(string(content))
+
+		if err != nil {
+			return launchOptions, fmt.Errorf("%s: %w", localConfig, err)
+		}
+
+		apps := root.childBlock("UserLocalConfigStore").childBlock("Software").childBlock("Valve").childBlock("Steam").childBlock("apps")
+
+		for _, app := range apps.Children {
+			if !app.Block {
+				continue
+			}
+
+			if launchOptions[app.Key] == nil {
+				launchOptions[app.Key] = make(map[string]string)
+			}
+
+			if options := app.child(LAUNCH_OPTIONS_KEY); options != nil {
+				launchOptions[app.Key][user] = vdfUnescape(options.Value)
+			} else {
+				launchOptions[app.Key][user] = ""
+			}
+		}
+	}
+
+	return launchOptions, nil
+}
+
+// HasLauncher reports whether the launch options run the game through plauncher.
+func HasLauncher(options string) bool {
+	return launcherOptionRegex.MatchString(options)
+}
+
+// Users reads the Steam users of homeDir, the ones launch options are kept for.
+func Users(homeDir string) []string {
+	users := make([]string, 0)
+
+	for _, localConfig := range localConfigFiles(homeDir) {
+		users = append(users, filepath.Base(filepath.Dir(filepath.Dir(localConfig))))
+	}
+
+	return users
+}
+
+func editLaunchOptions(apps *vdfNode, appid string, remove bool) LaunchOptionsChange {
+	change := LaunchOptionsChange{Appid: appid}
+	app := apps.child(appid)
+
+	if app != nil && app.Block {
+		if options := app.child(LAUNCH_OPTIONS_KEY); options != nil {
+			change.Before = vdfUnescape(options.Value)
+		}
+	}
+
+	if remove {
+		change.After = withoutLauncher(change.Before)
+	} else {
+		change.After = withLauncher(change.Before)
+	}
+
+	if change.After == change.Before {
+		return change
+	}
+
+	app = apps.childBlock(appid)
+
+	if change.After == "" {
+		app.removeChild(LAUNCH_OPTIONS_KEY)
+		return change
+	}
+
+	if options := app.child(LAUNCH_OPTIONS_KEY); options != nil {
+		options.Value = vdfEscape(change.After)
+	} else {
+		app.Children = append(app.Children, &vdfNode{Key: LAUNCH_OPTIONS_KEY, Value: vdfEscape(change.After)})
+	}
+
+	return change
+}
+
+// Environment variables before %command% and game arguments after it are kept, plain arguments go after it
+func withLauncher(options string) string {
+	if HasLauncher(options) {
+		return options
+	}
+
+	if !strings.Contains(options, COMMAND_PLACEHOLDER) {
+		return strings.TrimSpace(config.APP_NAME + " " + COMMAND_PLACEHOLDER + " " + options)
+	}
+
+	return strings.Replace(options, COMMAND_PLACEHOLDER, config.APP_NAME+" "+COMMAND_PLACEHOLDER, 1)
+}
+
+func withoutLauncher(options string) string {
+	options = strings.TrimSpace(launcherOptionRegex.ReplaceAllString(options, "${1}"+COMMAND_PLACEHOLDER))
+
+	if options == COMMAND_PLACEHOLDER {
+		return ""
+	}
+
+	return options
+}
+
+// Every user of every Steam installation has a localconfig.vdf, installations linked to one another count once
+func localConfigFiles(homeDir string) []string {
+	files := make([]string, 0)
+
+	for _, steamRoot := range steamRoots {
+		matches, _ := filepath.Glob(filepath.Join(homeDir, steamRoot, "userdata", "*", "config", "localconfig.vdf"))
+
+		for _, match := range matches {
+			if resolved, err := filepath.EvalSymlinks(match); err == nil && !slices.Contains(files, resolved) {
+				files = append(files, resolved)
+			}
+		}
+	}
+
+	return files
+}
+
+func steamRunning() (bool, error) {
+	pgrep, exists := system.Exec.LookPath("pgrep")
+
+	if !exists {
+		return false, errors.New("pgrep is needed to make sure Steam is closed")
+	}
+
+	err := system.Exec.Run(exec.Command(pgrep, "-x", "steam"))
+
+	var exitErr *exec.ExitError
+
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -0,0 +1,123 @@
+package steam
+
+import (
+	"strings"
+	"testing"
+)
+
+// localconfig.vdf as Steam writes it, with nested blocks, escaped quotes and an app already launched by plauncher
+const LOCAL_CONFIG_FIXTURE = "\"UserLocalConfigStore\"\n" +
+	"{\n" +
+	"\t\"Software\"\n" +
+	"\t{\n" +
+	"\t\t\"Valve\"\n" +
+	"\t\t{\n" +
+	"\t\t\t\"Steam\"\n" +
+	"\t\t\t{\n" +
+	"\t\t\t\t\"apps\"\n" +
+	"\t\t\t\t{\n" +
+	"\t\t\t\t\t\"1245620\"\n" +
+	"\t\t\t\t\t{\n" +
+	"\t\t\t\t\t\t\"LastPlayed\"\t\t\"1700000000\"\n" +
+	"\t\t\t\t\t\t\"LaunchOptions\"\t\t\"DXVK_HUD=fps %command% -skipintro\"\n" +
+	"\t\t\t\t\t\t\"cloud\"\n" +
+	"\t\t\t\t\t\t{\n" +
+	"\t\t\t\t\t\t\t\"last_sync_state\"\t\t\"synchronized\"\n" +
+	"\t\t\t\t\t\t}\n" +
+	"\t\t\t\t\t}\n" +
+	"\t\t\t\t\t\"570\"\n" +
+	"\t\t\t\t\t{\n" +
+	"\t\t\t\t\t\t\"LaunchOptions\"\t\t\"plauncher %command%\"\n" +
+	"\t\t\t\t\t}\n" +
+	"\t\t\t\t\t\"730\"\n" +
+	"\t\t\t\t\t{\n" +
+	"\t\t\t\t\t\t\"LaunchOptions\"\t\t\"%command% -console +name \\\"my name\\\"\"\n" +
+	"\t\t\t\t\t\t\"playtime\"\t\t\"42\"\n" +
+	"\t\t\t\t\t}\n" +
+	"\t\t\t\t}\n" +
+	"\t\t\t\t\"ShaderCacheManager\"\n" +
+	"\t\t\t\t{\n" +
+	"\t\t\t\t\t\"HasCurrentBucket\"\t\t\"1\"\n" +
+	"\t\t\t\t}\n" +
+	"\t\t\t}\n" +
+	"\t\t}\n" +
+	"\t}\n" +
+	"\t\"friends\"\n" +
+	"\t{\n" +
+	"\t\t\"PersonaName\"\t\t\"\\\"Quoted\\\" C:\\\\Games\"\n" +
+	"\t}\n" +
+	"}\n"
+
+func TestLocalConfigRoundTrip(t *testing.T) {
+	root, err := parseVdf(LOCAL_CONFIG_FIXTURE)
+
+	if err != nil {
+		t.Fatalf("parseVdf: %s", err)
+	}
+
+	if formatted := formatVdf(root); formatted != LOCAL_CONFIG_FIXTURE {
+		t.Errorf("rewritten without an edit:\n%s\nexpected:\n%s", formatted, LOCAL_CONFIG_FIXTURE)
+	}
+}
+
+func TestEditLaunchOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		appid  string
+		remove bool
+		// The edit expected in the file, as the text it replaces once, nothing else may change
+		before string
+		after  string
+	}{
+		{
+			"options around %command% are kept", "1245620", false,
+			"\"DXVK_HUD=fps %command% -skipintro\"",
+			"\"DXVK_HUD=fps plauncher %command% -skipintro\"",
+		},
+		{
+			"escaped quotes", "730", false,
+			`"%command% -console +name \"my name\""`,
+			`"plauncher %command% -console +name \"my name\""`,
+		},
+		{"already set", "570", false, "", ""},
+		{"not set to remove", "1245620", true, "", ""},
+		{
+			"removal of the only option", "570", true,
+			"\t\t\t\t\t\t\"LaunchOptions\"\t\t\"plauncher %command%\"\n",
+			"",
+		},
+		{
+			"app without a block", "440", false,
+			"\t\t\t\t}\n\t\t\t\t\"ShaderCacheManager\"",
+			"\t\t\t\t\t\"440\"\n\t\t\t\t\t{\n\t\t\t\t\t\t\"LaunchOptions\"\t\t\"plauncher %command%\"\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t\t\"ShaderCacheManager\"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := parseVdf(LOCAL_CONFIG_FIXTURE)
+
+			if err != nil {
+				t.Fatalf("parseVdf: %s", err)
+			}
+
+			apps := root.childBlock("UserLocalConfigStore").childBlock("Software").childBlock("Valve").childBlock("Steam").childBlock("apps")
+			change := editLaunchOptions(apps, test.appid, test.remove)
+			expected := LOCAL_CONFIG_FIXTURE
+
+			if test.before != "" {
+				if strings.Count(expected, test.before) != 1 {
+					t.Fatalf("%q is not in the fixture once", test.before)
+				}
+
+				expected = strings.Replace(expected, test.before, test.after, 1)
+			} else if change.Before != change.After {
+				t.Errorf("launch options changed from %q to %q, expected no change", change.Before, change.After)
+			}
+
+			if formatted := formatVdf(root); formatted != expected {
+				t.Errorf("rewritten as:\n%s\nexpected:\n%s", formatted, expected)
+			}
+		})
+	}
+}
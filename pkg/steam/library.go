@@ -0,0 +1,157 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// Folders a Steam installation lives in, native and Flatpak
+var steamRoots = []string{
+	".steam/steam",
+	".local/share/Steam",
+	".var/app/com.valvesoftware.Steam/.local/share/Steam",
+}
+
+// Ids of the non-Steam games added as shortcuts start here
+const NON_STEAM_APPID_MIN = 1 << 31
+
+// Matches the "key" "value" lines of Steam VDF files, nesting is not needed for the keys read here
+var vdfKeyValueRegex = regexp.MustCompile(`(?m)^\s*"([^"]+)"\s+"((?:[^"\\]|\\.)*)"`)
+
+// InstalledApp is a Steam app found in one of the library folders, read from its app manifest.
+type InstalledApp struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	InstallDir string `json:"install-dir"`
+	Library    string `json:"library"`
+}
+
+// InstalledApps lists the apps installed in every Steam library of the Steam installations found in homeDir.
+func InstalledApps(homeDir string) []InstalledApp {
+	apps := make([]InstalledApp, 0)
+	seen := make([]string, 0)
+
+	for _, library := range libraryFolders(homeDir) {
+		manifests, _ := filepath.Glob(filepath.Join(library, "steamapps", "appmanifest_*.acf"))
+
+		for _, manifest := range manifests {
+			app, err := readAppManifest(manifest)
+
+			if err != nil || app.Id == "" || slices.Contains(seen, app.Id) {
+				continue
+			}
+
+			app.Library = library
+			seen = append(seen, app.Id)
+			apps = append(apps, app)
+		}
+	}
+
+	return apps
+}
+
+// CompatDataAppInstalled reports whether the app of compatData, a steamapps/compatdata/APPID folder, is installed,
+// and whether that could be checked: the library of compatData has to be listed and present, a library on a disk
+// that is not mounted can not tell.
+func CompatDataAppInstalled(homeDir string, compatData string) (bool, bool) {
+	appId := filepath.Base(compatData)
+	library := filepath.Dir(filepath.Dir(filepath.Dir(compatData)))
+
+	if resolved, err := filepath.EvalSymlinks(library); err == nil {
+		library = resolved
+	}
+
+	if !slices.Contains(libraryFolders(homeDir), library) {
+		return false, false
+	}
+
+	return slices.ContainsFunc(InstalledApps(homeDir), func(app InstalledApp) bool { return app.Id == appId }), true
+}
+
+// Returns the Steam roots found in homeDir and the libraries listed in their libraryfolders.vdf
+func libraryFolders(homeDir string) []string {
+	libraries := make([]string, 0)
+
+	addLibrary := func(library string) {
+		if resolved, err := filepath.EvalSymlinks(library); err == nil {
+			library = resolved
+		}
+
+		if info, err := os.Stat(filepath.Join(library, "steamapps")); err == nil && info.IsDir() && !slices.Contains(libraries, library) {
+			libraries = append(libraries, library)
+		}
+	}
+
+	for _, steamRoot := range steamRoots {
+		root := filepath.Join(homeDir, steamRoot)
+		addLibrary(root)
+
+		content, err := os.ReadFile(filepath.Join(root, "steamapps", "libraryfolders.vdf"))
+
+		if err != nil {
+			continue
+		}
+
+		for _, match := range vdfKeyValueRegex.FindAllStringSubmatch(string(content), -1) {
+			if match[1] == "path" {
+				addLibrary(match[2])
+			}
+		}
+	}
+
+	return libraries
+}
+
+func readAppManifest(manifest string) (InstalledApp, error) {
+	app := InstalledApp{}
+	content, err := os.ReadFile(manifest)
+
+	if err != nil {
+		return app, err
+	}
+
+	// The AppState keys come first, nested blocks reuse some of their names
+	for _, match := range vdfKeyValueRegex.FindAllStringSubmatch(string(content), -1) {
+		switch {
+		case match[1] == "appid" && app.Id == "":
+			app.Id = match[2]
+		case match[1] == "name" && app.Name == "":
+			app.Name = match[2]
+		case match[1] == "installdir" && app.InstallDir == "":
+			app.InstallDir = match[2]
+		}
+	}
+
+	return app, nil
+}
+
+// OrphanedShaderCaches returns the shader cache folders, in every Steam library of homeDir, of apps no longer installed.
+func OrphanedShaderCaches(homeDir string) []string {
+	installed := make([]string, 0)
+
+	for _, app := range InstalledApps(homeDir) {
+		installed = append(installed, app.Id)
+	}
+
+	orphaned := make([]string, 0)
+
+	for _, library := range libraryFolders(homeDir) {
+		entries, _ := os.ReadDir(filepath.Join(library, "steamapps", "shadercache"))
+
+		for _, entry := range entries {
+			// Non-Steam shortcuts have no app manifest, their ids have the high bit set
+			if appid, err := strconv.ParseUint(entry.Name(), 10, 64); err != nil || appid >= NON_STEAM_APPID_MIN {
+				continue
+			}
+
+			if entry.IsDir() && !slices.Contains(installed, entry.Name()) {
+				orphaned = append(orphaned, filepath.Join(library, "steamapps", "shadercache", entry.Name()))
+			}
+		}
+	}
+
+	return orphaned
+}
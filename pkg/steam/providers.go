@@ -0,0 +1,173 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// MetadataProvider resolves Steam game metadata from a service, providers not supporting languages ignore it.
+type MetadataProvider interface {
+	// Name is the metadata.resolvers entry selecting the provider.
+	Name() string
+	// GameName returns the name of appid in language, a Steam language name (e.g. english).
+	GameName(appid string, language string) (string, error)
+}
+
+var providers = []MetadataProvider{
+	&StoreProvider{},
+	&SteamSpyProvider{},
+}
+
+// RegisterMetadataProvider makes provider selectable in metadata.resolvers, replacing a provider with the same name.
+func RegisterMetadataProvider(provider MetadataProvider) {
+	for i, registered := range providers {
+		if registered.Name() == provider.Name() {
+			providers[i] = provider
+			return
+		}
+	}
+
+	providers = append(providers, provider)
+}
+
+// Providers configured by URL in metadata.providers take precedence over registered ones with the same name
+func findMetadataProvider(name string, configured []config.MetadataProviderConfiguration) (MetadataProvider, bool) {
+	for _, providerConfiguration := range configured {
+		if providerConfiguration.Name == name {
+			return &URLTemplateProvider{providerConfiguration}, true
+		}
+	}
+
+	for _, provider := range providers {
+		if provider.Name() == name {
+			return provider, true
+		}
+	}
+
+	return nil, false
+}
+
+type BasicSteamSpyResponse struct {
+	AppId int    `json:"appid"`
+	Name  string `json:"name"`
+}
+
+// StoreAppDetailsResponse is the part of a Steam Store api/appdetails entry plauncher reads.
+type StoreAppDetailsResponse struct {
+	Success bool `json:"success"`
+	Data    struct {
+		Name string `json:"name"`
+	} `json:"data"`
+}
+
+// StoreProvider reads names from the Steam Store api/appdetails, in the requested language.
+type StoreProvider struct{}
+
+func (provider *StoreProvider) Name() string {
+	return config.METADATA_RESOLVER_STORE
+}
+
+func (provider *StoreProvider) GameName(appid string, language string) (string, error) {
+	query := url.Values{"appids": {appid}, "l": {language}, "filters": {"basic"}}
+	storeResponse := make(map[string]StoreAppDetailsResponse)
+
+	if err := fetchJson("https://store.steampowered.com/api/appdetails?"+query.Encode(), &storeResponse); err != nil {
+		return "", err
+	}
+
+	appDetails, exists := storeResponse[appid]
+
+	if !exists || !appDetails.Success || appDetails.Data.Name == "" {
+		return "", fmt.Errorf("the store does not know appid %s", appid)
+	}
+
+	return appDetails.Data.Name, nil
+}
+
+// SteamSpyProvider reads English names from SteamSpy.
+type SteamSpyProvider struct{}
+
+func (provider *SteamSpyProvider) Name() string {
+	return config.METADATA_RESOLVER_STEAMSPY
+}
+
+func (provider *SteamSpyProvider) GameName(appid string, language string) (string, error) {
+	steamSpyResponse := BasicSteamSpyResponse{}
+
+	if err := fetchJson(fmt.Sprintf("https://steamspy.com/api.php?request=appdetails&appid=%s", url.QueryEscape(appid)), &steamSpyResponse); err != nil {
+		return "", err
+	}
+
+	if steamSpyResponse.Name == "" {
+		return "", fmt.Errorf("steamspy does not know appid %s", appid)
+	}
+
+	return steamSpyResponse.Name, nil
+}
+
+// URLTemplateProvider reads names from a user configured service, e.g. a mirror of the Steam Store API.
+// {appid} and {language} are replaced in the URL and the name path. Without a name path the
+// response body is the name, otherwise the path is a dotted path to the name in a JSON response.
+type URLTemplateProvider struct {
+	Configuration config.MetadataProviderConfiguration
+}
+
+func (provider *URLTemplateProvider) Name() string {
+	return provider.Configuration.Name
+}
+
+func (provider *URLTemplateProvider) GameName(appid string, language string) (string, error) {
+	expand := strings.NewReplacer("{appid}", url.PathEscape(appid), "{language}", url.PathEscape(language))
+	body, err := system.HttpGet(expand.Replace(provider.Configuration.Url))
+
+	if err != nil {
+		return "", err
+	}
+
+	if provider.Configuration.NamePath == "" {
+		if name := strings.TrimSpace(string(body)); name != "" {
+			return name, nil
+		}
+
+		return "", fmt.Errorf("%s does not know appid %s", provider.Name(), appid)
+	}
+
+	var document any
+
+	if err := json.Unmarshal(body, &document); err != nil {
+		return "", fmt.Errorf("%s response is not valid JSON: %s", provider.Name(), err)
+	}
+
+	namePath := strings.NewReplacer("{appid}", appid, "{language}", language).Replace(provider.Configuration.NamePath)
+
+	for _, part := range strings.Split(namePath, ".") {
+		object, isObject := document.(map[string]any)
+
+		if !isObject {
+			return "", fmt.Errorf("%s response has no %s", provider.Name(), namePath)
+		}
+
+		document = object[part]
+	}
+
+	if name, isString := document.(string); isString && name != "" {
+		return name, nil
+	}
+
+	return "", fmt.Errorf("%s response has no %s", provider.Name(), namePath)
+}
+
+func fetchJson(url string, response any) error {
+	body, err := system.HttpGet(url)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, response)
+}
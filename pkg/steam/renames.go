@@ -0,0 +1,75 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+)
+
+// Rename is a game Steam now calls NewName while plauncher cached it, and named its files, as OldName.
+type Rename struct {
+	Appid   string `json:"appid"`
+	OldName string `json:"old-name"`
+	NewName string `json:"new-name"`
+}
+
+// FindRename compares the cached name of appid with the one in its app manifest, which Steam keeps current.
+// Only English names are compared, the manifests have no other language.
+func FindRename(appid string, homeDir string, cacheFolder string, metadata config.MetadataConfiguration) (Rename, bool) {
+	if metadataLanguage(metadata) != config.DEFAULT_METADATA_LANGUAGE {
+		return Rename{}, false
+	}
+
+	for _, app := range InstalledApps(homeDir) {
+		if app.Id == appid {
+			return compareCachedName(app.Id, app.Name, cacheFolder, metadata)
+		}
+	}
+
+	return Rename{}, false
+}
+
+// FindRenames looks for renamed games among the installed ones, from their app manifests, or with refetch from
+// the configured resolvers, which also covers names in other languages.
+func FindRenames(homeDir string, cacheFolder string, metadata config.MetadataConfiguration, refetch bool) []Rename {
+	renames := make([]Rename, 0)
+
+	for _, app := range InstalledApps(homeDir) {
+		currentName := app.Name
+
+		if refetch {
+			name, err := fetchGameName(app.Id, metadata)
+
+			if err != nil {
+				continue
+			}
+
+			currentName = name
+		} else if metadataLanguage(metadata) != config.DEFAULT_METADATA_LANGUAGE {
+			continue
+		}
+
+		if rename, renamed := compareCachedName(app.Id, currentName, cacheFolder, metadata); renamed {
+			renames = append(renames, rename)
+		}
+	}
+
+	return renames
+}
+
+// CacheRename records the new name of a renamed game in the name cache.
+func CacheRename(rename Rename, cacheFolder string, metadata config.MetadataConfiguration) error {
+	return cacheGameName(filepath.Join(NameCacheFolder(cacheFolder, metadata), rename.Appid), rename.NewName)
+}
+
+func compareCachedName(appid string, currentName string, cacheFolder string, metadata config.MetadataConfiguration) (Rename, bool) {
+	cachedName, err := os.ReadFile(filepath.Join(NameCacheFolder(cacheFolder, metadata), appid))
+
+	// Names only differing in spelling, e.g. an HTML entity or a ™, share their files
+	if err != nil || currentName == "" || config.GameSlug(string(cachedName)) == config.GameSlug(currentName) {
+		return Rename{}, false
+	}
+
+	return Rename{appid, string(cachedName), config.NormalizeGameName(currentName)}, true
+}
@@ -0,0 +1,183 @@
+// Package steam identifies the Steam game being launched from the command Steam hands over.
+package steam
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const STEAMAPPID_FILENAME = "steam_appid.txt"
+const COMMON_STEAM_APP_NAME = "Common"
+
+var gameExeRegex = regexp.MustCompile("waitforexitandrun\\ (\\/.+(\\.exe|\\.bat))")
+var steamAppidRegex = regexp.MustCompile("AppId=([0-9]+)")
+
+// EnrichSteamAppIdByExe reads the appid from the steam_appid.txt next to the game executable.
+func EnrichSteamAppIdByExe(configuration *config.Configuration, nonFlagsArgsString string) {
+	if _, exists := configuration.Props["steam-appid"]; !exists {
+		gameExeMatchResult := gameExeRegex.FindStringSubmatch(nonFlagsArgsString)
+
+		if gameExeMatchResult != nil {
+			gameExec := gameExeMatchResult[1]
+			gameFolder := filepath.Dir(gameExec)
+			appIdFile := filepath.Join(gameFolder, STEAMAPPID_FILENAME)
+
+			if _, err := os.Stat(appIdFile); !os.IsNotExist(err) {
+				file, err := os.Open(appIdFile)
+				if err != nil {
+					system.Warnf(system.EXIT_FAILURE, "Failed to open file: %s\n", err)
+					return
+				}
+				defer file.Close()
+
+				scanner := bufio.NewScanner(file)
+				scanner.Split(bufio.ScanLines)
+
+				for scanner.Scan() {
+
+					configuration.Props["steam-appid"] = scanner.Text()
+					configuration.Props["id"] = scanner.Text()
+				}
+			}
+		}
+	}
+}
+
+// EnrichSteamAppIdByArgs reads the appid from the AppId= argument Steam passes to its reaper.
+func EnrichSteamAppIdByArgs(configuration *config.Configuration, nonFlagsArgsString string) {
+	if _, exists := configuration.Props["steam-appid"]; !exists {
+		steamAppidRegexResult := steamAppidRegex.FindStringSubmatch(nonFlagsArgsString)
+
+		if steamAppidRegexResult != nil {
+			configuration.Props["steam-appid"] = steamAppidRegexResult[1]
+			configuration.Props["id"] = steamAppidRegexResult[1]
+		}
+	}
+}
+
+// EnrichGameName resolves the game name from the detected appid.
+func EnrichGameName(configuration *config.Configuration, cacheFolder string) {
+	if _, exists := configuration.Props["steam-appid"]; exists {
+		if name := FindSteamGameName(configuration.Props["steam-appid"], cacheFolder, configuration.Metadata); name != "" {
+			configuration.Props["name"] = name
+		}
+	}
+}
+
+// FindSteamGameName returns the name of appid, from cacheFolder or the first configured resolver that knows it.
+// It is empty when no resolver knows appid, a name that could not be cached is still returned.
+func FindSteamGameName(appid string, cacheFolder string, metadata config.MetadataConfiguration) string {
+	name, err := ResolveGameName(appid, cacheFolder, metadata)
+
+	if err != nil {
+		system.Warnf(system.EXIT_FAILURE, "%s\n", err)
+	}
+
+	return name
+}
+
+// ResolveGameName is FindSteamGameName returning an error when no resolver knows appid.
+func ResolveGameName(appid string, cacheFolder string, metadata config.MetadataConfiguration) (string, error) {
+	cacheFile := filepath.Join(NameCacheFolder(cacheFolder, metadata), appid)
+
+	if appName, err := os.ReadFile(cacheFile); !os.IsNotExist(err) {
+		log.Printf("Fetching game name from cache file: %s\n", cacheFile)
+		return string(appName), nil
+	}
+
+	name, err := fetchGameName(appid, metadata)
+
+	if err != nil {
+		return "", err
+	}
+
+	return name, cacheGameName(cacheFile, name)
+}
+
+// Asks the configured resolvers for the name of appid, in order, leaving the cache alone
+func fetchGameName(appid string, metadata config.MetadataConfiguration) (string, error) {
+	language := metadataLanguage(metadata)
+	resolvers := metadata.Resolvers
+
+	if len(resolvers) == 0 {
+		resolvers = config.DefaultConfiguration().Metadata.Resolvers
+	}
+
+	for _, resolver := range resolvers {
+		provider, exists := findMetadataProvider(resolver, metadata.Providers)
+
+		if !exists {
+			log.Printf("Ignoring unknown metadata resolver: %s\n", resolver)
+			continue
+		}
+
+		log.Printf("Fetching the game name of %s from %s\n", appid, resolver)
+
+		name, err := provider.GameName(appid, language)
+
+		if err != nil {
+			log.Printf("Could not fetch steam game name from %s: %s\n", resolver, err)
+			continue
+		}
+
+		return config.NormalizeGameName(name), nil
+	}
+
+	return "", fmt.Errorf("Could not fetch steam game name of %s from any resolver", appid)
+}
+
+// CacheInstalledAppName fills the name cache for app, from its app manifest when names are wanted in English.
+// It reports whether the name had to be fetched.
+func CacheInstalledAppName(app InstalledApp, cacheFolder string, metadata config.MetadataConfiguration) (string, bool, error) {
+	cacheFile := filepath.Join(NameCacheFolder(cacheFolder, metadata), app.Id)
+
+	if appName, err := os.ReadFile(cacheFile); err == nil {
+		return string(appName), false, nil
+	}
+
+	if app.Name != "" && metadataLanguage(metadata) == config.DEFAULT_METADATA_LANGUAGE {
+		name := config.NormalizeGameName(app.Name)
+		return name, false, cacheGameName(cacheFile, name)
+	}
+
+	name, err := ResolveGameName(app.Id, cacheFolder, metadata)
+
+	return name, true, err
+}
+
+func cacheGameName(cacheFile string, name string) error {
+	log.Printf("Saving game name(%s) in cache file: %s\n", name, cacheFile)
+
+	system.FS.MkdirAll(filepath.Dir(cacheFile), config.DEFAULT_PERMISSION)
+
+	if err := system.FS.WriteFile(cacheFile, []byte(name), config.DEFAULT_PERMISSION); err != nil {
+		return fmt.Errorf("Could not write cache file: %s", err)
+	}
+
+	return nil
+}
+
+func metadataLanguage(metadata config.MetadataConfiguration) string {
+	if metadata.Language == "" {
+		return config.DEFAULT_METADATA_LANGUAGE
+	}
+
+	return metadata.Language
+}
+
+// NameCacheFolder returns the folder of cacheFolder holding the names in the configured language. English names
+// are kept at its root, where earlier versions put them, other languages in a folder each.
+func NameCacheFolder(cacheFolder string, metadata config.MetadataConfiguration) string {
+	if language := metadataLanguage(metadata); language != config.DEFAULT_METADATA_LANGUAGE {
+		return filepath.Join(cacheFolder, language)
+	}
+
+	return cacheFolder
+}
@@ -0,0 +1,177 @@
+package steam
+
+import (
+	"fmt"
+	"strings"
+)
+
+// vdfNode is a "key" "value" pair of a Steam VDF file, or a "key" { ... } block when Block is set.
+// Values are kept escaped as in the file, so untouched nodes are written back as they were read.
+type vdfNode struct {
+	Key      string
+	Value    string
+	Block    bool
+	Children []*vdfNode
+}
+
+// Keys are compared case-insensitively, Steam writes both "apps" and "Apps" depending on its version
+func (node *vdfNode) child(key string) *vdfNode {
+	for _, child := range node.Children {
+		if strings.EqualFold(child.Key, key) {
+			return child
+		}
+	}
+
+	return nil
+}
+
+func (node *vdfNode) childBlock(key string) *vdfNode {
+	if child := node.child(key); child != nil && child.Block {
+		return child
+	}
+
+	child := &vdfNode{Key: key, Block: true}
+	node.Children = append(node.Children, child)
+
+	return child
+}
+
+func (node *vdfNode) removeChild(key string) {
+	for i, child := range node.Children {
+		if strings.EqualFold(child.Key, key) {
+			node.Children = append(node.Children[:i], node.Children[i+1:]...)
+			return
+		}
+	}
+}
+
+type vdfToken struct {
+	text   string
+	quoted bool
+}
+
+// Parses the text VDF format of localconfig.vdf into a root block holding its top level nodes
+func parseVdf(content string) (*vdfNode, error) {
+	tokens, err := vdfTokens(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	root := &vdfNode{Block: true}
+	stack := []*vdfNode{root}
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		current := stack[len(stack)-1]
+
+		switch {
+		case token.text == "}" && !token.quoted:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("Unexpected } in VDF")
+			}
+
+			stack = stack[:len(stack)-1]
+		case token.text == "{" && !token.quoted:
+			return nil, fmt.Errorf("Unexpected { in VDF")
+		case i+1 < len(tokens) && tokens[i+1].text == "{" && !tokens[i+1].quoted:
+			block := &vdfNode{Key: token.text, Block: true}
+			current.Children = append(current.Children, block)
+			stack = append(stack, block)
+			i++
+		case i+1 < len(tokens):
+			current.Children = append(current.Children, &vdfNode{Key: token.text, Value: tokens[i+1].text})
+			i++
+
+			// Platform conditions, e.g. [$WIN32], are dropped, localconfig.vdf does not use them
+			if i+1 < len(tokens) && !tokens[i+1].quoted && strings.HasPrefix(tokens[i+1].text, "[") {
+				i++
+			}
+		default:
+			return nil, fmt.Errorf("Key %s has no value in VDF", token.text)
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("Unclosed block %s in VDF", stack[len(stack)-1].Key)
+	}
+
+	return root, nil
+}
+
+func vdfTokens(content string) ([]vdfToken, error) {
+	tokens := make([]vdfToken, 0)
+
+	for i := 0; i < len(content); i++ {
+		char := content[i]
+
+		switch {
+		case char == ' ' || char == '\t' || char == '\r' || char == '\n':
+		case char == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case char == '{' || char == '}':
+			tokens = append(tokens, vdfToken{string(char), false})
+		case char == '"':
+			end := i + 1
+
+			for end < len(content) && content[end] != '"' {
+				if content[end] == '\\' {
+					end++
+				}
+
+				end++
+			}
+
+			if end >= len(content) {
+				return nil, fmt.Errorf("Unterminated string in VDF")
+			}
+
+			tokens = append(tokens, vdfToken{content[i+1 : end], true})
+			i = end
+		default:
+			end := i
+
+			for end < len(content) && !strings.ContainsRune(" \t\r\n{}\"", rune(content[end])) {
+				end++
+			}
+
+			tokens = append(tokens, vdfToken{content[i:end], false})
+			i = end - 1
+		}
+	}
+
+	return tokens, nil
+}
+
+// Writes the nodes of root the way Steam does, tab indented with the values tab separated from their keys
+func formatVdf(root *vdfNode) string {
+	var builder strings.Builder
+	writeVdfNodes(&builder, root.Children, 0)
+
+	return builder.String()
+}
+
+func writeVdfNodes(builder *strings.Builder, nodes []*vdfNode, depth int) {
+	indent := strings.Repeat("\t", depth)
+
+	for _, node := range nodes {
+		if !node.Block {
+			fmt.Fprintf(builder, "%s\"%s\"\t\t\"%s\"\n", indent, node.Key, node.Value)
+			continue
+		}
+
+		fmt.Fprintf(builder, "%s\"%s\"\n%s{\n", indent, node.Key, indent)
+		writeVdfNodes(builder, node.Children, depth+1)
+		fmt.Fprintf(builder, "%s}\n", indent)
+	}
+}
+
+func vdfEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
+
+func vdfUnescape(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(value)
+}
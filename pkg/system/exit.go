@@ -0,0 +1,64 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Exit codes of plauncher, so the Steam wrappers and scripts around it can branch on what went wrong.
+// Anything plauncher does not classify exits with EXIT_FAILURE, like log.Fatal does.
+const EXIT_OK = 0
+const EXIT_FAILURE = 1
+const EXIT_CONFIG_ERROR = 2
+const EXIT_MISSING_DEPENDENCY = 3
+const EXIT_PREFIX_ERROR = 4
+const EXIT_GAME_START_FAILED = 5
+const EXIT_GAME_CRASHED = 6
+
+// OnFatal, when set, is given the Fatalf messages to show them where the user looks, e.g. on a TV.
+var OnFatal func(message string)
+
+// Strict turns the Warnf failures into Fatalf ones, set from the strict key of the configuration.
+var Strict = false
+
+// Fatalf logs the message and exits with code.
+func Fatalf(code int, format string, args ...any) {
+	log.Printf(format, args...)
+
+	if OnFatal != nil {
+		OnFatal(strings.TrimSpace(fmt.Sprintf(format, args...)))
+	}
+
+	os.Exit(code)
+}
+
+// Warnf reports a failure the launch can go on without, on the terminal and in the log, or exits with code
+// like Fatalf in strict mode.
+func Warnf(code int, format string, args ...any) {
+	if Strict {
+		Fatalf(code, format, args...)
+	}
+
+	log.Printf("WARNING: "+format, args...)
+	fmt.Fprintf(os.Stderr, "plauncher: warning: %s\n", strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+// GameExitCode maps the error of a game run to EXIT_OK, EXIT_GAME_CRASHED when the game exited with an error
+// or was killed, or EXIT_GAME_START_FAILED when it could not run at all.
+func GameExitCode(err error) int {
+	if err == nil {
+		return EXIT_OK
+	}
+
+	var exitErr *exec.ExitError
+
+	if errors.As(err, &exitErr) {
+		return EXIT_GAME_CRASHED
+	}
+
+	return EXIT_GAME_START_FAILED
+}
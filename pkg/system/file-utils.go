@@ -0,0 +1,329 @@
+package system
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+func CopyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if e := out.Close(); e != nil {
+			err = e
+		}
+	}()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return
+	}
+
+	err = out.Sync()
+	if err != nil {
+		return
+	}
+
+	si, err := os.Stat(src)
+	if err != nil {
+		return
+	}
+	err = os.Chmod(dst, si.Mode())
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func CopyDir(src string, dst string) (err error) {
+	src = filepath.Clean(src)
+	dst = filepath.Clean(dst)
+
+	si, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !si.IsDir() {
+		return fmt.Errorf("source is not a directory")
+	}
+
+	_, err = os.Stat(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	if err == nil {
+		return fmt.Errorf("destination already exists")
+	}
+
+	err = os.MkdirAll(dst, si.Mode())
+	if err != nil {
+		return
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+
+		if entry.IsDir() {
+			err = CopyDir(srcPath, dstPath)
+			if err != nil {
+				return
+			}
+		} else {
+			info, _ := entry.Info()
+			// Symlinks are copied as symlinks, a Wine prefix relies on them (dosdevices).
+			if info.Mode()&os.ModeSymlink != 0 {
+				err = copySymlink(srcPath, dstPath)
+				if err != nil {
+					return
+				}
+				continue
+			}
+
+			err = CopyFile(srcPath, dstPath)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+func copySymlink(src string, dst string) error {
+	target, err := os.Readlink(src)
+
+	if err != nil {
+		return err
+	}
+
+	return os.Symlink(target, dst)
+}
+
+// CompareDirs reports the first difference between the trees src and dst: an entry missing from dst, of another
+// type, a file of another size or a symlink to another target. Entries only in dst are not reported.
+func CompareDirs(src string, dst string) error {
+	src = filepath.Clean(src)
+	dst = filepath.Clean(dst)
+
+	return filepath.WalkDir(src, func(srcPath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(src, srcPath)
+
+		if err != nil {
+			return err
+		}
+
+		dstPath := filepath.Join(dst, relativePath)
+		srcInfo, err := entry.Info()
+
+		if err != nil {
+			return err
+		}
+
+		dstInfo, err := os.Lstat(dstPath)
+
+		if err != nil {
+			return err
+		}
+
+		if srcInfo.Mode().Type() != dstInfo.Mode().Type() {
+			return fmt.Errorf("%s is a %s, %s a %s", srcPath, srcInfo.Mode().Type(), dstPath, dstInfo.Mode().Type())
+		}
+
+		if srcInfo.Mode().IsRegular() && srcInfo.Size() != dstInfo.Size() {
+			return fmt.Errorf("%s has %d bytes, %s %d", srcPath, srcInfo.Size(), dstPath, dstInfo.Size())
+		}
+
+		if srcInfo.Mode()&os.ModeSymlink != 0 {
+			srcTarget, _ := os.Readlink(srcPath)
+			dstTarget, _ := os.Readlink(dstPath)
+
+			if srcTarget != dstTarget {
+				return fmt.Errorf("%s points at %s, %s at %s", srcPath, srcTarget, dstPath, dstTarget)
+			}
+		}
+
+		return nil
+	})
+}
+
+// SyncDir makes dst a copy of src, creating dst when missing. Files already in dst with the same size and
+// modification time are skipped, so an interrupted SyncDir can be run again to resume. Symlinks are copied as
+// symlinks and what src no longer has is deleted from dst.
+func SyncDir(src string, dst string) error {
+	src = filepath.Clean(src)
+	dst = filepath.Clean(dst)
+
+	err := filepath.WalkDir(src, func(srcPath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(src, srcPath)
+
+		if err != nil {
+			return err
+		}
+
+		dstPath := filepath.Join(dst, relativePath)
+		srcInfo, err := entry.Info()
+
+		if err != nil {
+			return err
+		}
+
+		dstInfo, err := os.Lstat(dstPath)
+
+		// An entry that changed type is replaced, a file is never written through a symlink
+		if err == nil && dstInfo.Mode().Type() != srcInfo.Mode().Type() {
+			if err := os.RemoveAll(dstPath); err != nil {
+				return err
+			}
+
+			dstInfo = nil
+		}
+
+		if srcInfo.Mode()&os.ModeSymlink != 0 {
+			return syncSymlink(srcPath, dstPath, dstInfo != nil)
+		}
+
+		if entry.IsDir() {
+			return os.MkdirAll(dstPath, srcInfo.Mode().Perm())
+		}
+
+		if dstInfo != nil && dstInfo.Size() == srcInfo.Size() && dstInfo.ModTime().Equal(srcInfo.ModTime()) {
+			return nil
+		}
+
+		if err := CopyFile(srcPath, dstPath); err != nil {
+			return err
+		}
+
+		return os.Chtimes(dstPath, srcInfo.ModTime(), srcInfo.ModTime())
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return pruneDir(src, dst)
+}
+
+// Points the symlink dstPath at the target of srcPath, leaving it alone when it already does
+func syncSymlink(srcPath string, dstPath string, exists bool) error {
+	target, err := os.Readlink(srcPath)
+
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		if current, err := os.Readlink(dstPath); err == nil && current == target {
+			return nil
+		}
+
+		if err := os.Remove(dstPath); err != nil {
+			return err
+		}
+	}
+
+	return os.Symlink(target, dstPath)
+}
+
+// Deletes the entries of dst src does not have
+func pruneDir(src string, dst string) error {
+	return filepath.WalkDir(dst, func(dstPath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(dst, dstPath)
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := os.Lstat(filepath.Join(src, relativePath)); !os.IsNotExist(err) {
+			return nil
+		}
+
+		if err := os.RemoveAll(dstPath); err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+}
+
+const READ_LAST_LINE_CHUNK_SIZE = 16 * 1024
+
+// ReadLastLine returns the last line of filename without its line terminator (LF or CRLF).
+// The file is read backwards in chunks, an empty file has an empty last line.
+func ReadLastLine(filename string) (string, error) {
+	fileHandle, err := os.Open(filename)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer fileHandle.Close()
+
+	fileStat, err := fileHandle.Stat()
+
+	if err != nil {
+		return "", err
+	}
+
+	pos := fileStat.Size()
+	buffer := make([]byte, 0)
+
+	for pos > 0 {
+		chunkSize := min(int64(READ_LAST_LINE_CHUNK_SIZE), pos)
+		pos -= chunkSize
+
+		chunk := make([]byte, chunkSize)
+
+		if _, err := fileHandle.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return "", err
+		}
+
+		buffer = append(chunk, buffer...)
+		content := trimLineTerminator(buffer)
+
+		if index := bytes.LastIndexByte(content, '\n'); index >= 0 {
+			return string(trimLineTerminator(content[index+1:])), nil
+		}
+	}
+
+	return string(trimLineTerminator(buffer)), nil
+}
+
+func trimLineTerminator(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
@@ -0,0 +1,131 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyDirAndCompareDirs(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "src")
+	dst := filepath.Join(base, "dst")
+
+	os.MkdirAll(filepath.Join(src, "folder"), 0o755)
+	os.WriteFile(filepath.Join(src, "folder", "file"), []byte("content"), 0o644)
+	os.Symlink("folder/file", filepath.Join(src, "link"))
+	os.Symlink("missing", filepath.Join(src, "dangling"))
+
+	if err := CopyDir(src, dst); err != nil {
+		t.Fatalf("CopyDir: %s", err)
+	}
+
+	if err := CompareDirs(src, dst); err != nil {
+		t.Fatalf("copy differs: %s", err)
+	}
+
+	if target, err := os.Readlink(filepath.Join(dst, "dangling")); err != nil || target != "missing" {
+		t.Errorf("dangling link copied as %q (%v)", target, err)
+	}
+
+	os.WriteFile(filepath.Join(dst, "folder", "file"), []byte("changed content"), 0o644)
+
+	if err := CompareDirs(src, dst); err == nil {
+		t.Error("a file of another size was not reported")
+	}
+
+	os.Remove(filepath.Join(dst, "folder", "file"))
+
+	if err := CompareDirs(src, dst); err == nil {
+		t.Error("a missing file was not reported")
+	}
+}
+
+func TestSyncDirMirrors(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "src")
+	dst := filepath.Join(base, "dst")
+
+	os.MkdirAll(filepath.Join(src, "pfx", "dosdevices"), 0o755)
+	os.WriteFile(filepath.Join(src, "pfx", "user.reg"), []byte("registry"), 0o644)
+	os.WriteFile(filepath.Join(src, "removed"), []byte("gone soon"), 0o644)
+	os.MkdirAll(filepath.Join(src, "removed-folder"), 0o755)
+	os.Symlink("../drive_c", filepath.Join(src, "pfx", "dosdevices", "c:"))
+	os.Symlink("/", filepath.Join(src, "pfx", "dosdevices", "z:"))
+
+	if err := SyncDir(src, dst); err != nil {
+		t.Fatalf("first SyncDir: %s", err)
+	}
+
+	os.Remove(filepath.Join(src, "removed"))
+	os.Remove(filepath.Join(src, "removed-folder"))
+	os.Remove(filepath.Join(src, "pfx", "dosdevices", "z:"))
+	os.Symlink("/home", filepath.Join(src, "pfx", "dosdevices", "z:"))
+	os.Remove(filepath.Join(src, "pfx", "dosdevices", "c:"))
+	os.WriteFile(filepath.Join(src, "pfx", "dosdevices", "c:"), []byte("now a file"), 0o644)
+
+	if err := SyncDir(src, dst); err != nil {
+		t.Fatalf("second SyncDir: %s", err)
+	}
+
+	if err := CompareDirs(src, dst); err != nil {
+		t.Fatalf("mirror differs: %s", err)
+	}
+
+	for _, removed := range []string{"removed", "removed-folder"} {
+		if _, err := os.Lstat(filepath.Join(dst, removed)); !os.IsNotExist(err) {
+			t.Errorf("%s was not deleted from the mirror: %v", removed, err)
+		}
+	}
+
+	if target, err := os.Readlink(filepath.Join(dst, "pfx", "dosdevices", "z:")); err != nil || target != "/home" {
+		t.Errorf("z: points at %q (%v), expected /home", target, err)
+	}
+}
+
+func TestReadLastLine(t *testing.T) {
+	longLine := strings.Repeat("x", READ_LAST_LINE_CHUNK_SIZE)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"empty file", "", ""},
+		{"single line", "only line\n", "only line"},
+		{"no trailing newline", "first\nlast", "last"},
+		{"trailing newline", "first\nlast\n", "last"},
+		{"CRLF", "first\r\nlast\r\n", "last"},
+		{"CRLF without trailing terminator", "first\r\nlast", "last"},
+		{"empty last line", "first\n\n", ""},
+		{"last line spanning the chunk boundary", "first\n" + longLine + "tail\n", longLine + "tail"},
+		{"newline right at the chunk boundary", strings.Repeat("y", 10) + "\n" + strings.Repeat("z", READ_LAST_LINE_CHUNK_SIZE-1) + "\n", strings.Repeat("z", READ_LAST_LINE_CHUNK_SIZE-1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "log")
+
+			if err := os.WriteFile(file, []byte(test.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			line, err := ReadLastLine(file)
+
+			if err != nil {
+				t.Fatalf("ReadLastLine: %s", err)
+			}
+
+			if line != test.expected {
+				t.Errorf("got %d bytes %.20q, expected %d bytes %.20q", len(line), line, len(test.expected), test.expected)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := ReadLastLine(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+			t.Errorf("got %v, expected a not exist error", err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// OsExecutor runs processes for real.
+type OsExecutor struct{}
+
+func (executor *OsExecutor) LookPath(binName string) (string, bool) {
+	ctx := context.Background()
+
+	if timeout := Timeouts[TIMEOUT_PROBE]; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "which", binName)
+
+	stdout, err := cmd.Output()
+
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.Split(string(stdout), "\n")[0]), true
+}
+
+func (executor *OsExecutor) Run(cmd *exec.Cmd) error {
+	return cmd.Run()
+}
+
+func (executor *OsExecutor) Output(cmd *exec.Cmd) ([]byte, error) {
+	return cmd.Output()
+}
+
+func (executor *OsExecutor) Start(cmd *exec.Cmd) (int, error) {
+	if err := cmd.Start(); err != nil {
+		return -1, err
+	}
+
+	return cmd.Process.Pid, nil
+}
+
+func (executor *OsExecutor) Wait(cmd *exec.Cmd) (int, error) {
+	err := cmd.Wait()
+
+	if cmd.ProcessState == nil {
+		return -1, err
+	}
+
+	return cmd.ProcessState.ExitCode(), err
+}
+
+// OsFileSystem changes the real filesystem.
+type OsFileSystem struct{}
+
+func (fileSystem *OsFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (fileSystem *OsFileSystem) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+func (fileSystem *OsFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (fileSystem *OsFileSystem) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (fileSystem *OsFileSystem) Rename(oldpath string, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (fileSystem *OsFileSystem) Symlink(oldname string, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
+func (fileSystem *OsFileSystem) Link(oldname string, newname string) error {
+	return os.Link(oldname, newname)
+}
+
+func (fileSystem *OsFileSystem) Chmod(name string, mode os.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+func (fileSystem *OsFileSystem) CopyDir(src string, dst string) error {
+	return CopyDir(src, dst)
+}
+
+// cp shares the file extents on filesystems supporting it (btrfs, XFS) and copies them elsewhere, symlinks included
+func (fileSystem *OsFileSystem) CloneDir(src string, dst string) error {
+	cp, err := exec.LookPath("cp")
+
+	if err != nil {
+		return CopyDir(src, dst)
+	}
+
+	if output, err := exec.Command(cp, "-a", "--reflink=auto", "--", src, dst).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+func (fileSystem *OsFileSystem) SyncDir(src string, dst string) error {
+	return SyncDir(src, dst)
+}
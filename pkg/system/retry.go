@@ -0,0 +1,93 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// External commands and network calls fail transiently, a locked prefix or a DNS hiccup, they get this many
+// attempts with a delay doubling from RETRY_INITIAL_DELAY in between.
+const RETRY_ATTEMPTS = 3
+const RETRY_INITIAL_DELAY = 500 * time.Millisecond
+
+type permanentError struct {
+	err error
+}
+
+func (err *permanentError) Error() string {
+	return err.err.Error()
+}
+
+func (err *permanentError) Unwrap() error {
+	return err.err
+}
+
+// Permanent marks err as not worth retrying, Retry returns it right away.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err}
+}
+
+// Retry runs attempt until it succeeds, returns a Permanent error or RETRY_ATTEMPTS are used up, returning the last error.
+func Retry(description string, attempt func() error) error {
+	delay := RETRY_INITIAL_DELAY
+	var err error
+
+	for try := 1; try <= RETRY_ATTEMPTS; try++ {
+		if err = attempt(); err == nil {
+			return nil
+		}
+
+		var permanent *permanentError
+
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+
+		if try < RETRY_ATTEMPTS {
+			log.Printf("%s failed (attempt %d/%d), retrying in %s: %s\n", description, try, RETRY_ATTEMPTS, delay, err)
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	return err
+}
+
+// HttpGet downloads url with Retry. Server errors and rate limiting are retried, other statuses are not.
+func HttpGet(url string) ([]byte, error) {
+	var body []byte
+
+	err := Retry("GET "+url, func() error {
+		resp, err := http.Get(url)
+
+		if err != nil {
+			return err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			statusErr := fmt.Errorf("%s answered %s", url, resp.Status)
+
+			if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+				return Permanent(statusErr)
+			}
+
+			return statusErr
+		}
+
+		body, err = io.ReadAll(resp.Body)
+
+		return err
+	})
+
+	return body, err
+}
@@ -0,0 +1,109 @@
+package system
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const SIMULATE_PREFIX = "[simulate]"
+
+// SimulatedExecutor pretends every command succeeds and, with pretendInstalled, that every binary is installed.
+type SimulatedExecutor struct {
+	out              io.Writer
+	pretendInstalled bool
+}
+
+func (executor *SimulatedExecutor) LookPath(binName string) (string, bool) {
+	if path, exists := (&OsExecutor{}).LookPath(binName); exists || !executor.pretendInstalled {
+		return path, exists
+	}
+
+	return filepath.Join("/usr/bin", binName), true
+}
+
+func (executor *SimulatedExecutor) Run(cmd *exec.Cmd) error {
+	executor.describe("run", cmd)
+	return nil
+}
+
+func (executor *SimulatedExecutor) Output(cmd *exec.Cmd) ([]byte, error) {
+	executor.describe("run", cmd)
+	return []byte{}, nil
+}
+
+func (executor *SimulatedExecutor) Start(cmd *exec.Cmd) (int, error) {
+	executor.describe("exec", cmd)
+	return 0, nil
+}
+
+func (executor *SimulatedExecutor) Wait(cmd *exec.Cmd) (int, error) {
+	return 0, nil
+}
+
+func (executor *SimulatedExecutor) describe(action string, cmd *exec.Cmd) {
+	fmt.Fprintf(executor.out, "%s %s: %s\n", SIMULATE_PREFIX, action, strings.Join(cmd.Args, " "))
+}
+
+// SimulatedFileSystem describes filesystem changes without performing them.
+type SimulatedFileSystem struct {
+	out io.Writer
+}
+
+func (fileSystem *SimulatedFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	fmt.Fprintf(fileSystem.out, "%s mkdir -p %s\n", SIMULATE_PREFIX, path)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) WriteFile(name string, data []byte, perm os.FileMode) error {
+	fmt.Fprintf(fileSystem.out, "%s write %d bytes to %s\n", SIMULATE_PREFIX, len(data), name)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) Remove(name string) error {
+	fmt.Fprintf(fileSystem.out, "%s rm %s\n", SIMULATE_PREFIX, name)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) RemoveAll(path string) error {
+	fmt.Fprintf(fileSystem.out, "%s rm -rf %s\n", SIMULATE_PREFIX, path)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) Rename(oldpath string, newpath string) error {
+	fmt.Fprintf(fileSystem.out, "%s mv %s %s\n", SIMULATE_PREFIX, oldpath, newpath)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) Symlink(oldname string, newname string) error {
+	fmt.Fprintf(fileSystem.out, "%s ln -s %s %s\n", SIMULATE_PREFIX, oldname, newname)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) Link(oldname string, newname string) error {
+	fmt.Fprintf(fileSystem.out, "%s ln %s %s\n", SIMULATE_PREFIX, oldname, newname)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) Chmod(name string, mode os.FileMode) error {
+	fmt.Fprintf(fileSystem.out, "%s chmod %o %s\n", SIMULATE_PREFIX, mode, name)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) CopyDir(src string, dst string) error {
+	fmt.Fprintf(fileSystem.out, "%s cp -r %s %s\n", SIMULATE_PREFIX, src, dst)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) CloneDir(src string, dst string) error {
+	fmt.Fprintf(fileSystem.out, "%s cp -a --reflink=auto %s %s\n", SIMULATE_PREFIX, src, dst)
+	return nil
+}
+
+func (fileSystem *SimulatedFileSystem) SyncDir(src string, dst string) error {
+	fmt.Fprintf(fileSystem.out, "%s rsync -a --delete %s/ %s/\n", SIMULATE_PREFIX, src, dst)
+	return nil
+}
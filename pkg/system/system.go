@@ -0,0 +1,65 @@
+// Package system is the seam between plauncher and the machine it runs on. Every
+// process plauncher spawns and every change it makes to the filesystem goes through
+// Exec and FS, so the pipeline can be simulated on machines without the tools installed.
+package system
+
+import (
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Executor spawns external processes.
+type Executor interface {
+	// LookPath finds binName in PATH.
+	LookPath(binName string) (string, bool)
+	// Run runs cmd to completion.
+	Run(cmd *exec.Cmd) error
+	// Output runs cmd to completion and returns its stdout.
+	Output(cmd *exec.Cmd) ([]byte, error)
+	// Start starts cmd and returns its pid.
+	Start(cmd *exec.Cmd) (int, error)
+	// Wait waits for a started cmd and returns its exit code.
+	Wait(cmd *exec.Cmd) (int, error)
+}
+
+// FileSystem performs the filesystem changes, reads go straight to the os package.
+type FileSystem interface {
+	MkdirAll(path string, perm os.FileMode) error
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	Remove(name string) error
+	RemoveAll(path string) error
+	Rename(oldpath string, newpath string) error
+	Symlink(oldname string, newname string) error
+	Link(oldname string, newname string) error
+	Chmod(name string, mode os.FileMode) error
+	CopyDir(src string, dst string) error
+	CloneDir(src string, dst string) error
+	SyncDir(src string, dst string) error
+}
+
+var Exec Executor = &OsExecutor{}
+var FS FileSystem = &OsFileSystem{}
+
+var simulating = false
+
+// Simulate replaces Exec and FS with implementations that only describe what would happen to out, pretending
+// every binary is installed.
+func Simulate(out io.Writer) {
+	simulating = true
+	Exec = &SimulatedExecutor{out, true}
+	FS = &SimulatedFileSystem{out}
+}
+
+// SimulateChanges is Simulate looking binaries up for real, only the process and filesystem changes are described,
+// so a dry run shows the wrappers a launch would use.
+func SimulateChanges(out io.Writer) {
+	simulating = true
+	Exec = &SimulatedExecutor{out, false}
+	FS = &SimulatedFileSystem{out}
+}
+
+// Simulating reports whether Simulate was called.
+func Simulating() bool {
+	return simulating
+}
@@ -0,0 +1,87 @@
+package system
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const TIMEOUT_WINETRICKS = "winetricks"
+const TIMEOUT_LEGENDARY = "legendary"
+const TIMEOUT_PROBE = "probe"
+
+// ErrTimeout is wrapped by the errors of commands killed for running past their timeout.
+var ErrTimeout = errors.New("timed out")
+
+// Timeouts bounds the external tools by kind, set from the timeouts section of the configuration. Zero waits forever.
+var Timeouts = map[string]time.Duration{
+	TIMEOUT_WINETRICKS: 10 * time.Minute,
+	TIMEOUT_LEGENDARY:  5 * time.Minute,
+	TIMEOUT_PROBE:      10 * time.Second,
+}
+
+// RunBounded is Exec.Run killing cmd, and every process it started, once the timeout of kind has passed.
+func RunBounded(kind string, cmd *exec.Cmd) error {
+	timeout := Timeouts[kind]
+
+	if timeout <= 0 || Simulating() {
+		return Exec.Run(cmd)
+	}
+
+	startBounded(cmd)
+
+	if _, err := Exec.Start(cmd); err != nil {
+		return err
+	}
+
+	return waitBounded(cmd, timeout)
+}
+
+// OutputBounded is Exec.Output killing cmd, and every process it started, once the timeout of kind has passed.
+func OutputBounded(kind string, cmd *exec.Cmd) ([]byte, error) {
+	timeout := Timeouts[kind]
+
+	if timeout <= 0 || Simulating() {
+		return Exec.Output(cmd)
+	}
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	startBounded(cmd)
+
+	if _, err := Exec.Start(cmd); err != nil {
+		return nil, err
+	}
+
+	err := waitBounded(cmd, timeout)
+
+	return stdout.Bytes(), err
+}
+
+// wine and winetricks leave helpers behind, cmd gets its own process group so they are killed along with it
+func startBounded(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.Setpgid = true
+	cmd.WaitDelay = time.Second
+}
+
+func waitBounded(cmd *exec.Cmd, timeout time.Duration) error {
+	timer := time.AfterFunc(timeout, func() {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	})
+
+	_, err := Exec.Wait(cmd)
+
+	if !timer.Stop() {
+		return fmt.Errorf("%s %w after %s", filepath.Base(cmd.Path), ErrTimeout, timeout)
+	}
+
+	return err
+}
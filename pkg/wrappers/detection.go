@@ -0,0 +1,82 @@
+package wrappers
+
+import (
+	"sync"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const WINETRICKS_BIN_NAME = "winetricks"
+const VKBASALT_BIN_NAME = "vkbasalt"
+const FUSE_OVERLAYFS_BIN_NAME = "fuse-overlayfs"
+const FUSERMOUNT3_BIN_NAME = "fusermount3"
+const FUSERMOUNT_BIN_NAME = "fusermount"
+const WINE_BIN_NAME = "wine"
+const BWRAP_BIN_NAME = "bwrap"
+
+// Every binary plauncher may use, probed once per launch by DetectBinaries
+var KNOWN_BINARIES = []string{
+	GAMEMODE_BIN_NAME,
+	MANGOHUD_BIN_NAME,
+	MANGOAPP_BIN_NAME,
+	GAMESCOPE_BIN_NAME,
+	LEGENDARY_BIN_NAME,
+	UMU_RUN_BIN_NAME,
+	WINETRICKS_BIN_NAME,
+	VKBASALT_BIN_NAME,
+	UNSHARE_BIN_NAME,
+	SLIRP4NETNS_BIN_NAME,
+	TC_BIN_NAME,
+	FIREJAIL_BIN_NAME,
+	XRANDR_BIN_NAME,
+	WLR_RANDR_BIN_NAME,
+	FUSE_OVERLAYFS_BIN_NAME,
+	FUSERMOUNT3_BIN_NAME,
+	FUSERMOUNT_BIN_NAME,
+	WINE_BIN_NAME,
+	BWRAP_BIN_NAME,
+}
+
+type binaryLookup struct {
+	done   chan struct{}
+	path   string
+	exists bool
+}
+
+var binaryLookups = make(map[string]*binaryLookup)
+var binaryLookupsLock sync.Mutex
+
+// DetectBinaries starts looking every binName up concurrently and returns right away,
+// CheckIfBinExists waits for the pending lookup instead of probing again.
+func DetectBinaries(binNames ...string) {
+	for _, binName := range binNames {
+		startLookup(binName)
+	}
+}
+
+// CheckIfBinExists looks binName up in PATH, returning its full path. Results are cached for the launch.
+func CheckIfBinExists(binName string) (string, bool) {
+	lookup := startLookup(binName)
+	<-lookup.done
+
+	return lookup.path, lookup.exists
+}
+
+func startLookup(binName string) *binaryLookup {
+	binaryLookupsLock.Lock()
+	defer binaryLookupsLock.Unlock()
+
+	if lookup, exists := binaryLookups[binName]; exists {
+		return lookup
+	}
+
+	lookup := &binaryLookup{done: make(chan struct{})}
+	binaryLookups[binName] = lookup
+
+	go func() {
+		lookup.path, lookup.exists = system.Exec.LookPath(binName)
+		close(lookup.done)
+	}()
+
+	return lookup
+}
@@ -0,0 +1,135 @@
+package wrappers
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const XRANDR_BIN_NAME = "xrandr"
+const WLR_RANDR_BIN_NAME = "wlr-randr"
+
+// DisplayMode is the resolution and refresh rate of an output, Refresh is 0 when unknown.
+type DisplayMode struct {
+	Width   int
+	Height  int
+	Refresh int
+}
+
+// Named modes accepted by ParseDisplayMode
+var namedDisplayModes = map[string]DisplayMode{
+	config.TV_MODE_4K60:     {3840, 2160, 60},
+	config.TV_MODE_1080P120: {1920, 1080, 120},
+}
+
+// "2560x1440@144", the refresh rate being optional
+var displayModeRegex = regexp.MustCompile(`^(\d+)x(\d+)(?:@(\d+))?$`)
+
+// "   2560x1440    143.97*+  59.95"
+var xrandrCurrentModeRegex = regexp.MustCompile(`^\s+(\d+)x(\d+)\S*\s.*?([0-9.]+)\*`)
+
+// "    2560x1440 px, 143.998001 Hz (preferred, current)"
+var wlrRandrCurrentModeRegex = regexp.MustCompile(`^\s+(\d+)x(\d+) px, ([0-9.]+) Hz .*current`)
+
+// DetectDisplayMode returns the current mode of the first active output, asking xrandr, then wlr-randr,
+// then falling back to the preferred mode of the first connected DRM connector, without refresh rate.
+func DetectDisplayMode() (DisplayMode, bool) {
+	if bin, exists := CheckIfBinExists(XRANDR_BIN_NAME); exists {
+		if mode, found := currentModeFromCommand(exec.Command(bin, "--current"), xrandrCurrentModeRegex); found {
+			return mode, true
+		}
+	}
+
+	if bin, exists := CheckIfBinExists(WLR_RANDR_BIN_NAME); exists {
+		if mode, found := currentModeFromCommand(exec.Command(bin), wlrRandrCurrentModeRegex); found {
+			return mode, true
+		}
+	}
+
+	return preferredDrmMode()
+}
+
+// ParseDisplayMode reads a named mode (4k60, 1080p120) or WIDTHxHEIGHT[@REFRESH].
+func ParseDisplayMode(text string) (DisplayMode, error) {
+	if mode, exists := namedDisplayModes[strings.ToLower(text)]; exists {
+		return mode, nil
+	}
+
+	match := displayModeRegex.FindStringSubmatch(text)
+
+	if match == nil {
+		return DisplayMode{}, fmt.Errorf("Invalid display mode %s, expected 4k60, 1080p120 or WIDTHxHEIGHT@REFRESH", text)
+	}
+
+	width, _ := strconv.Atoi(match[1])
+	height, _ := strconv.Atoi(match[2])
+	refresh, _ := strconv.Atoi(match[3])
+
+	return DisplayMode{width, height, refresh}, nil
+}
+
+func currentModeFromCommand(cmdHandle *exec.Cmd, currentModeRegex *regexp.Regexp) (DisplayMode, bool) {
+	output, err := system.OutputBounded(system.TIMEOUT_PROBE, cmdHandle)
+
+	if err != nil {
+		log.Printf("Could not query display modes with %s: %s\n", cmdHandle.Path, err)
+		return DisplayMode{}, false
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+
+	for scanner.Scan() {
+		match := currentModeRegex.FindStringSubmatch(scanner.Text())
+
+		if match == nil {
+			continue
+		}
+
+		width, _ := strconv.Atoi(match[1])
+		height, _ := strconv.Atoi(match[2])
+		refresh, _ := strconv.ParseFloat(match[3], 64)
+
+		return DisplayMode{width, height, int(math.Round(refresh))}, true
+	}
+
+	return DisplayMode{}, false
+}
+
+func preferredDrmMode() (DisplayMode, bool) {
+	connectors, _ := filepath.Glob("/sys/class/drm/card*-*")
+
+	for _, connector := range connectors {
+		status, err := os.ReadFile(filepath.Join(connector, "status"))
+
+		if err != nil || strings.TrimSpace(string(status)) != "connected" {
+			continue
+		}
+
+		modes, err := os.ReadFile(filepath.Join(connector, "modes"))
+
+		if err != nil {
+			continue
+		}
+
+		preferred, _, _ := strings.Cut(string(modes), "\n")
+		widthText, heightText, found := strings.Cut(preferred, "x")
+		width, widthErr := strconv.Atoi(widthText)
+		height, heightErr := strconv.Atoi(strings.TrimRightFunc(heightText, func(char rune) bool { return char < '0' || char > '9' }))
+
+		if found && widthErr == nil && heightErr == nil {
+			return DisplayMode{width, height, 0}, true
+		}
+	}
+
+	return DisplayMode{}, false
+}
@@ -0,0 +1,32 @@
+package wrappers
+
+import (
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+)
+
+// GamemodeWrapper runs the game through gamemoderun.
+type GamemodeWrapper struct{}
+
+func (wrapper *GamemodeWrapper) Name() string {
+	return GAMEMODE_BIN_NAME
+}
+
+func (wrapper *GamemodeWrapper) Detect(configuration *config.Configuration) (string, bool) {
+	if !configuration.Gamemode.Enabled {
+		return "", false
+	}
+
+	return CheckIfBinExists(GAMEMODE_BIN_NAME)
+}
+
+func (wrapper *GamemodeWrapper) Validate(configuration *config.Configuration, paths config.Paths) error {
+	return nil
+}
+
+func (wrapper *GamemodeWrapper) Env(configuration *config.Configuration, paths config.Paths) map[string]string {
+	return nil
+}
+
+func (wrapper *GamemodeWrapper) Args(bin string, configuration *config.Configuration, paths config.Paths) []string {
+	return []string{bin}
+}
@@ -0,0 +1,305 @@
+package wrappers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/handheld"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const GAMESCOPE_MANGOAPP_ARGV = "--mangoapp"
+const GAMESCOPE_HDR_ARGV = "--hdr-enabled"
+const GAMESCOPE_HDR_SDR_CONTENT_NITS_ARGV = "--hdr-sdr-content-nits"
+const GAMESCOPE_HDR_ITM_ARGV = "--hdr-itm-enable"
+const GAMESCOPE_HDR_ITM_SDR_NITS_ARGV = "--hdr-itm-sdr-nits"
+const GAMESCOPE_HDR_ITM_TARGET_NITS_ARGV = "--hdr-itm-target-nits"
+const GAMESCOPE_SDR_GAMUT_WIDENESS_ARGV = "--sdr-gamut-wideness"
+const GAMESCOPE_ADAPTIVE_SYNC_ARGV = "--adaptive-sync"
+const GAMESCOPE_GRAB_ARGV = "--grab"
+const GAMESCOPE_FORCE_GRAB_CURSOR_ARGV = "--force-grab-cursor"
+const GAMESCOPE_MOUSE_SENSITIVITY_ARGV = "--mouse-sensitivity"
+const GAMESCOPE_HIDE_CURSOR_DELAY_ARGV = "--hide-cursor-delay"
+
+// Milliseconds before the idle cursor hides in tv mode, unless gamescope.input sets it
+const TV_HIDE_CURSOR_DELAY = 3000
+
+// Spelled this way by gamescope
+const GAMESCOPE_HDR_WIDE_GAMUT_FOR_SDR_ARGV = "--hdr-wide-gammut-for-sdr"
+
+var gamescopeFlagRegex = regexp.MustCompile(`(?:^|[\s,\[])(--?[A-Za-z][A-Za-z0-9-]*)`)
+
+// GamescopeWrapper runs the game nested in a gamescope session.
+type GamescopeWrapper struct{}
+
+func (wrapper *GamescopeWrapper) Name() string {
+	return GAMESCOPE_BIN_NAME
+}
+
+func (wrapper *GamescopeWrapper) Detect(configuration *config.Configuration) (string, bool) {
+	if !configuration.Gamescope.Enabled {
+		return "", false
+	}
+
+	return CheckIfBinExists(GAMESCOPE_BIN_NAME)
+}
+
+func (wrapper *GamescopeWrapper) Validate(configuration *config.Configuration, paths config.Paths) error {
+	gamescopeArgs, err := splitArgs(configuration.Gamescope.Args)
+
+	if err != nil {
+		return fmt.Errorf("Invalid gamescope.args: %s", err)
+	}
+
+	if configuration.Gamescope.ValidateArgs {
+		warnUnknownGamescopeFlags(gamescopeArgs)
+	}
+
+	if preset := configuration.Handheld.Preset; preset != "" && preset != handheld.PRESET_AUTO {
+		if _, found := handheld.FindPreset(preset); !found {
+			return fmt.Errorf("Unknown handheld.preset %s", preset)
+		}
+	}
+
+	if configuration.Tv.Enabled {
+		if _, err := ParseDisplayMode(configuration.Tv.Mode); err != nil {
+			return fmt.Errorf("Invalid tv.mode: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// Gamescope exits right away on a flag it does not know, without anything visible to the user
+func warnUnknownGamescopeFlags(gamescopeArgs []string) {
+	bin, exists := CheckIfBinExists(GAMESCOPE_BIN_NAME)
+
+	if !exists {
+		return
+	}
+
+	var stderr bytes.Buffer
+	cmdHandle := exec.Command(bin, "--help")
+	cmdHandle.Stderr = &stderr
+
+	// --help may exit with an error status, the usage text is all that matters
+	stdout, _ := system.OutputBounded(system.TIMEOUT_PROBE, cmdHandle)
+	knownFlags := make([]string, 0)
+
+	for _, match := range gamescopeFlagRegex.FindAllStringSubmatch(string(stdout)+stderr.String(), -1) {
+		knownFlags = append(knownFlags, match[1])
+	}
+
+	if len(knownFlags) == 0 {
+		log.Println("Could not read gamescope flags from its --help output, skipping validation")
+		return
+	}
+
+	for _, arg := range gamescopeArgs {
+		flag, _, _ := strings.Cut(arg, "=")
+
+		if !strings.HasPrefix(flag, "-") || slices.Contains(knownFlags, flag) {
+			continue
+		}
+
+		log.Printf("WARNING: gamescope does not know the flag %s, it will probably exit right away\n", flag)
+		fmt.Fprintf(os.Stderr, "plauncher: unknown gamescope flag %s\n", flag)
+	}
+}
+
+func (wrapper *GamescopeWrapper) Env(configuration *config.Configuration, paths config.Paths) map[string]string {
+	if !configuration.Gamescope.Hdr.Enabled {
+		return nil
+	}
+
+	return map[string]string{
+		"DXVK_HDR":       "1",
+		"ENABLE_HDR_WSI": "1",
+	}
+}
+
+func (wrapper *GamescopeWrapper) Args(bin string, configuration *config.Configuration, paths config.Paths) []string {
+	if configuration.Gamescope.Hdr.Enabled {
+		configuration.Gamescope.Args = appendMissingFlags(configuration.Gamescope.Args, hdrArgs(configuration.Gamescope.Hdr))
+	}
+
+	if configuration.Tv.Enabled && configuration.Gamescope.Input.HideCursorDelay == 0 {
+		configuration.Gamescope.Input.HideCursorDelay = TV_HIDE_CURSOR_DELAY
+	}
+
+	configuration.Gamescope.Args = appendMissingFlags(configuration.Gamescope.Args, inputArgs(configuration.Gamescope.Input))
+
+	// Docked handhelds follow the TV instead
+	if preset, found := handheld.FindPreset(configuration.Handheld.Preset); found && !configuration.Tv.Enabled {
+		log.Printf("Using the gamescope defaults of the %s\n", preset.Device)
+		configuration.Gamescope.Args = appendMissingFlags(configuration.Gamescope.Args, preset.GamescopeArgs)
+	}
+
+	// The game is always fullscreen in gamescope, game-only is the same as on
+	if vrr := configuration.Display.Vrr; vrr == config.DISPLAY_VRR_ON || vrr == config.DISPLAY_VRR_GAME_ONLY {
+		configuration.Gamescope.Args = appendMissingFlags(configuration.Gamescope.Args, [][]string{{GAMESCOPE_ADAPTIVE_SYNC_ARGV}})
+	}
+
+	/*
+		if _, exists := CheckIfBinExists(MANGOAPP_BIN_NAME); exists && configuration.Mangohud.Enabled {
+			configuration.Environment["MANGOHUD_CONFIGFILE"] = filepath.Join(paths.UserConfigDir, "MangoHud", "MangoHud-GS.conf")
+			configuration.Environment["MANGOHUD"] = "0"
+			configuration.Environment["DISABLE_MANGOAPP"] = "0"
+			if !slices.Contains(configuration.Gamescope.Args, GAMESCOPE_MANGOAPP_ARGV) {
+				args = append(args, GAMESCOPE_MANGOAPP_ARGV)
+			}
+		}
+	*/
+
+	gamescopeArgs, _ := splitArgs(configuration.Gamescope.Args)
+
+	// Fullscreen instances would cover each other
+	if config.Instance(*configuration) > 0 {
+		gamescopeArgs = slices.DeleteFunc(gamescopeArgs, func(arg string) bool { return arg == "-f" || arg == "--fullscreen" })
+	}
+
+	args := append([]string{bin}, gamescopeArgs...)
+	args = append(args, displayModeArgs(gamescopeArgs, *configuration)...)
+
+	return append(args, "--")
+}
+
+// Brightness and color settings only apply to HDR output, unset ones keep the gamescope defaults
+func hdrArgs(hdr config.GamescopeHdrConfiguration) [][]string {
+	args := [][]string{{GAMESCOPE_HDR_ARGV}}
+
+	if hdr.SdrContentNits > 0 {
+		args = append(args, []string{GAMESCOPE_HDR_SDR_CONTENT_NITS_ARGV, strconv.Itoa(hdr.SdrContentNits)})
+	}
+
+	if hdr.Itm {
+		args = append(args, []string{GAMESCOPE_HDR_ITM_ARGV})
+
+		if hdr.ItmSdrNits > 0 {
+			args = append(args, []string{GAMESCOPE_HDR_ITM_SDR_NITS_ARGV, strconv.Itoa(hdr.ItmSdrNits)})
+		}
+
+		if hdr.ItmTargetNits > 0 {
+			args = append(args, []string{GAMESCOPE_HDR_ITM_TARGET_NITS_ARGV, strconv.Itoa(hdr.ItmTargetNits)})
+		}
+	}
+
+	if hdr.SdrGamutWideness > 0 {
+		args = append(args, []string{GAMESCOPE_SDR_GAMUT_WIDENESS_ARGV, strconv.FormatFloat(hdr.SdrGamutWideness, 'f', -1, 64)})
+	}
+
+	if hdr.WideGamutForSdr {
+		args = append(args, []string{GAMESCOPE_HDR_WIDE_GAMUT_FOR_SDR_ARGV})
+	}
+
+	return args
+}
+
+func inputArgs(input config.GamescopeInputConfiguration) [][]string {
+	args := make([][]string, 0)
+
+	if input.GrabKeyboard {
+		args = append(args, []string{GAMESCOPE_GRAB_ARGV})
+	}
+
+	if input.GrabCursor {
+		args = append(args, []string{GAMESCOPE_FORCE_GRAB_CURSOR_ARGV})
+	}
+
+	if input.MouseSensitivity > 0 {
+		args = append(args, []string{GAMESCOPE_MOUSE_SENSITIVITY_ARGV, strconv.FormatFloat(input.MouseSensitivity, 'f', -1, 64)})
+	}
+
+	if input.HideCursorDelay > 0 {
+		args = append(args, []string{GAMESCOPE_HIDE_CURSOR_DELAY_ARGV, strconv.Itoa(input.HideCursorDelay)})
+	}
+
+	return args
+}
+
+// Appends every flag, along with its value, unless gamescopeArgs already sets it, the configured args win
+func appendMissingFlags(gamescopeArgs []string, flags [][]string) []string {
+	configuredArgs, _ := splitArgs(gamescopeArgs)
+
+	for _, flag := range flags {
+		hasFlag := slices.ContainsFunc(configuredArgs, func(arg string) bool {
+			return arg == flag[0] || strings.HasPrefix(arg, flag[0]+"=")
+		})
+
+		if !hasFlag {
+			gamescopeArgs = append(gamescopeArgs, flag...)
+		}
+	}
+
+	return gamescopeArgs
+}
+
+// Gamescope defaults for the output size differ across versions, match the active display unless set,
+// or the TV mode the display is switched to in tv mode. Instances get their share of it as a window.
+func displayModeArgs(gamescopeArgs []string, configuration config.Configuration) []string {
+	hasFlag := func(flags ...string) bool {
+		return slices.ContainsFunc(gamescopeArgs, func(arg string) bool {
+			flag, _, _ := strings.Cut(arg, "=")
+			return slices.Contains(flags, flag)
+		})
+	}
+
+	if hasFlag("-W", "--output-width", "-H", "--output-height") {
+		return nil
+	}
+
+	mode, found := DisplayMode{}, false
+
+	if configuration.Tv.Enabled {
+		// Checked by Validate
+		mode, _ = ParseDisplayMode(configuration.Tv.Mode)
+		found = true
+	} else {
+		mode, found = DetectDisplayMode()
+	}
+
+	if !found {
+		log.Println("Could not detect the active display mode, using gamescope defaults")
+		return nil
+	}
+
+	log.Printf("Using display mode %dx%d@%d for gamescope\n", mode.Width, mode.Height, mode.Refresh)
+
+	if instance := config.Instance(configuration); instance > 0 {
+		return instanceWindowArgs(mode, configuration.Instances, instance)
+	}
+
+	args := []string{"-W", strconv.Itoa(mode.Width), "-H", strconv.Itoa(mode.Height)}
+
+	if mode.Refresh > 0 && !hasFlag("-r", "--nested-refresh") {
+		args = append(args, "-r", strconv.Itoa(mode.Refresh))
+	}
+
+	return args
+}
+
+// The game renders at the size of its window, the compositor places the windows
+func instanceWindowArgs(mode DisplayMode, instances config.InstancesConfiguration, instance int) []string {
+	width, height := mode.Width, mode.Height
+	count := max(instances.Count, 1)
+
+	if instances.Split == config.INSTANCES_SPLIT_STACKED {
+		height /= count
+	} else {
+		width /= count
+	}
+
+	log.Printf("Using a %dx%d gamescope window for instance %d\n", width, height, instance)
+
+	windowWidth, windowHeight := strconv.Itoa(width), strconv.Itoa(height)
+
+	return []string{"-W", windowWidth, "-H", windowHeight, "-w", windowWidth, "-h", windowHeight}
+}
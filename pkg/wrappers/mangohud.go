@@ -0,0 +1,85 @@
+package wrappers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// MANGOHUD_CONTROL_PREFIX starts the names of the abstract sockets MangoHud listens on for plauncher control
+const MANGOHUD_CONTROL_PREFIX = "plauncher-mangohud-"
+
+// MangohudWrapper enables the MangoHud vulkan layer through the environment, it adds no command.
+type MangohudWrapper struct{}
+
+func (wrapper *MangohudWrapper) Name() string {
+	return MANGOHUD_BIN_NAME
+}
+
+func (wrapper *MangohudWrapper) Detect(configuration *config.Configuration) (string, bool) {
+	if !configuration.Mangohud.Enabled {
+		return "", false
+	}
+
+	return CheckIfBinExists(MANGOHUD_BIN_NAME)
+}
+
+func (wrapper *MangohudWrapper) Validate(configuration *config.Configuration, paths config.Paths) error {
+	return nil
+}
+
+func (wrapper *MangohudWrapper) Env(configuration *config.Configuration, paths config.Paths) map[string]string {
+	env := map[string]string{
+		"MANGOHUD_CONFIGFILE": filepath.Join(paths.UserConfigDir, "MangoHud", "MangoHud.conf"),
+		"MANGOHUD":            "1",
+		"DISABLE_MANGOAPP":    "1",
+	}
+
+	if configuration.Control.Enabled {
+		env["MANGOHUD_CONFIG"] = mangohudControlConfig(configuration)
+	}
+
+	return env
+}
+
+// MangohudControlName returns the start of the abstract socket names MangoHud listens on in the processes of the
+// launch of launcherPid, each one adding its own pid.
+func MangohudControlName(launcherPid int) string {
+	return fmt.Sprintf("%s%d-", MANGOHUD_CONTROL_PREFIX, launcherPid)
+}
+
+// Adds the control socket to the MANGOHUD_CONFIG of the game, or of plauncher, keeping the configuration file read
+// when neither sets one
+func mangohudControlConfig(configuration *config.Configuration) string {
+	mangohudConfig, found := configuration.Environment["MANGOHUD_CONFIG"]
+
+	if !found {
+		mangohudConfig = os.Getenv("MANGOHUD_CONFIG")
+	}
+
+	if strings.Contains(mangohudConfig, "control=") {
+		return mangohudConfig
+	}
+
+	if mangohudConfig == "" {
+		mangohudConfig = "read_cfg"
+	}
+
+	controlName := MangohudControlName(os.Getpid())
+
+	// The pid of the launch a simulation describes is not known, the one of the simulation would mislead
+	if system.Simulating() {
+		controlName = MANGOHUD_CONTROL_PREFIX + "<pid>-"
+	}
+
+	// MangoHud replaces %p with the pid of the process it is loaded in
+	return mangohudConfig + ",control=" + controlName + "%p"
+}
+
+func (wrapper *MangohudWrapper) Args(bin string, configuration *config.Configuration, paths config.Paths) []string {
+	return nil
+}
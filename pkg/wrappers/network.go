@@ -0,0 +1,178 @@
+package wrappers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const UNSHARE_BIN_NAME = "unshare"
+const SLIRP4NETNS_BIN_NAME = "slirp4netns"
+const TC_BIN_NAME = "tc"
+const FIREJAIL_BIN_NAME = "firejail"
+
+// NETWORK_SHAPE_COMMAND is the plauncher subcommand running a command inside its own network namespace,
+// bandwidth limited and/or bound to an outbound interface.
+const NETWORK_SHAPE_COMMAND = "network-shape"
+
+const NETWORK_TAP_DEVICE = "tap0"
+
+var networkRateRegex = regexp.MustCompile(`(?i)^[0-9]+(\.[0-9]+)?(bit|kbit|mbit|gbit|bps|kbps|mbps|gbps)$`)
+
+// NetworkWrapper runs the game in its own network namespace, connected through slirp4netns and shaped with tc,
+// or in an existing named namespace (e.g. one holding only a VPN) through firejail.
+type NetworkWrapper struct{}
+
+func (wrapper *NetworkWrapper) Name() string {
+	return "network"
+}
+
+func (wrapper *NetworkWrapper) Detect(configuration *config.Configuration) (string, bool) {
+	// Running outside of the namespace is not an option, Validate reports a missing firejail
+	if configuration.Network.Namespace != "" {
+		bin, _ := CheckIfBinExists(FIREJAIL_BIN_NAME)
+		return bin, true
+	}
+
+	if configuration.Network.Limit == "" && configuration.Network.Interface == "" {
+		return "", false
+	}
+
+	self, err := os.Executable()
+
+	if err != nil {
+		log.Printf("Could not find the plauncher executable, network limit disabled: %s\n", err)
+		return "", false
+	}
+
+	return self, true
+}
+
+func (wrapper *NetworkWrapper) Validate(configuration *config.Configuration, paths config.Paths) error {
+	network := configuration.Network
+
+	if network.Namespace != "" {
+		if network.Limit != "" || network.Interface != "" {
+			return errors.New("network.namespace can not be combined with network.limit or network.interface")
+		}
+
+		if _, exists := CheckIfBinExists(FIREJAIL_BIN_NAME); !exists {
+			return fmt.Errorf("%w: network.namespace needs %s to be installed", ErrMissingDependency, FIREJAIL_BIN_NAME)
+		}
+
+		return nil
+	}
+
+	if network.Limit != "" && !networkRateRegex.MatchString(network.Limit) {
+		return fmt.Errorf("Invalid network.limit %s, expected a rate like 5mbit or 500kbps", network.Limit)
+	}
+
+	requiredBinaries := []string{UNSHARE_BIN_NAME, SLIRP4NETNS_BIN_NAME}
+
+	if network.Limit != "" {
+		requiredBinaries = append(requiredBinaries, TC_BIN_NAME)
+	}
+
+	for _, binName := range requiredBinaries {
+		if _, exists := CheckIfBinExists(binName); !exists {
+			return fmt.Errorf("%w: network.limit and network.interface need %s to be installed", ErrMissingDependency, binName)
+		}
+	}
+
+	return nil
+}
+
+func (wrapper *NetworkWrapper) Env(configuration *config.Configuration, paths config.Paths) map[string]string {
+	return nil
+}
+
+func (wrapper *NetworkWrapper) Args(bin string, configuration *config.Configuration, paths config.Paths) []string {
+	if configuration.Network.Namespace != "" {
+		return []string{bin, "--quiet", "--noprofile", "--netns=" + configuration.Network.Namespace, "--"}
+	}
+
+	args := []string{bin, NETWORK_SHAPE_COMMAND}
+
+	if configuration.Network.Limit != "" {
+		args = append(args, "--limit="+strings.ToLower(configuration.Network.Limit))
+	}
+
+	if configuration.Network.Interface != "" {
+		args = append(args, "--interface="+configuration.Network.Interface)
+	}
+
+	return append(args, "--")
+}
+
+// Waits for slirp4netns to create the tap device, shapes both directions when LIMIT is set, then becomes the game
+const networkShapeScript = `tries=0
+until ip link show ` + NETWORK_TAP_DEVICE + ` >/dev/null 2>&1; do
+	tries=$((tries + 1))
+	[ "$tries" -gt 100 ] && echo "plauncher: network namespace not ready" >&2 && exit 1
+	sleep 0.1
+done
+if [ -n "$LIMIT" ]; then
+	tc qdisc add dev ` + NETWORK_TAP_DEVICE + ` root tbf rate "$LIMIT" burst 64kb latency 400ms || exit 1
+	tc qdisc add dev ` + NETWORK_TAP_DEVICE + ` handle ffff: ingress || exit 1
+	tc filter add dev ` + NETWORK_TAP_DEVICE + ` parent ffff: protocol all u32 match u32 0 0 police rate "$LIMIT" burst 64kb drop || exit 1
+fi
+exec "$@"`
+
+// RunInNetworkNamespace runs command in a new user and network namespace. Its traffic is limited to limit,
+// a tc rate, and leaves through outboundInterface, when they are set. It returns the exit code of command.
+func RunInNetworkNamespace(limit string, outboundInterface string, command []string) (int, error) {
+	unshareBin, exists := CheckIfBinExists(UNSHARE_BIN_NAME)
+
+	if !exists {
+		return -1, fmt.Errorf("%s is not installed", UNSHARE_BIN_NAME)
+	}
+
+	slirpBin, exists := CheckIfBinExists(SLIRP4NETNS_BIN_NAME)
+
+	if !exists {
+		return -1, fmt.Errorf("%s is not installed", SLIRP4NETNS_BIN_NAME)
+	}
+
+	// Root in the new user namespace is what allows tc on the tap device, without privileges outside
+	args := append([]string{"--user", "--map-root-user", "--net", "--fork", "sh", "-c", networkShapeScript, "sh"}, command...)
+	cmdHandle := exec.Command(unshareBin, args...)
+	cmdHandle.Env = append(os.Environ(), "LIMIT="+limit)
+	cmdHandle.Stdin = os.Stdin
+	cmdHandle.Stdout = os.Stdout
+	cmdHandle.Stderr = os.Stderr
+
+	pid, err := system.Exec.Start(cmdHandle)
+
+	if err != nil {
+		return -1, err
+	}
+
+	slirpArgs := []string{"--configure", "--mtu=65520", "--disable-host-loopback"}
+
+	if outboundInterface != "" {
+		slirpArgs = append(slirpArgs, "--outbound-addr="+outboundInterface)
+	}
+
+	slirpHandle := exec.Command(slirpBin, append(slirpArgs, fmt.Sprint(pid), NETWORK_TAP_DEVICE)...)
+
+	if _, err := system.Exec.Start(slirpHandle); err != nil {
+		cmdHandle.Process.Kill()
+		return -1, fmt.Errorf("Failed to start %s: %s", SLIRP4NETNS_BIN_NAME, err)
+	}
+
+	exitCode, err := system.Exec.Wait(cmdHandle)
+
+	if slirpHandle.Process != nil {
+		slirpHandle.Process.Kill()
+		slirpHandle.Wait()
+	}
+
+	return exitCode, err
+}
@@ -0,0 +1,217 @@
+package wrappers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SplitShellWords splits line the way a POSIX shell splits words, without expansions:
+// single and double quotes group words and backslashes escape the next character.
+func SplitShellWords(line string) ([]string, error) {
+	return splitShellWords(line, nil)
+}
+
+// ExpandShellWords is SplitShellWords also expanding $VAR and ${VAR} outside single quotes, with the values of
+// lookup. An expanded value is never split further, as if it was quoted.
+func ExpandShellWords(line string, lookup func(string) string) ([]string, error) {
+	return splitShellWords(line, lookup)
+}
+
+func splitShellWords(line string, lookup func(string) string) ([]string, error) {
+	words := make([]string, 0)
+	word := strings.Builder{}
+	inWord := false
+	var quote rune
+	escaped := false
+	chars := []rune(line)
+
+	for index := 0; index < len(chars); index++ {
+		char := chars[index]
+
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes the characters that are special there
+			if quote == '"' && !strings.ContainsRune("\"\\$`", char) {
+				word.WriteRune('\\')
+			}
+
+			word.WriteRune(char)
+			escaped = false
+		case char == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0 && char == quote:
+			quote = 0
+		case char == '$' && quote != '\'' && lookup != nil && variableAt(chars, index+1) != "":
+			name := variableAt(chars, index+1)
+			index += len([]rune(name))
+			name = strings.Trim(name, "{}")
+
+			if value := lookup(name); value != "" || quote != 0 {
+				word.WriteString(value)
+				inWord = true
+			}
+		case quote != 0:
+			word.WriteRune(char)
+		case char == '\'' || char == '"':
+			quote = char
+			inWord = true
+		case char == ' ' || char == '\t' || char == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("Trailing backslash in: %s", line)
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("Unterminated %c quote in: %s", quote, line)
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// ReplaceShellVariables replaces the $VAR and ${VAR} of line that values holds, outside single quotes, escaping each
+// value so that it stays a single word once line is split. Other variables are left as they are.
+func ReplaceShellVariables(line string, values map[string]string) string {
+	replaced := strings.Builder{}
+	var quote rune
+	escaped := false
+	chars := []rune(line)
+
+	for index := 0; index < len(chars); index++ {
+		char := chars[index]
+
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote == 0 && (char == '\'' || char == '"'):
+			quote = char
+		case char == '$' && quote != '\'':
+			name := variableAt(chars, index+1)
+
+			if value, found := values[strings.Trim(name, "{}")]; found && name != "" {
+				replaced.WriteString(escapeShellValue(value, quote))
+				index += len([]rune(name))
+				continue
+			}
+		}
+
+		replaced.WriteRune(char)
+	}
+
+	return replaced.String()
+}
+
+// Backslash escapes the characters of value special in the quote it is written in, 0 being outside quotes
+func escapeShellValue(value string, quote rune) string {
+	special := "\\\"$`"
+
+	if quote == 0 {
+		special += " \t\n'|&;<>()*?[]#~{}"
+	}
+
+	escaped := strings.Builder{}
+
+	for _, char := range value {
+		if strings.ContainsRune(special, char) {
+			escaped.WriteRune('\\')
+		}
+
+		escaped.WriteRune(char)
+	}
+
+	return escaped.String()
+}
+
+// The variable name starting at chars[start], braces included for ${VAR}, or "" when there is none
+func variableAt(chars []rune, start int) string {
+	if start < len(chars) && chars[start] == '{' {
+		for end := start + 1; end < len(chars); end++ {
+			if chars[end] == '}' {
+				if end == start+1 {
+					return ""
+				}
+
+				return string(chars[start : end+1])
+			}
+
+			if !isVariableChar(chars[end], end == start+1) {
+				return ""
+			}
+		}
+
+		return ""
+	}
+
+	end := start
+
+	for end < len(chars) && isVariableChar(chars[end], end == start) {
+		end++
+	}
+
+	return string(chars[start:end])
+}
+
+func isVariableChar(char rune, first bool) bool {
+	return char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (!first && char >= '0' && char <= '9')
+}
+
+// Splits every entry of args into shell words, so one entry can hold a flag and its value
+func splitArgs(args []string) ([]string, error) {
+	splitArgs := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		words, err := SplitShellWords(arg)
+
+		if err != nil {
+			return nil, err
+		}
+
+		splitArgs = append(splitArgs, words...)
+	}
+
+	return splitArgs, nil
+}
+
+// Splits every entry of args into shell words, expanding the variables of environment, then of the process
+func expandArgs(args []string, environment map[string]string) ([]string, error) {
+	lookup := func(name string) string {
+		if value, exists := environment[name]; exists {
+			return os.ExpandEnv(value)
+		}
+
+		return os.Getenv(name)
+	}
+
+	expandedArgs := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		words, err := ExpandShellWords(arg, lookup)
+
+		if err != nil {
+			return nil, err
+		}
+
+		expandedArgs = append(expandedArgs, words...)
+	}
+
+	return expandedArgs, nil
+}
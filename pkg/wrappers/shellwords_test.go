@@ -0,0 +1,81 @@
+package wrappers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{"empty line", "", []string{}},
+		{"blanks only", " \t\n", []string{}},
+		{"plain words", "umu-run  game.exe\t-windowed", []string{"umu-run", "game.exe", "-windowed"}},
+		{"double quoted value with spaces", `--name="Portal 2" umu-run`, []string{"--name=Portal 2", "umu-run"}},
+		{"single quoted value with spaces", `'My Games/game.exe' -dx11`, []string{"My Games/game.exe", "-dx11"}},
+		{"empty quotes", `a "" ''`, []string{"a", "", ""}},
+		{"escaped space", `My\ Game.exe`, []string{"My Game.exe"}},
+		{"escaped quote in double quotes", `"say \"hi\""`, []string{`say "hi"`}},
+		{"backslash kept in double quotes", `"C:\games"`, []string{`C:\games`}},
+		{"backslash kept in single quotes", `'C:\games'`, []string{`C:\games`}},
+		{"variables not expanded", `$HOME "${PREFIX}"`, []string{"$HOME", "${PREFIX}"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			words, err := SplitShellWords(test.line)
+
+			if err != nil {
+				t.Fatalf("SplitShellWords: %s", err)
+			}
+
+			if !slices.Equal(words, test.expected) {
+				t.Errorf("words %q, expected %q", words, test.expected)
+			}
+		})
+	}
+
+	for _, line := range []string{`"unterminated`, `'unterminated`, `trailing\`} {
+		t.Run(line, func(t *testing.T) {
+			if _, err := SplitShellWords(line); err == nil {
+				t.Errorf("%s was split, expected an error", line)
+			}
+		})
+	}
+}
+
+func TestExpandShellWords(t *testing.T) {
+	values := map[string]string{"GAME": "My Game", "EMPTY": ""}
+	lookup := func(name string) string { return values[name] }
+
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{"expanded value is not split", "$GAME.exe", []string{"My Game.exe"}},
+		{"braces", "${GAME}s", []string{"My Games"}},
+		{"in double quotes", `"$GAME"`, []string{"My Game"}},
+		{"not in single quotes", `'$GAME'`, []string{"$GAME"}},
+		{"escaped dollar", `\$GAME`, []string{"$GAME"}},
+		{"empty variable drops the word", "a $EMPTY b", []string{"a", "b"}},
+		{"quoted empty variable keeps the word", `a "$EMPTY" b`, []string{"a", "", "b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			words, err := ExpandShellWords(test.line, lookup)
+
+			if err != nil {
+				t.Fatalf("ExpandShellWords: %s", err)
+			}
+
+			if !slices.Equal(words, test.expected) {
+				t.Errorf("words %q, expected %q", words, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package wrappers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+// UmuWrapper runs games outside Steam through umu-run and a Proton build.
+type UmuWrapper struct{}
+
+func (wrapper *UmuWrapper) Name() string {
+	return UMU_RUN_BIN_NAME
+}
+
+func (wrapper *UmuWrapper) Detect(configuration *config.Configuration) (string, bool) {
+	if _, exists := os.LookupEnv("STEAM_COMPAT_DATA_PATH"); exists || !configuration.Umu.Enabled {
+		return "", false
+	}
+
+	return CheckIfBinExists(UMU_RUN_BIN_NAME)
+}
+
+func (wrapper *UmuWrapper) Validate(configuration *config.Configuration, paths config.Paths) error {
+	if _, exists := configuration.Props["name"]; !exists {
+		return errors.New("Games outside steam need a name. Set with --name=$val")
+	}
+
+	if protonDir, err := os.Stat(configuration.Umu.Proton); os.IsNotExist(err) || !protonDir.IsDir() {
+		return errors.New("Specified proton path is does not exist or is not a directory")
+	}
+
+	if configuration.Umu.Store != "" && !slices.Contains(UMU_STORES, configuration.Umu.Store) {
+		return fmt.Errorf("Unknown umu store %s, expected one of: %s", configuration.Umu.Store, strings.Join(UMU_STORES, ", "))
+	}
+
+	if _, err := NormalizeUmuGameId(configuration.Umu.GameId); err != nil {
+		return fmt.Errorf("Invalid umu.game-id: %s", err)
+	}
+
+	if _, err := NormalizeUmuGameId(configuration.Environment["GAMEID"]); err != nil {
+		return fmt.Errorf("Invalid GAMEID in environment: %s", err)
+	}
+
+	if _, err := expandArgs(configuration.Umu.Args, configuration.Environment); err != nil {
+		return fmt.Errorf("Invalid umu.args: %s", err)
+	}
+
+	return nil
+}
+
+func (wrapper *UmuWrapper) Env(configuration *config.Configuration, paths config.Paths) map[string]string {
+	env := make(map[string]string)
+
+	prefixBaseFolder := config.GameFolder(config.CompatDataBase(paths, *configuration), configuration.Props["name"])
+	system.FS.MkdirAll(prefixBaseFolder, config.DEFAULT_PERMISSION)
+
+	env["GAMEID"] = umuGameId(configuration, paths)
+
+	env["WINEPREFIX"] = prefixBaseFolder
+	env["PROTONPATH"] = configuration.Umu.Proton
+	env["STORE"] = configuration.Umu.Store
+
+	return env
+}
+
+func (wrapper *UmuWrapper) Args(bin string, configuration *config.Configuration, paths config.Paths) []string {
+	umuArgs, _ := expandArgs(configuration.Umu.Args, configuration.Environment)
+
+	return append([]string{bin}, umuArgs...)
+}
+
+// GAMEID of games without a known umu id, umu applies no game specific fixes
+const UMU_DEFAULT_GAMEID = "umu-default"
+
+var umuGameIdRegex = regexp.MustCompile(`^umu-[A-Za-z0-9._-]+$`)
+
+// NormalizeUmuGameId checks id is a umu GAMEID (umu-<id>) and turns a bare Steam appid into one. An empty id stays empty.
+func NormalizeUmuGameId(id string) (string, error) {
+	if id == "" || umuGameIdRegex.MatchString(id) {
+		return id, nil
+	}
+
+	if _, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return "umu-" + id, nil
+	}
+
+	return "", fmt.Errorf("%s is neither umu-<id> nor a Steam appid", id)
+}
+
+type umuGameIdSource struct {
+	name  string
+	value string
+}
+
+// The GAMEID sources, highest precedence first: umu.game-id, the game id (through the umu database cache for
+// other stores), then GAMEID from the environment section. Sources that disagree with the winner are reported.
+func umuGameId(configuration *config.Configuration, paths config.Paths) string {
+	sources := make([]umuGameIdSource, 0)
+
+	if gameId, _ := NormalizeUmuGameId(configuration.Umu.GameId); gameId != "" {
+		sources = append(sources, umuGameIdSource{"umu.game-id", gameId})
+	}
+
+	if id := configuration.Props["id"]; id != "" {
+		gameId := ""
+
+		// Only the cache is read, launches never wait on the umu database (see plauncher cache warm)
+		if store := umuDatabaseStore(configuration); store != "" {
+			if umuId, exists := CachedUmuId(paths.UmuIdsCacheFolder, store, id); exists {
+				gameId, _ = NormalizeUmuGameId(umuId)
+			}
+		}
+
+		if gameId == "" {
+			gameId, _ = NormalizeUmuGameId(id)
+		}
+
+		if gameId != "" {
+			sources = append(sources, umuGameIdSource{"game id " + id, gameId})
+		} else {
+			log.Printf("Game id %s has no umu id, it is not used as GAMEID\n", id)
+		}
+	}
+
+	if gameId, _ := NormalizeUmuGameId(configuration.Environment["GAMEID"]); gameId != "" {
+		sources = append(sources, umuGameIdSource{"GAMEID in environment", gameId})
+	}
+
+	if len(sources) == 0 {
+		log.Printf("No umu id known for %s, using GAMEID %s\n", configuration.Props["name"], UMU_DEFAULT_GAMEID)
+		return UMU_DEFAULT_GAMEID
+	}
+
+	for _, source := range sources[1:] {
+		if source.value != sources[0].value {
+			log.Printf("WARNING: GAMEID %s from %s ignored, %s sets %s\n", source.value, source.name, sources[0].name, sources[0].value)
+			fmt.Fprintf(os.Stderr, "plauncher: GAMEID %s from %s ignored, %s sets %s\n", source.value, source.name, sources[0].name, sources[0].value)
+		}
+	}
+
+	log.Printf("Using GAMEID %s from %s\n", sources[0].value, sources[0].name)
+
+	return sources[0].value
+}
+
+// Store names accepted by umu-run in STORE
+var UMU_STORES = []string{"none", "egs", "gog", "amazon", "battlenet", "ea", "humble", "itchio", "ubisoft", "zoomplatform"}
+
+// EnrichUmuStore sets umu.store from --store, or detects it from the game command when the configuration leaves it empty.
+func EnrichUmuStore(configuration *config.Configuration, gameArgs []string) {
+	if store, exists := configuration.Props["store"]; exists {
+		configuration.Umu.Store = store
+		return
+	}
+
+	if configuration.Umu.Store != "" {
+		return
+	}
+
+	if store := detectUmuStore(gameArgs); store != "" {
+		log.Printf("Detected umu store: %s\n", store)
+		configuration.Umu.Store = store
+	}
+}
+
+func detectUmuStore(gameArgs []string) string {
+	gameExe := ""
+
+	for _, arg := range gameArgs {
+		// Legendary (and Heroic through it) hands the Epic launcher arguments over to the game
+		if strings.HasPrefix(arg, "-epicapp=") || strings.EqualFold(arg, "-EpicPortal") {
+			return "egs"
+		}
+
+		if gameExe == "" && strings.HasSuffix(strings.ToLower(arg), ".exe") {
+			gameExe = arg
+		}
+	}
+
+	if gameExe == "" {
+		return ""
+	}
+
+	// gogdl installs keep GOG's goggame-<id>.info, itch keeps its receipt in .itch, both at the game root
+	for folder, depth := filepath.Dir(gameExe), 0; depth < 3 && folder != "/" && folder != "."; folder, depth = filepath.Dir(folder), depth+1 {
+		if matches, _ := filepath.Glob(filepath.Join(folder, "goggame-*.info")); len(matches) > 0 {
+			return "gog"
+		}
+
+		if info, err := os.Stat(filepath.Join(folder, ".itch")); err == nil && info.IsDir() {
+			return "itchio"
+		}
+	}
+
+	return ""
+}
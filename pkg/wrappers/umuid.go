@@ -0,0 +1,81 @@
+package wrappers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const UMU_API_URL = "https://umu.openwinecomponents.org/umu_api.php"
+const UMU_STORE_STEAM = "steam"
+
+// UmuDatabaseEntry is the part of an umu database entry plauncher reads.
+type UmuDatabaseEntry struct {
+	Title string `json:"title"`
+	UmuId string `json:"umu_id"`
+}
+
+// CachedUmuId returns the umu id of the game codename (the appid for Steam) in store, as cached by FetchUmuId.
+// An empty id means the umu database does not know the game.
+func CachedUmuId(cacheFolder string, store string, codename string) (string, bool) {
+	umuId, err := os.ReadFile(umuIdCacheFile(cacheFolder, store, codename))
+
+	if err != nil {
+		return "", false
+	}
+
+	return string(umuId), true
+}
+
+// FetchUmuId looks codename up in the umu database and caches the result, unknown games included.
+func FetchUmuId(cacheFolder string, store string, codename string) (string, error) {
+	query := url.Values{"store": {store}, "codename": {codename}}
+	body, err := system.HttpGet(UMU_API_URL + "?" + query.Encode())
+
+	if err != nil {
+		return "", err
+	}
+
+	entries := make([]UmuDatabaseEntry, 0)
+
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return "", fmt.Errorf("umu database response is not valid JSON: %s", err)
+	}
+
+	umuId := ""
+
+	if len(entries) > 0 {
+		umuId = entries[0].UmuId
+	}
+
+	cacheFile := umuIdCacheFile(cacheFolder, store, codename)
+	log.Printf("Saving umu id(%s) in cache file: %s\n", umuId, cacheFile)
+
+	system.FS.MkdirAll(filepath.Dir(cacheFile), config.DEFAULT_PERMISSION)
+
+	return umuId, system.FS.WriteFile(cacheFile, []byte(umuId), config.DEFAULT_PERMISSION)
+}
+
+func umuIdCacheFile(cacheFolder string, store string, codename string) string {
+	return filepath.Join(cacheFolder, config.GameSlug(store), config.GameSlug(codename))
+}
+
+// Store the umu database knows the game under, games without one but with an appid are Steam games
+func umuDatabaseStore(configuration *config.Configuration) string {
+	if configuration.Umu.Store != "" && configuration.Umu.Store != "none" {
+		return configuration.Umu.Store
+	}
+
+	if _, err := strconv.Atoi(configuration.Props["id"]); err == nil {
+		return UMU_STORE_STEAM
+	}
+
+	return ""
+}
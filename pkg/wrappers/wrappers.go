@@ -0,0 +1,82 @@
+// Package wrappers assembles the chain of tools (gamemode, mangohud, gamescope, umu)
+// the game command is prefixed with. Each tool is a Wrapper registered in the pipeline.
+package wrappers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+	"github.com/fpetros1/linux-game-launcher/pkg/system"
+)
+
+const GAMEMODE_BIN_NAME = "gamemoderun"
+const MANGOHUD_BIN_NAME = "mangohud"
+const MANGOAPP_BIN_NAME = "mangoapp"
+const GAMESCOPE_BIN_NAME = "gamescope"
+const LEGENDARY_BIN_NAME = "legendary"
+const UMU_RUN_BIN_NAME = "umu-run"
+
+// ErrMissingDependency is wrapped by the Validate errors caused by a tool that is not installed.
+var ErrMissingDependency = errors.New("missing dependency")
+
+// Wrapper is a tool the game command can be run through.
+type Wrapper interface {
+	// Name identifies the wrapper in logs.
+	Name() string
+	// Detect reports whether the wrapper is enabled for this launch and installed, returning its binary.
+	Detect(configuration *config.Configuration) (string, bool)
+	// Validate checks the configuration can be used by the wrapper.
+	Validate(configuration *config.Configuration, paths config.Paths) error
+	// Env returns the variables the wrapper needs in the game environment.
+	Env(configuration *config.Configuration, paths config.Paths) map[string]string
+	// Args returns the arguments placed before the game command, bin included when the wrapper is a command.
+	Args(bin string, configuration *config.Configuration, paths config.Paths) []string
+}
+
+var registry = []Wrapper{
+	&NetworkWrapper{},
+	&MangohudWrapper{},
+	&GamemodeWrapper{},
+	&GamescopeWrapper{},
+	&UmuWrapper{},
+}
+
+// Register appends wrapper to the end of the pipeline, right before the game command.
+func Register(wrapper Wrapper) {
+	registry = append(registry, wrapper)
+}
+
+// Registered returns the wrappers in pipeline order.
+func Registered() []Wrapper {
+	return registry
+}
+
+// BuildCommand prefixes gameArgs with every enabled and installed wrapper.
+func BuildCommand(configuration *config.Configuration, paths config.Paths, gameArgs []string) []string {
+	command := make([]string, 0)
+
+	for _, wrapper := range registry {
+		bin, enabled := wrapper.Detect(configuration)
+
+		if !enabled {
+			continue
+		}
+
+		if err := wrapper.Validate(configuration, paths); errors.Is(err, ErrMissingDependency) {
+			system.Fatalf(system.EXIT_MISSING_DEPENDENCY, "%s\n", err)
+		} else if err != nil {
+			system.Fatalf(system.EXIT_CONFIG_ERROR, "%s\n", err)
+		}
+
+		log.Printf("Using wrapper: %s\n", wrapper.Name())
+
+		for key, value := range wrapper.Env(configuration, paths) {
+			configuration.Environment[key] = value
+		}
+
+		command = append(command, wrapper.Args(bin, configuration, paths)...)
+	}
+
+	return append(command, gameArgs...)
+}
@@ -0,0 +1,195 @@
+package wrappers
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fpetros1/linux-game-launcher/pkg/config"
+)
+
+func testConfiguration() config.Configuration {
+	configuration := config.DefaultConfiguration()
+	configuration.Props["name"] = "Hades"
+
+	return configuration
+}
+
+func testPaths(t *testing.T) config.Paths {
+	base := t.TempDir()
+
+	return config.Paths{
+		HomeDir:           base,
+		UserConfigDir:     filepath.Join(base, "config"),
+		CompatDataBase:    filepath.Join(base, "compatdata"),
+		UmuIdsCacheFolder: filepath.Join(base, "umu-ids"),
+	}
+}
+
+func checkArgs(t *testing.T, got []string, expected []string) {
+	t.Helper()
+
+	if !slices.Equal(got, expected) {
+		t.Errorf("args %q, expected %q", got, expected)
+	}
+}
+
+func checkEnv(t *testing.T, got map[string]string, expected map[string]string) {
+	t.Helper()
+
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("%s=%q, expected %q", key, got[key], value)
+		}
+	}
+}
+
+func TestGamemodeWrapper(t *testing.T) {
+	configuration := testConfiguration()
+	wrapper := &GamemodeWrapper{}
+
+	checkArgs(t, wrapper.Args("/usr/bin/gamemoderun", &configuration, testPaths(t)), []string{"/usr/bin/gamemoderun"})
+
+	if env := wrapper.Env(&configuration, testPaths(t)); len(env) > 0 {
+		t.Errorf("unexpected environment %v", env)
+	}
+}
+
+func TestMangohudWrapper(t *testing.T) {
+	configuration := testConfiguration()
+	paths := testPaths(t)
+	wrapper := &MangohudWrapper{}
+	control := ",control=" + MangohudControlName(os.Getpid()) + "%p"
+
+	t.Setenv("MANGOHUD_CONFIG", "")
+
+	checkArgs(t, wrapper.Args("/usr/bin/mangohud", &configuration, paths), nil)
+	checkEnv(t, wrapper.Env(&configuration, paths), map[string]string{
+		"MANGOHUD":            "1",
+		"DISABLE_MANGOAPP":    "1",
+		"MANGOHUD_CONFIGFILE": filepath.Join(paths.UserConfigDir, "MangoHud", "MangoHud.conf"),
+		"MANGOHUD_CONFIG":     "read_cfg" + control,
+	})
+
+	configuration.Environment["MANGOHUD_CONFIG"] = "fps_limit=60"
+	checkEnv(t, wrapper.Env(&configuration, paths), map[string]string{"MANGOHUD_CONFIG": "fps_limit=60" + control})
+
+	configuration.Control.Enabled = false
+
+	if _, found := wrapper.Env(&configuration, paths)["MANGOHUD_CONFIG"]; found {
+		t.Error("MANGOHUD_CONFIG set with control disabled")
+	}
+}
+
+func TestGamescopeWrapper(t *testing.T) {
+	configuration := testConfiguration()
+	paths := testPaths(t)
+	wrapper := &GamescopeWrapper{}
+
+	configuration.Gamescope.Args = []string{"-W 2560 -H 1440", "-f"}
+	configuration.Gamescope.Hdr.Enabled = true
+	configuration.Gamescope.Hdr.SdrContentNits = 400
+	configuration.Gamescope.Input.GrabKeyboard = true
+	configuration.Display.Vrr = config.DISPLAY_VRR_ON
+
+	if err := wrapper.Validate(&configuration, paths); err != nil {
+		t.Fatalf("Validate: %s", err)
+	}
+
+	checkEnv(t, wrapper.Env(&configuration, paths), map[string]string{"DXVK_HDR": "1", "ENABLE_HDR_WSI": "1"})
+	checkArgs(t, wrapper.Args("/usr/bin/gamescope", &configuration, paths), []string{
+		"/usr/bin/gamescope", "-W", "2560", "-H", "1440", "-f",
+		GAMESCOPE_HDR_ARGV, GAMESCOPE_HDR_SDR_CONTENT_NITS_ARGV, "400",
+		GAMESCOPE_GRAB_ARGV, GAMESCOPE_ADAPTIVE_SYNC_ARGV, "--",
+	})
+
+	configuration.Gamescope.Args = []string{`-W 1920 -H 1080 --hdr-sdr-content-nits=200 --stats-path "/tmp/my stats"`}
+
+	args := wrapper.Args("/usr/bin/gamescope", &configuration, paths)
+
+	if slices.Contains(args, "400") || !slices.Contains(args, "/tmp/my stats") {
+		t.Errorf("configured args did not win or were not split as shell words: %q", args)
+	}
+
+	configuration.Gamescope.Args = []string{`"unterminated`}
+
+	if err := wrapper.Validate(&configuration, paths); err == nil {
+		t.Error("invalid gamescope.args were accepted")
+	}
+}
+
+func TestNetworkWrapper(t *testing.T) {
+	configuration := testConfiguration()
+	paths := testPaths(t)
+	wrapper := &NetworkWrapper{}
+
+	configuration.Network.Limit = "5MBit"
+	configuration.Network.Interface = "wlan0"
+	checkArgs(t, wrapper.Args("/usr/bin/plauncher", &configuration, paths), []string{"/usr/bin/plauncher", NETWORK_SHAPE_COMMAND, "--limit=5mbit", "--interface=wlan0", "--"})
+
+	configuration.Network.Limit = "fast"
+
+	if err := wrapper.Validate(&configuration, paths); err == nil || !strings.Contains(err.Error(), "network.limit") {
+		t.Errorf("invalid network.limit accepted: %v", err)
+	}
+
+	configuration.Network = config.NetworkConfiguration{Namespace: "vpn"}
+	checkArgs(t, wrapper.Args("/usr/bin/firejail", &configuration, paths), []string{"/usr/bin/firejail", "--quiet", "--noprofile", "--netns=vpn", "--"})
+
+	if env := wrapper.Env(&configuration, paths); len(env) > 0 {
+		t.Errorf("unexpected environment %v", env)
+	}
+}
+
+func TestUmuWrapper(t *testing.T) {
+	configuration := testConfiguration()
+	paths := testPaths(t)
+	wrapper := &UmuWrapper{}
+
+	configuration.Umu.Enabled = true
+	configuration.Umu.Proton = t.TempDir()
+	configuration.Umu.GameId = "1145360"
+	configuration.Umu.Store = "egs"
+	configuration.Umu.Args = []string{"-opengl", "--dir=$GAME_DIR"}
+	configuration.Environment["GAME_DIR"] = "/games/hades"
+
+	if err := wrapper.Validate(&configuration, paths); err != nil {
+		t.Fatalf("Validate: %s", err)
+	}
+
+	checkEnv(t, wrapper.Env(&configuration, paths), map[string]string{
+		"GAMEID":     "umu-1145360",
+		"WINEPREFIX": config.GameFolder(paths.CompatDataBase, "Hades"),
+		"PROTONPATH": configuration.Umu.Proton,
+		"STORE":      "egs",
+	})
+	checkArgs(t, wrapper.Args("/usr/bin/umu-run", &configuration, paths), []string{"/usr/bin/umu-run", "-opengl", "--dir=/games/hades"})
+
+	configuration.Umu.Store = "steamstore"
+
+	if err := wrapper.Validate(&configuration, paths); err == nil {
+		t.Error("unknown umu store accepted")
+	}
+}
+
+func TestBuildCommand(t *testing.T) {
+	bins := t.TempDir()
+
+	for _, bin := range []string{GAMEMODE_BIN_NAME, MANGOHUD_BIN_NAME} {
+		os.WriteFile(filepath.Join(bins, bin), []byte("#!/bin/sh\n"), 0o755)
+	}
+
+	t.Setenv("PATH", bins+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	configuration := testConfiguration()
+	configuration.Mangohud.Enabled = true
+	configuration.Gamescope.Enabled = false
+	configuration.Umu.Enabled = false
+
+	command := BuildCommand(&configuration, testPaths(t), []string{"/games/hades/Hades"})
+
+	checkArgs(t, command, []string{filepath.Join(bins, GAMEMODE_BIN_NAME), "/games/hades/Hades"})
+	checkEnv(t, configuration.Environment, map[string]string{"MANGOHUD": "1"})
+}